@@ -0,0 +1,312 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// Status tells how a line of the old file relates to a line of the new file
+type Status string
+
+const (
+	// Equal lines are the same on both files
+	Equal Status = "equal"
+
+	// Changed lines exist on both files, but with different content
+	Changed Status = "changed"
+
+	// Added lines only exist on the new file
+	Added Status = "added"
+
+	// Removed lines only exist on the old file
+	Removed Status = "removed"
+)
+
+// FieldChange is a field whose value differs between the old and the new line
+type FieldChange struct {
+	Field    yamlconfig.Field
+	OldValue string
+	NewValue string
+}
+
+// LineDiff is the comparison of a line of the old file with a line of the new file. Line numbers start at 1,
+// and they are 0 when the line does not exist on that file
+type LineDiff struct {
+	Status Status
+	Key    string
+
+	OldLineNumber int
+	OldLine       string
+	OldRecord     *yamlconfig.Record
+
+	NewLineNumber int
+	NewLine       string
+	NewRecord     *yamlconfig.Record
+
+	// Changes holds the fields that changed, when both lines are of the same record
+	Changes []FieldChange
+
+	// RecordChanged is true when the lines are of different records, so their fields are not compared
+	RecordChanged bool
+
+	// OutsideFieldsChanged is true when characters that are not on any field changed
+	OutsideFieldsChanged bool
+}
+
+// Result is the comparison of two files
+type Result struct {
+	Lines []LineDiff
+
+	Equal   int
+	Changed int
+	Added   int
+	Removed int
+}
+
+// HasDifferences returns true if the files are not equal
+func (result Result) HasDifferences() bool {
+	return result.Changed > 0 || result.Added > 0 || result.Removed > 0
+}
+
+// add adds a compared line to the result
+func (result *Result) add(lineDiff LineDiff) {
+	result.Lines = append(result.Lines, lineDiff)
+	result.count(lineDiff.Status)
+}
+
+// count adds a compared line to the counters of the result
+func (result *Result) count(status Status) {
+	switch status {
+	case Equal:
+		result.Equal++
+	case Changed:
+		result.Changed++
+	case Added:
+		result.Added++
+	case Removed:
+		result.Removed++
+	}
+}
+
+// CompareByLine compares each line of the old file with the line on the same position of the new file
+func CompareByLine(records []yamlconfig.Record, oldLines []string, newLines []string) Result {
+	result := Result{}
+	for i := 0; i < len(oldLines) || i < len(newLines); i++ {
+		switch {
+		case i >= len(newLines):
+			result.add(removedLine(records, i+1, oldLines[i], ""))
+		case i >= len(oldLines):
+			result.add(addedLine(records, i+1, newLines[i], ""))
+		default:
+			result.add(compareLines(records, i+1, oldLines[i], i+1, newLines[i], ""))
+		}
+	}
+	return result
+}
+
+// keyedLine is a line along with its number, starting at 1
+type keyedLine struct {
+	number int
+	line   string
+}
+
+// KeyFunc returns the key of a line, which identifies the line on both files. False is returned if the line has no key
+type KeyFunc func(line string) (key string, hasKey bool)
+
+// FieldKey returns a KeyFunc in which the key of a line is the value of the field with the given name, without the spaces
+// around it. Lines that do not match any record, or whose record does not have the field, have no key.
+// An error is returned if no record has the field
+func FieldKey(records []yamlconfig.Record, fieldName string) (KeyFunc, error) {
+	if !hasField(records, fieldName) {
+		return nil, fmt.Errorf("FieldKey(): error - no record has the key field %q", fieldName)
+	}
+	return func(line string) (string, bool) {
+		record, isRecordFound := yamlconfig.FindFirstRecordThatMatchesString(records, line)
+		if !isRecordFound {
+			return "", false
+		}
+		field, isFieldFound := record.FieldByName(fieldName)
+		if !isFieldFound {
+			return "", false
+		}
+		return strings.TrimSpace(field.RawValue(line)), true
+	}, nil
+}
+
+// keySeparator separates the name of the record and the values of the key fields on the keys of RecordKey. It is a control
+// character that is not found on the lines of a text file, so that values with ", " on them do not make different keys equal
+const keySeparator = "\x1f"
+
+// RecordKey returns a KeyFunc in which the key of a line is the name of its record followed by the values of the key fields
+// of that record, separated by keySeparator. The key is shown on the reports as "person: 001, 023". Lines that do not match
+// any record, or whose record does not declare a key, have no key. An error is returned if no record declares a key
+func RecordKey(records []yamlconfig.Record) (KeyFunc, error) {
+	if !HasRecordKey(records) {
+		return nil, fmt.Errorf("RecordKey(): error - no record declares a key")
+	}
+	return func(line string) (string, bool) {
+		record, isRecordFound := yamlconfig.FindFirstRecordThatMatchesString(records, line)
+		if !isRecordFound || len(record.Key) == 0 {
+			return "", false
+		}
+		return record.Name + keySeparator + strings.Join(record.KeyValues(line), keySeparator), true
+	}, nil
+}
+
+// HasRecordKey returns true if any of the records declares a key
+func HasRecordKey(records []yamlconfig.Record) bool {
+	for _, record := range records {
+		if len(record.Key) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareByKey compares the lines of the old file with the lines of the new file that have the same key. If a key is repeated,
+// its first line on the old file is compared with its first line on the new file, and so on. Lines without key are compared
+// by their order, e.g. the header of the old file is compared with the header of the new file.
+// The result has the lines in the order of the old file, followed by the added lines
+func CompareByKey(records []yamlconfig.Record, oldLines []string, newLines []string, keyOf KeyFunc) Result {
+	newLinesByKey := map[string][]keyedLine{}
+	var newLinesWithoutKey []keyedLine
+	for i, line := range newLines {
+		key, hasKey := keyOf(line)
+		if !hasKey {
+			newLinesWithoutKey = append(newLinesWithoutKey, keyedLine{i + 1, line})
+			continue
+		}
+		newLinesByKey[key] = append(newLinesByKey[key], keyedLine{i + 1, line})
+	}
+
+	result := Result{}
+	isNewLineCompared := make([]bool, len(newLines))
+	linesWithoutKey := 0
+	for i, line := range oldLines {
+		key, hasKey := keyOf(line)
+
+		var candidates []keyedLine
+		if hasKey {
+			candidates = newLinesByKey[key]
+		} else if linesWithoutKey < len(newLinesWithoutKey) {
+			candidates = newLinesWithoutKey[linesWithoutKey:]
+			linesWithoutKey++
+		}
+
+		if len(candidates) == 0 {
+			result.add(removedLine(records, i+1, line, displayKey(key)))
+			continue
+		}
+
+		newLine := candidates[0]
+		if hasKey {
+			newLinesByKey[key] = candidates[1:]
+		}
+		isNewLineCompared[newLine.number-1] = true
+		result.add(compareLines(records, i+1, line, newLine.number, newLine.line, displayKey(key)))
+	}
+
+	for i, line := range newLines {
+		if !isNewLineCompared[i] {
+			key, _ := keyOf(line)
+			result.add(addedLine(records, i+1, line, displayKey(key)))
+		}
+	}
+
+	return result
+}
+
+// hasField returns true if any of the records has a field with the given name
+func hasField(records []yamlconfig.Record, fieldName string) bool {
+	for _, record := range records {
+		if _, isFound := record.FieldByName(fieldName); isFound {
+			return true
+		}
+	}
+	return false
+}
+
+// findRecord returns the record of a given line, or nil if the line does not match any record
+func findRecord(records []yamlconfig.Record, line string) *yamlconfig.Record {
+	recordIndex, isRecordFound := yamlconfig.FindFirstRecordIndexThatMatchesString(records, line)
+	if !isRecordFound {
+		return nil
+	}
+	return &records[recordIndex]
+}
+
+// removedLine returns the comparison of a line that only exists on the old file
+func removedLine(records []yamlconfig.Record, lineNumber int, line string, key string) LineDiff {
+	return LineDiff{Status: Removed, Key: key, OldLineNumber: lineNumber, OldLine: line, OldRecord: findRecord(records, line)}
+}
+
+// addedLine returns the comparison of a line that only exists on the new file
+func addedLine(records []yamlconfig.Record, lineNumber int, line string, key string) LineDiff {
+	return LineDiff{Status: Added, Key: key, NewLineNumber: lineNumber, NewLine: line, NewRecord: findRecord(records, line)}
+}
+
+// compareLines compares a line of the old file with a line of the new file, field by field
+func compareLines(records []yamlconfig.Record, oldLineNumber int, oldLine string, newLineNumber int, newLine string, key string) LineDiff {
+	lineDiff := LineDiff{
+		Status:        Equal,
+		Key:           key,
+		OldLineNumber: oldLineNumber,
+		OldLine:       oldLine,
+		OldRecord:     findRecord(records, oldLine),
+		NewLineNumber: newLineNumber,
+		NewLine:       newLine,
+		NewRecord:     findRecord(records, newLine),
+	}
+	if oldLine == newLine {
+		return lineDiff
+	}
+
+	lineDiff.Status = Changed
+	if lineDiff.OldRecord == nil || lineDiff.NewRecord == nil || lineDiff.OldRecord.Name != lineDiff.NewRecord.Name {
+		// the fields are only compared when both lines are of the same record, lines without record have no fields
+		lineDiff.RecordChanged = lineDiff.OldRecord != nil || lineDiff.NewRecord != nil
+		lineDiff.OutsideFieldsChanged = !lineDiff.RecordChanged
+		return lineDiff
+	}
+
+	for _, field := range lineDiff.OldRecord.Fields {
+		oldValue, newValue := field.RawValue(oldLine), field.RawValue(newLine)
+		if oldValue != newValue {
+			lineDiff.Changes = append(lineDiff.Changes, FieldChange{Field: field, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	lineDiff.OutsideFieldsChanged = !isEqualOutsideFields(lineDiff.OldRecord.Fields, oldLine, newLine)
+
+	return lineDiff
+}
+
+// isEqualOutsideFields returns true if the characters of both lines that are not on any of the fields are the same
+func isEqualOutsideFields(fields []yamlconfig.Field, oldLine string, newLine string) bool {
+	oldRunes, newRunes := []rune(oldLine), []rune(newLine)
+	length := len(oldRunes)
+	if len(newRunes) > length {
+		length = len(newRunes)
+	}
+
+	for position := 1; position <= length; position++ {
+		if isOnFields(fields, position) {
+			continue
+		}
+		if position > len(oldRunes) || position > len(newRunes) || oldRunes[position-1] != newRunes[position-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// isOnFields returns true if the given position is on any of the fields
+func isOnFields(fields []yamlconfig.Field, position int) bool {
+	for _, field := range fields {
+		if position >= field.Initial && position <= field.End {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+var testRecords = []yamlconfig.Record{
+	{
+		Name:   "header",
+		Regex:  yamlconfig.MustCreateRegex("^H"),
+		Fields: []yamlconfig.Field{{Name: "type", Initial: 1, End: 1}, {Name: "date", Initial: 2, End: 9}},
+	},
+	{
+		Name:  "person",
+		Regex: yamlconfig.MustCreateRegex("^P"),
+		Fields: []yamlconfig.Field{
+			{Name: "type", Initial: 1, End: 1},
+			{Name: "id", Initial: 2, End: 4},
+			{Name: "name", Initial: 5, End: 9},
+			{Name: "age", Initial: 11, End: 12},
+		},
+	},
+}
+
+// summarize returns the status, the line numbers and the names of the changed fields of each compared line
+func summarize(result Result) [][]interface{} {
+	var summary [][]interface{}
+	for _, lineDiff := range result.Lines {
+		var changedFields []string
+		for _, change := range lineDiff.Changes {
+			changedFields = append(changedFields, change.Field.Name)
+		}
+		summary = append(summary, []interface{}{lineDiff.Status, lineDiff.OldLineNumber, lineDiff.NewLineNumber, changedFields, lineDiff.RecordChanged, lineDiff.OutsideFieldsChanged})
+	}
+	return summary
+}
+
+func TestCompareByLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldLines []string
+		newLines []string
+		want     [][]interface{}
+	}{
+		{
+			"Should find equal lines and changed fields",
+			[]string{"H20210101", "P001John |40"},
+			[]string{"H20210101", "P001Jon  |41"},
+			[][]interface{}{
+				{Equal, 1, 1, []string(nil), false, false},
+				{Changed, 2, 2, []string{"name", "age"}, false, false},
+			},
+		},
+		{
+			"Should find changes outside of the fields",
+			[]string{"P001John |40"},
+			[]string{"P001John #40"},
+			[][]interface{}{
+				{Changed, 1, 1, []string(nil), false, true},
+			},
+		},
+		{
+			"Should find lines of different records",
+			[]string{"P001John |40", "xyz"},
+			[]string{"H20210101", "xyw"},
+			[][]interface{}{
+				{Changed, 1, 1, []string(nil), true, false},
+				{Changed, 2, 2, []string(nil), false, true},
+			},
+		},
+		{
+			"Should find added and removed lines at the end",
+			[]string{"H20210101", "P001John |40"},
+			[]string{"H20210101"},
+			[][]interface{}{
+				{Equal, 1, 1, []string(nil), false, false},
+				{Removed, 2, 0, []string(nil), false, false},
+			},
+		},
+		{
+			"Should find added lines at the end",
+			[]string{},
+			[]string{"H20210101"},
+			[][]interface{}{
+				{Added, 0, 1, []string(nil), false, false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarize(CompareByLine(testRecords, tt.oldLines, tt.newLines)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareByLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareByKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldLines    []string
+		newLines    []string
+		want        [][]interface{}
+		wantCounts  []int
+		wantErr     bool
+		keyOverride string
+	}{
+		{
+			"Should align the lines by the key field",
+			[]string{"H20210101", "P001John |40", "P002Mary |30", "P003Ann  |20"},
+			[]string{"H20210102", "P003Ann  |20", "P004Bob   |50", "P001John |41"},
+			[][]interface{}{
+				{Changed, 1, 1, []string{"date"}, false, false},
+				{Changed, 2, 4, []string{"age"}, false, false},
+				{Removed, 3, 0, []string(nil), false, false},
+				{Equal, 4, 2, []string(nil), false, false},
+				{Added, 0, 3, []string(nil), false, false},
+			},
+			[]int{1, 2, 1, 1},
+			false,
+			"",
+		},
+		{
+			"Should align repeated keys by their order",
+			[]string{"P001John |40", "P001John |41"},
+			[]string{"P001John |41", "P001John |42", "P001John |43"},
+			[][]interface{}{
+				{Changed, 1, 1, []string{"age"}, false, false},
+				{Changed, 2, 2, []string{"age"}, false, false},
+				{Added, 0, 3, []string(nil), false, false},
+			},
+			[]int{0, 2, 1, 0},
+			false,
+			"",
+		},
+		{
+			"Should not accept a key field that no record has",
+			[]string{},
+			[]string{},
+			nil,
+			[]int{0, 0, 0, 0},
+			true,
+			"cpf",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyField := "id"
+			if tt.keyOverride != "" {
+				keyField = tt.keyOverride
+			}
+			keyOf, err := FieldKey(testRecords, keyField)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FieldKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			got := CompareByKey(testRecords, tt.oldLines, tt.newLines, keyOf)
+			if gotSummary := summarize(got); !reflect.DeepEqual(gotSummary, tt.want) {
+				t.Errorf("CompareByKey() = %v, want %v", gotSummary, tt.want)
+			}
+			if gotCounts := []int{got.Equal, got.Changed, got.Added, got.Removed}; !reflect.DeepEqual(gotCounts, tt.wantCounts) {
+				t.Errorf("CompareByKey() counts = %v, want %v", gotCounts, tt.wantCounts)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// LayoutChangeKind is the kind of a change between two versions of a layout
+type LayoutChangeKind string
+
+const (
+	// VersionChanged is a change on the version of the layout, which has no record
+	VersionChanged LayoutChangeKind = "version_changed"
+	// RecordAdded is a record that is only on the new version
+	RecordAdded LayoutChangeKind = "record_added"
+	// RecordRemoved is a record that is only on the old version
+	RecordRemoved LayoutChangeKind = "record_removed"
+	// RegexChanged is a change on the regex of a record
+	RegexChanged LayoutChangeKind = "regex_changed"
+	// KeyChanged is a change on the key fields of a record, which are joined by ", " on Old and New
+	KeyChanged LayoutChangeKind = "key_changed"
+	// FieldAdded is a field that is only on the new version, with its positions on New
+	FieldAdded LayoutChangeKind = "field_added"
+	// FieldRemoved is a field that is only on the old version, with its positions on Old
+	FieldRemoved LayoutChangeKind = "field_removed"
+	// FieldRenamed is a field matched by its positions to a field with another name
+	FieldRenamed LayoutChangeKind = "field_renamed"
+	// FieldMoved is a change on the initial position of a field, with its positions on Old and New
+	FieldMoved LayoutChangeKind = "field_moved"
+	// FieldResized is a change on the length of a field
+	FieldResized LayoutChangeKind = "field_resized"
+	// TypeChanged is a change on the type of a field or on its options, e.g. from "decimal(2)" to "decimal(3)"
+	TypeChanged LayoutChangeKind = "type_changed"
+	// DescriptionChanged is a change on the description of a field
+	DescriptionChanged LayoutChangeKind = "description_changed"
+)
+
+// minimumOverlapToRename is the minimum part of a field that must overlap a field with another name, on the other version
+// of the layout, for them to be considered the same field renamed
+const minimumOverlapToRename = 0.5
+
+// LayoutChange is a change between two versions of a layout. Record is empty when the change is on the layout itself,
+// e.g. on its version. Field is the name of the field on the new version, or on
+// the old version if it was removed. Old and New describe the change, e.g. the old and new positions of a moved field
+type LayoutChange struct {
+	Kind   LayoutChangeKind `json:"kind"`
+	Record string           `json:"record,omitempty"`
+	Field  string           `json:"field,omitempty"`
+	Old    string           `json:"old,omitempty"`
+	New    string           `json:"new,omitempty"`
+}
+
+// String returns a human readable description of the change
+func (change LayoutChange) String() string {
+	record := fmt.Sprintf("record %q", change.Record)
+	field := fmt.Sprintf("%v: field %q", record, change.Field)
+	switch change.Kind {
+	case VersionChanged:
+		return fmt.Sprintf("~ version changed from %q to %q", change.Old, change.New)
+	case RecordAdded:
+		return "+ " + record
+	case RecordRemoved:
+		return "- " + record
+	case RegexChanged:
+		return fmt.Sprintf("~ %v: regex changed from %q to %q", record, change.Old, change.New)
+	case KeyChanged:
+		return fmt.Sprintf("~ %v: key changed from [%v] to [%v]", record, change.Old, change.New)
+	case FieldAdded:
+		return fmt.Sprintf("+ %v (%v)", field, change.New)
+	case FieldRemoved:
+		return fmt.Sprintf("- %v (%v)", field, change.Old)
+	case FieldRenamed:
+		return fmt.Sprintf("~ %v: renamed from %q", field, change.Old)
+	case FieldMoved:
+		return fmt.Sprintf("~ %v: moved from %v to %v", field, change.Old, change.New)
+	case FieldResized:
+		return fmt.Sprintf("~ %v: length changed from %v to %v", field, change.Old, change.New)
+	case TypeChanged:
+		return fmt.Sprintf("~ %v: type changed from %v to %v", field, change.Old, change.New)
+	case DescriptionChanged:
+		return fmt.Sprintf("~ %v: description changed from %q to %q", field, change.Old, change.New)
+	}
+	return fmt.Sprintf("~ %v: %v %q -> %q", field, change.Kind, change.Old, change.New)
+}
+
+// CompareLayouts returns the changes between two versions of a layout, starting by the change on its version. Records are matched by their names, and fields by
+// their names. The remaining fields of a record are matched by their positions, and reported as renamed: first the fields
+// on the same positions, then the fields that overlap the most, if they overlap at least half of one of them
+func CompareLayouts(oldConfiguration yamlconfig.Configuration, newConfiguration yamlconfig.Configuration) []LayoutChange {
+	var changes []LayoutChange
+	if oldConfiguration.Version != newConfiguration.Version {
+		changes = append(changes, LayoutChange{Kind: VersionChanged, Old: oldConfiguration.Version, New: newConfiguration.Version})
+	}
+
+	for _, oldRecord := range oldConfiguration.Records {
+		if _, isFound := findRecordByName(newConfiguration.Records, oldRecord.Name); !isFound {
+			changes = append(changes, LayoutChange{Kind: RecordRemoved, Record: oldRecord.Name})
+		}
+	}
+
+	for _, newRecord := range newConfiguration.Records {
+		oldRecord, isFound := findRecordByName(oldConfiguration.Records, newRecord.Name)
+		if !isFound {
+			changes = append(changes, LayoutChange{Kind: RecordAdded, Record: newRecord.Name})
+			continue
+		}
+		changes = append(changes, compareRecords(oldRecord, newRecord)...)
+	}
+
+	return changes
+}
+
+// findRecordByName returns the record with the given name
+func findRecordByName(records []yamlconfig.Record, name string) (yamlconfig.Record, bool) {
+	for _, record := range records {
+		if record.Name == name {
+			return record, true
+		}
+	}
+	return yamlconfig.Record{}, false
+}
+
+// compareRecords returns the changes between two versions of a record
+func compareRecords(oldRecord yamlconfig.Record, newRecord yamlconfig.Record) []LayoutChange {
+	var changes []LayoutChange
+	if oldRecord.Regex.String() != newRecord.Regex.String() {
+		changes = append(changes, LayoutChange{Kind: RegexChanged, Record: newRecord.Name, Old: oldRecord.Regex.String(), New: newRecord.Regex.String()})
+	}
+	if oldKey, newKey := strings.Join(oldRecord.Key, ", "), strings.Join(newRecord.Key, ", "); oldKey != newKey {
+		changes = append(changes, LayoutChange{Kind: KeyChanged, Record: newRecord.Name, Old: oldKey, New: newKey})
+	}
+
+	oldFieldByNewField := matchFields(oldRecord.Fields, newRecord.Fields)
+	isOldFieldMatched := map[string]bool{}
+	for _, newField := range newRecord.Fields {
+		oldField, isMatched := oldFieldByNewField[newField.Name]
+		if !isMatched {
+			changes = append(changes, LayoutChange{Kind: FieldAdded, Record: newRecord.Name, Field: newField.Name, New: positions(newField)})
+			continue
+		}
+		isOldFieldMatched[oldField.Name] = true
+		changes = append(changes, compareFields(newRecord.Name, oldField, newField)...)
+	}
+
+	for _, oldField := range oldRecord.Fields {
+		if !isOldFieldMatched[oldField.Name] {
+			changes = append(changes, LayoutChange{Kind: FieldRemoved, Record: newRecord.Name, Field: oldField.Name, Old: positions(oldField)})
+		}
+	}
+	return changes
+}
+
+// matchFields returns the old field that matches each new field, by the names of the new fields.
+// Fields are matched by their names and then by their positions
+func matchFields(oldFields []yamlconfig.Field, newFields []yamlconfig.Field) map[string]yamlconfig.Field {
+	oldFieldByNewField := map[string]yamlconfig.Field{}
+	var unmatchedOldFields, unmatchedNewFields []yamlconfig.Field
+
+	for _, newField := range newFields {
+		if oldField, isFound := (yamlconfig.Record{Fields: oldFields}).FieldByName(newField.Name); isFound {
+			oldFieldByNewField[newField.Name] = oldField
+		} else {
+			unmatchedNewFields = append(unmatchedNewFields, newField)
+		}
+	}
+	for _, oldField := range oldFields {
+		if _, isFound := (yamlconfig.Record{Fields: newFields}).FieldByName(oldField.Name); !isFound {
+			unmatchedOldFields = append(unmatchedOldFields, oldField)
+		}
+	}
+
+	isOldFieldRenamed := map[string]bool{}
+	rename := func(score func(oldField yamlconfig.Field, newField yamlconfig.Field) float64) {
+		for _, newField := range unmatchedNewFields {
+			if _, isMatched := oldFieldByNewField[newField.Name]; isMatched {
+				continue
+			}
+			bestScore, bestOldField := 0.0, yamlconfig.Field{}
+			for _, oldField := range unmatchedOldFields {
+				if fieldScore := score(oldField, newField); !isOldFieldRenamed[oldField.Name] && fieldScore > bestScore {
+					bestScore, bestOldField = fieldScore, oldField
+				}
+			}
+			if bestScore > 0 {
+				oldFieldByNewField[newField.Name] = bestOldField
+				isOldFieldRenamed[bestOldField.Name] = true
+			}
+		}
+	}
+
+	rename(func(oldField yamlconfig.Field, newField yamlconfig.Field) float64 {
+		if oldField.Initial == newField.Initial && oldField.End == newField.End {
+			return 1
+		}
+		return 0
+	})
+	rename(func(oldField yamlconfig.Field, newField yamlconfig.Field) float64 {
+		overlap := overlapOf(oldField, newField)
+		if overlap < minimumOverlapToRename {
+			return 0
+		}
+		return overlap
+	})
+
+	return oldFieldByNewField
+}
+
+// overlapOf returns the part of the smallest of the fields that overlaps the other field, from 0 to 1
+func overlapOf(oldField yamlconfig.Field, newField yamlconfig.Field) float64 {
+	initial, end := oldField.Initial, oldField.End
+	if newField.Initial > initial {
+		initial = newField.Initial
+	}
+	if newField.End < end {
+		end = newField.End
+	}
+	if end < initial {
+		return 0
+	}
+
+	smallestLength := oldField.Length()
+	if newField.Length() < smallestLength {
+		smallestLength = newField.Length()
+	}
+	return float64(end-initial+1) / float64(smallestLength)
+}
+
+// compareFields returns the changes between two versions of a field
+func compareFields(recordName string, oldField yamlconfig.Field, newField yamlconfig.Field) []LayoutChange {
+	var changes []LayoutChange
+	change := func(kind LayoutChangeKind, oldValue string, newValue string) {
+		changes = append(changes, LayoutChange{Kind: kind, Record: recordName, Field: newField.Name, Old: oldValue, New: newValue})
+	}
+
+	if oldField.Name != newField.Name {
+		change(FieldRenamed, oldField.Name, newField.Name)
+	}
+	if oldField.Initial != newField.Initial {
+		change(FieldMoved, positions(oldField), positions(newField))
+	}
+	if oldField.Length() != newField.Length() {
+		change(FieldResized, fmt.Sprint(oldField.Length()), fmt.Sprint(newField.Length()))
+	}
+	if oldType, newType := typeOf(oldField), typeOf(newField); oldType != newType {
+		change(TypeChanged, oldType, newType)
+	}
+	if oldField.Description != newField.Description {
+		change(DescriptionChanged, oldField.Description, newField.Description)
+	}
+	return changes
+}
+
+// positions returns the positions of a field, e.g. "5-9"
+func positions(field yamlconfig.Field) string {
+	return fmt.Sprintf("%v-%v", field.Initial, field.End)
+}
+
+// typeOf returns the type of a field along with its options, e.g. "decimal(2)" or "date(DDMMYYYY)"
+func typeOf(field yamlconfig.Field) string {
+	switch field.Type {
+	case "", yamlconfig.StringType:
+		return string(yamlconfig.StringType)
+	case yamlconfig.DecimalType:
+		return fmt.Sprintf("%v(%v)", field.Type, field.Decimals)
+	case yamlconfig.DateType:
+		format := field.Format
+		if format == "" {
+			format = "YYYYMMDD"
+		}
+		return fmt.Sprintf("%v(%v)", field.Type, format)
+	}
+	return string(field.Type)
+}
+
+// WriteLayoutChangesAsText writes one change per line, followed by the number of changes
+func WriteLayoutChangesAsText(w io.Writer, changes []LayoutChange) error {
+	for _, change := range changes {
+		if _, err := fmt.Fprintln(w, change); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%v changes\n", len(changes))
+	return err
+}
+
+// WriteLayoutChangesAsJSON writes the changes as a JSON object with the list of changes
+func WriteLayoutChangesAsJSON(w io.Writer, changes []LayoutChange) error {
+	if changes == nil {
+		changes = []LayoutChange{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Changes []LayoutChange `json:"changes"`
+	}{changes})
+}
@@ -0,0 +1,188 @@
+package diff
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+func TestCompareLayouts(t *testing.T) {
+	person := func(fields ...yamlconfig.Field) yamlconfig.Record {
+		return yamlconfig.Record{Name: "person", Regex: yamlconfig.MustCreateRegex("^P"), Fields: fields}
+	}
+
+	tests := []struct {
+		name             string
+		oldConfiguration yamlconfig.Configuration
+		newConfiguration yamlconfig.Configuration
+		want             []LayoutChange
+	}{
+		{
+			name:             "Should find no changes on equal layouts",
+			oldConfiguration: yamlconfig.Configuration{Records: testRecords},
+			newConfiguration: yamlconfig.Configuration{Records: testRecords},
+			want:             nil,
+		},
+		{
+			name:             "Should find a change on the version of the layout",
+			oldConfiguration: yamlconfig.Configuration{Version: "1.0", Records: testRecords},
+			newConfiguration: yamlconfig.Configuration{Version: "1.1", Records: testRecords},
+			want:             []LayoutChange{{Kind: VersionChanged, Old: "1.0", New: "1.1"}},
+		},
+		{
+			name:             "Should find added and removed records",
+			oldConfiguration: yamlconfig.Configuration{Records: testRecords[:1]},
+			newConfiguration: yamlconfig.Configuration{Records: testRecords[1:]},
+			want: []LayoutChange{
+				{Kind: RecordRemoved, Record: "header"},
+				{Kind: RecordAdded, Record: "person"},
+			},
+		},
+		{
+			name: "Should find changes on the regex and on the key of a record",
+			oldConfiguration: yamlconfig.Configuration{Records: []yamlconfig.Record{
+				{Name: "person", Regex: yamlconfig.MustCreateRegex("^P"), Key: []string{"id"}},
+			}},
+			newConfiguration: yamlconfig.Configuration{Records: []yamlconfig.Record{
+				{Name: "person", Regex: yamlconfig.MustCreateRegex("^PE"), Key: []string{"branch", "id"}},
+			}},
+			want: []LayoutChange{
+				{Kind: RegexChanged, Record: "person", Old: "^P", New: "^PE"},
+				{Kind: KeyChanged, Record: "person", Old: "id", New: "branch, id"},
+			},
+		},
+		{
+			name: "Should find moved and resized fields",
+			oldConfiguration: yamlconfig.Configuration{Records: []yamlconfig.Record{
+				person(yamlconfig.Field{Name: "name", Initial: 5, End: 9}, yamlconfig.Field{Name: "age", Initial: 11, End: 12}),
+			}},
+			newConfiguration: yamlconfig.Configuration{Records: []yamlconfig.Record{
+				person(yamlconfig.Field{Name: "name", Initial: 5, End: 14}, yamlconfig.Field{Name: "age", Initial: 16, End: 17}),
+			}},
+			want: []LayoutChange{
+				{Kind: FieldResized, Record: "person", Field: "name", Old: "5", New: "10"},
+				{Kind: FieldMoved, Record: "person", Field: "age", Old: "11-12", New: "16-17"},
+			},
+		},
+		{
+			name: "Should find changed types and descriptions",
+			oldConfiguration: yamlconfig.Configuration{Records: []yamlconfig.Record{
+				person(yamlconfig.Field{Name: "salary", Initial: 1, End: 8}, yamlconfig.Field{Name: "birth", Initial: 9, End: 16, Type: yamlconfig.DateType}),
+			}},
+			newConfiguration: yamlconfig.Configuration{Records: []yamlconfig.Record{
+				person(yamlconfig.Field{Name: "salary", Initial: 1, End: 8, Type: yamlconfig.DecimalType, Decimals: 2}, yamlconfig.Field{Name: "birth", Initial: 9, End: 16, Type: yamlconfig.DateType, Format: "YYYYMMDD", Description: "date of birth"}),
+			}},
+			want: []LayoutChange{
+				{Kind: TypeChanged, Record: "person", Field: "salary", Old: "string", New: "decimal(2)"},
+				{Kind: DescriptionChanged, Record: "person", Field: "birth", Old: "", New: "date of birth"},
+			},
+		},
+		{
+			name: "Should find renamed fields by their positions",
+			oldConfiguration: yamlconfig.Configuration{Records: []yamlconfig.Record{
+				person(yamlconfig.Field{Name: "name", Initial: 5, End: 9}, yamlconfig.Field{Name: "age", Initial: 11, End: 12}),
+			}},
+			newConfiguration: yamlconfig.Configuration{Records: []yamlconfig.Record{
+				person(yamlconfig.Field{Name: "full name", Initial: 5, End: 9}, yamlconfig.Field{Name: "years", Initial: 11, End: 13}),
+			}},
+			want: []LayoutChange{
+				{Kind: FieldRenamed, Record: "person", Field: "full name", Old: "name", New: "full name"},
+				{Kind: FieldRenamed, Record: "person", Field: "years", Old: "age", New: "years"},
+				{Kind: FieldResized, Record: "person", Field: "years", Old: "2", New: "3"},
+			},
+		},
+		{
+			name: "Should prefer the same positions over an overlap when finding renamed fields",
+			oldConfiguration: yamlconfig.Configuration{Records: []yamlconfig.Record{
+				person(yamlconfig.Field{Name: "a", Initial: 1, End: 4}, yamlconfig.Field{Name: "b", Initial: 3, End: 6}),
+			}},
+			newConfiguration: yamlconfig.Configuration{Records: []yamlconfig.Record{
+				person(yamlconfig.Field{Name: "c", Initial: 2, End: 5}, yamlconfig.Field{Name: "d", Initial: 3, End: 6}),
+			}},
+			want: []LayoutChange{
+				{Kind: FieldRenamed, Record: "person", Field: "c", Old: "a", New: "c"},
+				{Kind: FieldMoved, Record: "person", Field: "c", Old: "1-4", New: "2-5"},
+				{Kind: FieldRenamed, Record: "person", Field: "d", Old: "b", New: "d"},
+			},
+		},
+		{
+			name: "Should find added and removed fields that do not overlap",
+			oldConfiguration: yamlconfig.Configuration{Records: []yamlconfig.Record{
+				person(yamlconfig.Field{Name: "name", Initial: 5, End: 9}, yamlconfig.Field{Name: "fax", Initial: 11, End: 20}),
+			}},
+			newConfiguration: yamlconfig.Configuration{Records: []yamlconfig.Record{
+				person(yamlconfig.Field{Name: "name", Initial: 5, End: 9}, yamlconfig.Field{Name: "email", Initial: 21, End: 60}),
+			}},
+			want: []LayoutChange{
+				{Kind: FieldAdded, Record: "person", Field: "email", New: "21-60"},
+				{Kind: FieldRemoved, Record: "person", Field: "fax", Old: "11-20"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareLayouts(tt.oldConfiguration, tt.newConfiguration); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareLayouts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteLayoutChanges(t *testing.T) {
+	changes := []LayoutChange{
+		{Kind: RecordAdded, Record: "trailer"},
+		{Kind: FieldRenamed, Record: "person", Field: "full name", Old: "name", New: "full name"},
+		{Kind: FieldMoved, Record: "person", Field: "age", Old: "11-12", New: "16-17"},
+	}
+
+	tests := []struct {
+		name  string
+		write func(*bytes.Buffer, []LayoutChange) error
+		want  string
+	}{
+		{
+			name: "Should write the changes as text",
+			write: func(buf *bytes.Buffer, changes []LayoutChange) error {
+				return WriteLayoutChangesAsText(buf, changes)
+			},
+			want: "+ record \"trailer\"\n" +
+				"~ record \"person\": field \"full name\": renamed from \"name\"\n" +
+				"~ record \"person\": field \"age\": moved from 11-12 to 16-17\n" +
+				"3 changes\n",
+		},
+		{
+			name: "Should write a change on the version as text",
+			write: func(buf *bytes.Buffer, changes []LayoutChange) error {
+				return WriteLayoutChangesAsText(buf, []LayoutChange{{Kind: VersionChanged, Old: "1.0", New: "1.1"}})
+			},
+			want: "~ version changed from \"1.0\" to \"1.1\"\n1 changes\n",
+		},
+		{
+			name: "Should write the changes as json",
+			write: func(buf *bytes.Buffer, changes []LayoutChange) error {
+				return WriteLayoutChangesAsJSON(buf, changes[:1])
+			},
+			want: "{\n  \"changes\": [\n    {\n      \"kind\": \"record_added\",\n      \"record\": \"trailer\"\n    }\n  ]\n}\n",
+		},
+		{
+			name: "Should write an empty list of changes as json",
+			write: func(buf *bytes.Buffer, changes []LayoutChange) error {
+				return WriteLayoutChangesAsJSON(buf, nil)
+			},
+			want: "{\n  \"changes\": []\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf, changes); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package diff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/extsort"
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// withoutKeyPrefix starts the sort keys of the lines without key, so that they come before the lines with key and are
+// compared by their order
+const withoutKeyPrefix = "\x00"
+
+// Reconcile compares the lines of the old file with the lines of the new file that have the same key, like CompareByKey,
+// but without holding the files in memory: the lines of each file are sorted by their keys with an external sort, which
+// keeps up to the given number of bytes in memory, and the sorted lines are then merged.
+// The given function is called for each compared line, in the order of the keys. The returned Result only has the counters
+func Reconcile(records []yamlconfig.Record, oldFile io.Reader, newFile io.Reader, keyOf KeyFunc, maxBytesInMemory int, handle func(LineDiff) error) (Result, error) {
+	oldLines, err := sortByKey(oldFile, keyOf, maxBytesInMemory)
+	if err != nil {
+		return Result{}, err
+	}
+	defer oldLines.Close()
+
+	newLines, err := sortByKey(newFile, keyOf, maxBytesInMemory)
+	if err != nil {
+		return Result{}, err
+	}
+	defer newLines.Close()
+
+	result := Result{}
+	hasOldLine, hasNewLine := oldLines.Next(), newLines.Next()
+	for hasOldLine || hasNewLine {
+		var lineDiff LineDiff
+		oldLine, newLine := oldLines.Entry(), newLines.Entry()
+		switch {
+		case !hasNewLine || (hasOldLine && oldLine.Key < newLine.Key):
+			lineDiff = removedLine(records, oldLine.Number, oldLine.Line, displayKey(oldLine.Key))
+			hasOldLine = oldLines.Next()
+		case !hasOldLine || newLine.Key < oldLine.Key:
+			lineDiff = addedLine(records, newLine.Number, newLine.Line, displayKey(newLine.Key))
+			hasNewLine = newLines.Next()
+		default:
+			lineDiff = compareLines(records, oldLine.Number, oldLine.Line, newLine.Number, newLine.Line, displayKey(oldLine.Key))
+			hasOldLine, hasNewLine = oldLines.Next(), newLines.Next()
+		}
+
+		result.count(lineDiff.Status)
+		if err = handle(lineDiff); err != nil {
+			return Result{}, err
+		}
+	}
+
+	if err = oldLines.Err(); err != nil {
+		return Result{}, err
+	}
+	return result, newLines.Err()
+}
+
+// sortByKey returns the lines of a given file sorted by their keys. Lines without key come first, in the order of the file
+func sortByKey(file io.Reader, keyOf KeyFunc, maxBytesInMemory int) (*extsort.Iterator, error) {
+	sorter := extsort.NewSorter(maxBytesInMemory)
+	defer sorter.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber, linesWithoutKey := 0, 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		key, hasKey := keyOf(line)
+		if !hasKey {
+			linesWithoutKey++
+			key = fmt.Sprintf("%v%020d", withoutKeyPrefix, linesWithoutKey)
+		}
+		if err := sorter.Add(extsort.Entry{Key: key, Number: lineNumber, Line: line}); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sorter.Sort()
+}
+
+// displayKey returns the key of a line as it is shown on the reports, e.g. "person: 001, 023"
+func displayKey(sortKey string) string {
+	if sortKey == "" || strings.HasPrefix(sortKey, withoutKeyPrefix) {
+		return ""
+	}
+	return strings.Replace(strings.Replace(sortKey, keySeparator, ": ", 1), keySeparator, ", ", -1)
+}
+
+// ReconciliationReport writes the lines compared by Reconcile grouped by what happened to them: the lines that are only on the
+// old file, the lines that are only on the new file and the matched lines that are different. Each group is kept on a
+// temporary file until the report is written, so that the report does not have to fit in memory
+type ReconciliationReport struct {
+	oldFileName string
+	newFileName string
+	sections    map[Status]*os.File
+	writers     map[Status]*bufio.Writer
+}
+
+// NewReconciliationReport returns a ReconciliationReport for the files with the given names.
+// Close must be called to remove its temporary files
+func NewReconciliationReport(oldFileName string, newFileName string) (*ReconciliationReport, error) {
+	report := &ReconciliationReport{
+		oldFileName: oldFileName,
+		newFileName: newFileName,
+		sections:    map[Status]*os.File{},
+		writers:     map[Status]*bufio.Writer{},
+	}
+	for _, status := range []Status{Removed, Added, Changed} {
+		section, err := ioutil.TempFile("", "fwf-reconcile-*")
+		if err != nil {
+			report.Close()
+			return nil, err
+		}
+		report.sections[status] = section
+		report.writers[status] = bufio.NewWriter(section)
+	}
+	return report, nil
+}
+
+// Add adds a compared line to the report. Equal lines are only counted
+func (report *ReconciliationReport) Add(lineDiff LineDiff) error {
+	writer, isSection := report.writers[lineDiff.Status]
+	if !isSection {
+		return nil
+	}
+
+	key := ""
+	if lineDiff.Key != "" {
+		key = fmt.Sprintf(", key %q", lineDiff.Key)
+	}
+
+	switch lineDiff.Status {
+	case Removed:
+		fmt.Fprintf(writer, "  line %v (%v%v)\n      %v\n", lineDiff.OldLineNumber, recordName(lineDiff.OldRecord), key, lineDiff.OldLine)
+	case Added:
+		fmt.Fprintf(writer, "  line %v (%v%v)\n      %v\n", lineDiff.NewLineNumber, recordName(lineDiff.NewRecord), key, lineDiff.NewLine)
+	case Changed:
+		fmt.Fprintf(writer, "  old line %v, new line %v (%v%v)\n", lineDiff.OldLineNumber, lineDiff.NewLineNumber, recordName(lineDiff.OldRecord), key)
+		for _, description := range describe(lineDiff) {
+			fmt.Fprintf(writer, "      %v\n", description)
+		}
+	}
+	return nil
+}
+
+// WriteReport writes the report, with the counters of the given result, to a given writer
+func (report *ReconciliationReport) WriteReport(w io.Writer, result Result) error {
+	sections := []struct {
+		status Status
+		title  string
+		count  int
+	}{
+		{Removed, "Only in " + report.oldFileName, result.Removed},
+		{Added, "Only in " + report.newFileName, result.Added},
+		{Changed, "Matched with differences", result.Changed},
+	}
+
+	for _, section := range sections {
+		if err := report.writers[section.status].Flush(); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%v (%v):\n", section.title, section.count); err != nil {
+			return err
+		}
+		file := report.sections[section.status]
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, file); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%v matched and equal, %v matched with differences, %v only in %v, %v only in %v\n",
+		result.Equal, result.Changed, result.Removed, report.oldFileName, result.Added, report.newFileName)
+	return err
+}
+
+// Close removes the temporary files of the report
+func (report *ReconciliationReport) Close() error {
+	var closeErr error
+	for _, section := range report.sections {
+		section.Close()
+		if err := os.Remove(section.Name()); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	return closeErr
+}
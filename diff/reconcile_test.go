@@ -0,0 +1,195 @@
+package diff
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+var keyedRecords = []yamlconfig.Record{
+	{
+		Name:   "header",
+		Regex:  yamlconfig.MustCreateRegex("^H"),
+		Fields: []yamlconfig.Field{{Name: "type", Initial: 1, End: 1}, {Name: "date", Initial: 2, End: 9}},
+	},
+	{
+		Name:  "person",
+		Regex: yamlconfig.MustCreateRegex("^P"),
+		Key:   []string{"id"},
+		Fields: []yamlconfig.Field{
+			{Name: "type", Initial: 1, End: 1},
+			{Name: "id", Initial: 2, End: 4},
+			{Name: "name", Initial: 5, End: 9},
+			{Name: "age", Initial: 11, End: 12},
+		},
+	},
+}
+
+func TestRecordKey(t *testing.T) {
+	keyOf, err := RecordKey(keyedRecords)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		line       string
+		wantKey    string
+		wantHasKey bool
+	}{
+		{"Should use the name of the record and the values of its key", "P001John |40", "person\x1f001", true},
+		{"Should not have a key when the record does not declare one", "H20210101", "", false},
+		{"Should not have a key when the line does not match any record", "X", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, hasKey := keyOf(tt.line)
+			if key != tt.wantKey || hasKey != tt.wantHasKey {
+				t.Errorf("RecordKey() = %v, %v, want %v, %v", key, hasKey, tt.wantKey, tt.wantHasKey)
+			}
+		})
+	}
+
+	if _, err = RecordKey(testRecords); err == nil {
+		t.Errorf("RecordKey() should return an error when no record declares a key")
+	}
+}
+
+func TestRecordKey_KeyValuesWithSeparator(t *testing.T) {
+	records := []yamlconfig.Record{
+		{
+			Name:   "person",
+			Regex:  yamlconfig.MustCreateRegex("^P"),
+			Key:    []string{"first", "second"},
+			Fields: []yamlconfig.Field{{Name: "first", Initial: 2, End: 5}, {Name: "second", Initial: 6, End: 9}},
+		},
+	}
+	keyOf, err := RecordKey(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key1, _ := keyOf("PA, BC   ")
+	key2, _ := keyOf("PA   B, C")
+	if key1 == key2 {
+		t.Errorf("RecordKey() = %q for both lines, want different keys", key1)
+	}
+	if got, want := displayKey(key1), "person: A, B, C"; got != want {
+		t.Errorf("displayKey() = %q, want %q", got, want)
+	}
+}
+
+func Test_displayKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		sortKey string
+		want    string
+	}{
+		{"Should show the name of the record and the values of its key", "person\x1f001\x1f023", "person: 001, 023"},
+		{"Should show the value of a field key as it is", "001", "001"},
+		{"Should not show the sort key of a line without key", withoutKeyPrefix + "00000000000000000001", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := displayKey(tt.sortKey); got != tt.want {
+				t.Errorf("displayKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	oldLines := []string{"H20210101", "P003Ann  |20", "P001John |40", "P002Mary |30", "P001John |41"}
+	newLines := []string{"H20210102", "P004Bob  |50", "P001John |40", "P003Ann  |21"}
+	keyOf, err := RecordKey(keyedRecords)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, maxBytesInMemory := range []int{1 << 20, 1} {
+		var got []string
+		result, err := Reconcile(keyedRecords, strings.NewReader(strings.Join(oldLines, "\n")), strings.NewReader(strings.Join(newLines, "\r\n")), keyOf, maxBytesInMemory, func(lineDiff LineDiff) error {
+			got = append(got, describeForTest(lineDiff))
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{
+			"changed 1 1 [date]",
+			"equal 3 3 []",
+			"removed 5 0 []",
+			"removed 4 0 []",
+			"changed 2 4 [age]",
+			"added 0 2 []",
+		}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("Reconcile() with %v bytes in memory = %v, want %v", maxBytesInMemory, got, want)
+		}
+		if result.Equal != 1 || result.Changed != 2 || result.Added != 1 || result.Removed != 2 || len(result.Lines) != 0 {
+			t.Errorf("Reconcile() result = %+v, want 1 equal, 2 changed, 1 added, 2 removed and no lines", result)
+		}
+
+		// the same lines are compared by CompareByKey, in a different order
+		var wantSorted []string
+		for _, lineDiff := range CompareByKey(keyedRecords, oldLines, newLines, keyOf).Lines {
+			wantSorted = append(wantSorted, describeForTest(lineDiff))
+		}
+		sort.Strings(got)
+		sort.Strings(wantSorted)
+		if strings.Join(got, "\n") != strings.Join(wantSorted, "\n") {
+			t.Errorf("Reconcile() = %v, want the same comparisons as CompareByKey() %v", got, wantSorted)
+		}
+	}
+}
+
+// describeForTest returns the status, the line numbers and the changed fields of a compared line
+func describeForTest(lineDiff LineDiff) string {
+	var changedFields []string
+	for _, change := range lineDiff.Changes {
+		changedFields = append(changedFields, change.Field.Name)
+	}
+	return string(lineDiff.Status) + " " + strconv.Itoa(lineDiff.OldLineNumber) + " " + strconv.Itoa(lineDiff.NewLineNumber) + " [" + strings.Join(changedFields, " ") + "]"
+}
+
+func TestReconciliationReport(t *testing.T) {
+	report, err := NewReconciliationReport("old.txt", "new.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer report.Close()
+
+	keyOf, err := RecordKey(keyedRecords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Reconcile(keyedRecords, strings.NewReader("P001John |40\nP002Mary |30"), strings.NewReader("P001John |41\nP003Ann  |20"), keyOf, 1<<20, report.Add)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got bytes.Buffer
+	if err = report.WriteReport(&got, result); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `Only in old.txt (1):
+  line 2 (person, key "person: 002")
+      P002Mary |30
+Only in new.txt (1):
+  line 2 (person, key "person: 003")
+      P003Ann  |20
+Matched with differences (1):
+  old line 1, new line 1 (person, key "person: 001")
+      age (11-12): "40" -> "41"
+0 matched and equal, 1 matched with differences, 1 only in old.txt, 1 only in new.txt
+`
+	if got.String() != want {
+		t.Errorf("ReconciliationReport.WriteReport() = %v, want %v", got.String(), want)
+	}
+}
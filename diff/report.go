@@ -0,0 +1,263 @@
+package diff
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+var htmlTemplate = `
+	<!DOCTYPE html>
+	<html>
+		<head>
+			<meta charset="utf-8">
+			<title>fwf diff: {{.OldFileName}} / {{.NewFileName}}</title>
+			<style>
+				body {
+					font-family: sans-serif;
+				}
+
+				.summary > span {
+					display: inline-block;
+					padding: 2px 8px;
+					margin-right: 4px;
+					border-radius: 4px;
+				}
+
+				table {
+					border-collapse: collapse;
+					width: 100%;
+				}
+
+				th, td {
+					border-bottom: 1px solid #ddd;
+					padding: 2px 6px;
+					text-align: left;
+					vertical-align: top;
+				}
+
+				td.line {
+					font-family: 'Courier New', Courier, monospace;
+					white-space: pre;
+				}
+
+				td.number {
+					color: #888;
+					text-align: right;
+				}
+
+				.changed {
+					background-color: rgb(255,243,205);
+				}
+
+				.added {
+					background-color: rgb(220,245,220);
+				}
+
+				.removed {
+					background-color: rgb(255,225,225);
+				}
+
+				.field {
+					box-shadow: 0 0 3px rgb(0,100,0);
+				}
+
+				.field.changedfield {
+					background-color: rgb(255,170,80);
+				}
+
+				.changes {
+					color: #555;
+					font-size: 0.9em;
+				}
+
+				.changes td {
+					padding-left: 24px;
+				}
+			</style>
+		</head>
+		<body>
+			<h2>{{.OldFileName}} &rarr; {{.NewFileName}}</h2>
+			<div class='summary'>
+				{{- if .Alignment}}
+				<span>Lines aligned by {{.Alignment}}</span>
+				{{- else}}
+				<span>Lines aligned by position</span>
+				{{- end}}
+				<span>{{.Result.Equal}} equal</span>
+				<span class='changed'>{{.Result.Changed}} changed</span>
+				<span class='added'>{{.Result.Added}} added</span>
+				<span class='removed'>{{.Result.Removed}} removed</span>
+			</div>
+			<table>
+				<tr><th>Line</th><th>{{.OldFileName}}</th><th>Line</th><th>{{.NewFileName}}</th></tr>
+				{{- range .Lines}}
+				<tr class='{{.Status}}'>
+					<td class='number'>{{if .OldLineNumber}}{{.OldLineNumber}}{{end}}</td>
+					<td class='line'>{{range .OldSegments}}{{if .Field}}<span class='field{{if .Changed}} changedfield{{end}}' title='{{.Title}}'>{{.Text}}</span>{{else}}{{.Text}}{{end}}{{end}}</td>
+					<td class='number'>{{if .NewLineNumber}}{{.NewLineNumber}}{{end}}</td>
+					<td class='line'>{{range .NewSegments}}{{if .Field}}<span class='field{{if .Changed}} changedfield{{end}}' title='{{.Title}}'>{{.Text}}</span>{{else}}{{.Text}}{{end}}{{end}}</td>
+				</tr>
+				{{- if .Descriptions}}
+				<tr class='changes'>
+					<td colspan='4'>{{range .Descriptions}}{{.}}<br>{{end}}</td>
+				</tr>
+				{{- end}}
+				{{- end}}
+			</table>
+		</body>
+	</html>`
+
+// htmlLine is a compared line on the html report
+type htmlLine struct {
+	Status        Status
+	OldLineNumber int
+	OldSegments   []segment
+	NewLineNumber int
+	NewSegments   []segment
+	Descriptions  []string
+}
+
+// segment is a part of a line on the html report, which is either a field or the characters between fields
+type segment struct {
+	Text    string
+	Field   bool
+	Changed bool
+	Title   string
+}
+
+// segmentsOf splits a line of a given record, which may be nil, into its fields and the characters between them
+func segmentsOf(record *yamlconfig.Record, line string, changes []FieldChange) []segment {
+	if record == nil {
+		return []segment{{Text: line}}
+	}
+
+	isChanged := map[string]bool{}
+	for _, change := range changes {
+		isChanged[change.Field.Name] = true
+	}
+
+	fields := make([]yamlconfig.Field, len(record.Fields))
+	copy(fields, record.Fields)
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Initial < fields[j].Initial
+	})
+
+	runes := []rune(line)
+	var segments []segment
+	position := 1
+	for _, field := range fields {
+		if field.Initial > len(runes) {
+			break
+		}
+		if field.Initial > position {
+			segments = append(segments, segment{Text: string(runes[position-1 : field.Initial-1])})
+		}
+		segments = append(segments, segment{
+			Text:    field.RawValue(line),
+			Field:   true,
+			Changed: isChanged[field.Name],
+			Title:   fmt.Sprintf("%v (%v-%v)", field.Name, field.Initial, field.End),
+		})
+		position = field.End + 1
+	}
+	if position <= len(runes) {
+		segments = append(segments, segment{Text: string(runes[position-1:])})
+	}
+	return segments
+}
+
+// describe returns the description of the differences of a compared line
+func describe(lineDiff LineDiff) []string {
+	var descriptions []string
+	if lineDiff.RecordChanged {
+		descriptions = append(descriptions, fmt.Sprintf("record changed from %v to %v", recordName(lineDiff.OldRecord), recordName(lineDiff.NewRecord)))
+	}
+	for _, change := range lineDiff.Changes {
+		descriptions = append(descriptions, fmt.Sprintf("%v (%v-%v): %q -> %q", change.Field.Name, change.Field.Initial, change.Field.End, change.OldValue, change.NewValue))
+	}
+	if lineDiff.OutsideFieldsChanged {
+		descriptions = append(descriptions, "characters outside of the fields changed")
+	}
+	return descriptions
+}
+
+// recordName returns the name of a record, which may be nil
+func recordName(record *yamlconfig.Record) string {
+	if record == nil {
+		return "(no record)"
+	}
+	return record.Name
+}
+
+// ExportHTML returns a self-contained html page with the side by side comparison of the files. The alignment describes the keys
+// used to align the lines, and it is empty if the lines were aligned by their position. Equal lines are only shown if
+// showEqualLines is true, but they are always counted
+func ExportHTML(result Result, oldFileName string, newFileName string, alignment string, showEqualLines bool) string {
+	t := template.Must(template.New("diffTemplate").Parse(htmlTemplate))
+
+	var lines []htmlLine
+	for _, lineDiff := range result.Lines {
+		if lineDiff.Status == Equal && !showEqualLines {
+			continue
+		}
+		lines = append(lines, htmlLine{
+			Status:        lineDiff.Status,
+			OldLineNumber: lineDiff.OldLineNumber,
+			OldSegments:   segmentsOf(lineDiff.OldRecord, lineDiff.OldLine, lineDiff.Changes),
+			NewLineNumber: lineDiff.NewLineNumber,
+			NewSegments:   segmentsOf(lineDiff.NewRecord, lineDiff.NewLine, lineDiff.Changes),
+			Descriptions:  describe(lineDiff),
+		})
+	}
+
+	var buf bytes.Buffer
+	err := t.Execute(&buf, struct {
+		OldFileName string
+		NewFileName string
+		Alignment   string
+		Result      Result
+		Lines       []htmlLine
+	}{oldFileName, newFileName, alignment, result, lines})
+	if err != nil {
+		panic(err)
+	}
+	return buf.String()
+}
+
+// WriteText writes the comparison of the files as text. Equal lines are only written if showEqualLines is true,
+// but they are always counted
+func WriteText(w io.Writer, result Result, showEqualLines bool) error {
+	writer := bufio.NewWriter(w)
+	for _, lineDiff := range result.Lines {
+		if lineDiff.Status == Equal && !showEqualLines {
+			continue
+		}
+
+		key := ""
+		if lineDiff.Key != "" {
+			key = fmt.Sprintf(", key %q", lineDiff.Key)
+		}
+
+		switch lineDiff.Status {
+		case Equal:
+			fmt.Fprintf(writer, "= old line %v, new line %v (%v%v)\n", lineDiff.OldLineNumber, lineDiff.NewLineNumber, recordName(lineDiff.OldRecord), key)
+		case Changed:
+			fmt.Fprintf(writer, "~ old line %v, new line %v (%v%v)\n", lineDiff.OldLineNumber, lineDiff.NewLineNumber, recordName(lineDiff.OldRecord), key)
+			for _, description := range describe(lineDiff) {
+				fmt.Fprintf(writer, "    %v\n", description)
+			}
+		case Removed:
+			fmt.Fprintf(writer, "- old line %v (%v%v)\n    %v\n", lineDiff.OldLineNumber, recordName(lineDiff.OldRecord), key, lineDiff.OldLine)
+		case Added:
+			fmt.Fprintf(writer, "+ new line %v (%v%v)\n    %v\n", lineDiff.NewLineNumber, recordName(lineDiff.NewRecord), key, lineDiff.NewLine)
+		}
+	}
+	fmt.Fprintf(writer, "%v equal, %v changed, %v added, %v removed\n", result.Equal, result.Changed, result.Added, result.Removed)
+	return writer.Flush()
+}
@@ -0,0 +1,80 @@
+package diff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	keyOf, err := FieldKey(testRecords, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := CompareByKey(testRecords, []string{"H20210101", "P001John |40", "P002Mary |30"}, []string{"H20210101", "P001Jon  |40", "P003Ann  |20"}, keyOf)
+
+	tests := []struct {
+		name           string
+		showEqualLines bool
+		want           string
+	}{
+		{
+			"Should write the differences and the counters",
+			false,
+			`~ old line 2, new line 2 (person, key "001")
+    name (5-9): "John " -> "Jon  "
+- old line 3 (person, key "002")
+    P002Mary |30
++ new line 3 (person, key "003")
+    P003Ann  |20
+1 equal, 1 changed, 1 added, 1 removed
+`,
+		},
+		{
+			"Should write the equal lines as well",
+			true,
+			`= old line 1, new line 1 (header)
+~ old line 2, new line 2 (person, key "001")
+    name (5-9): "John " -> "Jon  "
+- old line 3 (person, key "002")
+    P002Mary |30
++ new line 3 (person, key "003")
+    P003Ann  |20
+1 equal, 1 changed, 1 added, 1 removed
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bytes.Buffer
+			if err := WriteText(&got, result, tt.showEqualLines); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("WriteText() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	result := CompareByLine(testRecords, []string{"H20210101", "P001John |40", "P<b>"}, []string{"H20210101", "P001Jon  |40"})
+
+	got := ExportHTML(result, "old.txt", "new.txt", "", false)
+	for _, content := range []string{
+		"<h2>old.txt &rarr; new.txt</h2>",
+		"<span class='changed'>1 changed</span>",
+		"<span class='field changedfield' title='name (5-9)'>John </span>",
+		"<span class='field changedfield' title='name (5-9)'>Jon  </span>",
+		"<span class='field' title='age (11-12)'>40</span>",
+		"name (5-9): &#34;John &#34; -&gt; &#34;Jon  &#34;",
+		"&lt;b&gt;",
+	} {
+		if !strings.Contains(got, content) {
+			t.Errorf("ExportHTML() = %v, want it to contain %v", got, content)
+		}
+	}
+	if strings.Contains(got, "20210101") {
+		t.Errorf("ExportHTML() = %v, want it not to contain the equal lines", got)
+	}
+}
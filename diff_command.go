@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/pedroppinheiro/fwf/diff"
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// runDiffCommand handles "fwf diff", which compares two files field by field
+func runDiffCommand(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage of diff: fwf diff -yaml=configuration.yaml [flags] old.txt new.txt")
+		flags.PrintDefaults()
+	}
+	diffYAMLLocation := flags.String("yaml", "", "the full path for the yaml configuration")
+	keyField := flags.String("key", "", "the name of the field used to align the lines of both files, if none is given the keys of the records are used and, if the records have no key, the lines are aligned by their position")
+	format := flags.String("format", "html", "the format of the report: \"html\" creates a diff.html file and opens it in the browser, \"text\" writes the report to the standard output")
+	outputLocation := flags.String("o", "./", "the path to where the html report should be created")
+	showEqualLines := flags.Bool("all", false, "show the equal lines on the report as well")
+	flags.Parse(args)
+
+	if *diffYAMLLocation == "" {
+		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf diff -h\" for help")
+	}
+	if flags.NArg() != 2 {
+		panic("Please provide the old and the new files after the flags, use \"fwf diff -h\" for help")
+	}
+
+	configuration := readConfigurationFromYAML(*diffYAMLLocation)
+	oldFileLocation, newFileLocation := flags.Arg(0), flags.Arg(1)
+	oldLines, newLines := readLines(oldFileLocation), readLines(newFileLocation)
+
+	keyOf, alignment := getKeyFunc(configuration.Records, *keyField)
+	var result diff.Result
+	if keyOf == nil {
+		result = diff.CompareByLine(configuration.Records, oldLines, newLines)
+	} else {
+		result = diff.CompareByKey(configuration.Records, oldLines, newLines, keyOf)
+	}
+
+	switch *format {
+	case "text":
+		if err := diff.WriteText(os.Stdout, result, *showEqualLines); err != nil {
+			panic(err)
+		}
+	case "html":
+		report := diff.ExportHTML(result, filepath.Base(oldFileLocation), filepath.Base(newFileLocation), alignment, *showEqualLines)
+		generatedFilePath := *outputLocation + "diff.html"
+		if err := ioutil.WriteFile(generatedFilePath, []byte(report), 0777); err != nil {
+			panic(err)
+		}
+		log.Printf("File created successfully on %v\n", generatedFilePath)
+		OpenInBrowser(generatedFilePath)
+	default:
+		panic("Unknown format \"" + *format + "\", use \"fwf diff -h\" for help")
+	}
+
+	if result.HasDifferences() {
+		os.Exit(1)
+	}
+}
+
+// getKeyFunc returns the function that gives the keys used to align the lines of two files, along with a description of
+// the keys. If a key field is given, the key is the value of that field, otherwise the keys declared on the records are used.
+// Nil is returned if no key field is given and no record declares a key
+func getKeyFunc(records []yamlconfig.Record, keyField string) (diff.KeyFunc, string) {
+	if keyField != "" {
+		keyOf, err := diff.FieldKey(records, keyField)
+		if err != nil {
+			panic(err)
+		}
+		return keyOf, keyField
+	}
+	if diff.HasRecordKey(records) {
+		keyOf, err := diff.RecordKey(records)
+		if err != nil {
+			panic(err)
+		}
+		return keyOf, "the keys of the records"
+	}
+	return nil, ""
+}
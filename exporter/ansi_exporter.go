@@ -0,0 +1,131 @@
+package exporter
+
+import (
+	"hash/fnv"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+const ansiReset = "\x1b[0m"
+
+// ANSIColors are the background colors used to mark fields on a terminal. Black text is used on top of them
+var ANSIColors = []string{
+	"\x1b[30;42m", // green
+	"\x1b[30;46m", // cyan
+	"\x1b[30;43m", // yellow
+	"\x1b[30;45m", // magenta
+	"\x1b[30;44m", // blue
+	"\x1b[30;41m", // red
+	"\x1b[30;47m", // white
+}
+
+// ANSIExporter is an implementation of the Exporter interface,
+// in which is responsible to mark a string with ANSI escape codes, so that each field is colored differently on a terminal
+type ANSIExporter struct {
+	defaultFileName string
+}
+
+// GetANSIExporter returns the initialized ANSIExporter
+func GetANSIExporter() ANSIExporter {
+	return ANSIExporter{"index.ansi"}
+}
+
+// ansiRecordMarker marks the fields of a single record, using the next color for each field so that
+// adjacent fields never share the same color
+type ansiRecordMarker struct {
+	colorByInitialPosition map[int]string
+}
+
+// ObtainInitialMarker returns the escape code that starts the color of the field
+func (marker ansiRecordMarker) ObtainInitialMarker(field yamlconfig.Field) string {
+	return marker.colorByInitialPosition[field.Initial]
+}
+
+// ObtainEndMarker returns the escape code that resets the color
+func (marker ansiRecordMarker) ObtainEndMarker(field yamlconfig.Field) string {
+	return ansiReset
+}
+
+// FieldColor returns the color of the field with the given index, when the fields of a record are sorted by their positions
+func FieldColor(index int) string {
+	return ANSIColors[index%len(ANSIColors)]
+}
+
+// ObtainInitialMarker returns the escape code that starts the color of the field.
+// Since there is no information about the other fields, the color is chosen based on the field's name
+func (exporter ANSIExporter) ObtainInitialMarker(field yamlconfig.Field) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(field.Name))
+	return FieldColor(int(hash.Sum32() % uint32(len(ANSIColors))))
+}
+
+// ObtainEndMarker returns the escape code that resets the color
+func (exporter ANSIExporter) ObtainEndMarker(field yamlconfig.Field) string {
+	return ansiReset
+}
+
+// ControlPicture returns the visible placeholder of a control character, which has the same width of a character, so that
+// a file can not change the colors, move the cursor or clear the terminal on which it is shown. C0 characters are shown
+// as their control pictures, e.g. "␛" for the escape that starts ANSI escape codes, DEL as "␡" and C1 characters as "�".
+// Tabs and the other characters are returned as they are
+func ControlPicture(r rune) rune {
+	switch {
+	case r == '\t':
+		return r
+	case r < ' ':
+		return '\u2400' + r
+	case r == '\u007f':
+		return '\u2421'
+	case r >= '\u0080' && r <= '\u009f':
+		return unicode.ReplacementChar
+	}
+	return r
+}
+
+// ReplaceControlCharacters returns a string with each control character replaced with its placeholder, see ControlPicture
+func ReplaceControlCharacters(s string) string {
+	return strings.Map(ControlPicture, s)
+}
+
+// MarkRecordsOnString finds the record of a given string and colors each one of its fields. The control characters of the
+// string are replaced with visible placeholders, except for its line break.
+// It returns the marked string
+func (exporter ANSIExporter) MarkRecordsOnString(records []yamlconfig.Record, s string) string {
+	content := strings.TrimRight(s, "\r\n")
+	lineBreak := s[len(content):]
+
+	record, isRecordFound := yamlconfig.FindFirstRecordThatMatchesString(records, s)
+	s = ReplaceControlCharacters(content) + lineBreak
+	if !isRecordFound || len(record.Fields) == 0 {
+		return s
+	}
+
+	fields := make([]yamlconfig.Field, len(record.Fields))
+	copy(fields, record.Fields)
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Initial < fields[j].Initial
+	})
+
+	marker := ansiRecordMarker{colorByInitialPosition: map[int]string{}}
+	for i, field := range fields {
+		marker.colorByInitialPosition[field.Initial] = FieldColor(i)
+	}
+
+	return yamlconfig.ApplyMarkerToFieldsOnString(marker, fields, s)
+}
+
+// ExportVisualization returns the marked content as it is, since the escape codes are already enough to visualize it on a terminal
+func (exporter ANSIExporter) ExportVisualization(visualization Visualization) (string, error) {
+	return visualization.Content, nil
+}
+
+// SaveToFile saves a given string to a given path. The file may be seen with "less -R"
+func (exporter ANSIExporter) SaveToFile(s string, path string) (generatedFilePath string, err error) {
+	generatedFilePath = path + exporter.defaultFileName
+	err = ioutil.WriteFile(generatedFilePath, []byte(s), 0666)
+	return
+}
@@ -0,0 +1,78 @@
+package exporter
+
+import (
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+func TestANSIExporter_MarkRecordsOnString(t *testing.T) {
+	var records = []yamlconfig.Record{
+		{
+			Name:  "record A",
+			Regex: yamlconfig.MustCreateRegex("^A.*$"),
+			Fields: []yamlconfig.Field{
+				{
+					Name:    "field 2",
+					Initial: 2,
+					End:     3,
+				},
+				{
+					Name:    "field 1",
+					Initial: 1,
+					End:     1,
+				},
+			},
+		},
+		{
+			Name:  "record B",
+			Regex: yamlconfig.MustCreateRegex("^B.*$"),
+		},
+	}
+
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{
+			"Should color adjacent fields with different colors",
+			"Abcd",
+			ANSIColors[0] + "A" + ansiReset + ANSIColors[1] + "bc" + ansiReset + "d",
+		},
+		{
+			"Should not color a record without fields",
+			"Bbcd",
+			"Bbcd",
+		},
+		{
+			"Should not color a string that does not match any record",
+			"Cbcd",
+			"Cbcd",
+		},
+		{
+			"Should replace the control characters of a field",
+			"A\x1b[2Jd",
+			ANSIColors[0] + "A" + ansiReset + ANSIColors[1] + "\u241b[" + ansiReset + "2Jd",
+		},
+		{
+			"Should replace the control characters of a string that does not match any record, except for tabs and its line break",
+			"C\tb\x00\u007f\u009b\r\n",
+			"C\tb\u2400\u2421\ufffd\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetANSIExporter().MarkRecordsOnString(records, tt.s); got != tt.want {
+				t.Errorf("ANSIExporter.MarkRecordsOnString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestANSIExporter_ObtainInitialMarker(t *testing.T) {
+	field := yamlconfig.Field{Name: "field 1", Initial: 1, End: 1}
+	if GetANSIExporter().ObtainInitialMarker(field) != GetANSIExporter().ObtainInitialMarker(field) {
+		t.Errorf("ANSIExporter.ObtainInitialMarker() should always return the same color for the same field")
+	}
+}
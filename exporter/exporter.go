@@ -1,13 +1,18 @@
 package exporter
 
 import (
+	"io"
+	"path/filepath"
+	"time"
+
 	"github.com/pedroppinheiro/fwf/yamlconfig"
 )
 
 //Exporter defines the interface for all exporters
 type Exporter interface {
-	// ExportVisualization will take a given string and may add specific content to aid in the visualizing of the end result
-	ExportVisualization(string) string
+	// ExportVisualization will take a given visualization and may add specific content to aid in the visualizing of the end result,
+	// such as a description of its records and its summary. An error is returned if the visualization can not be exported
+	ExportVisualization(visualization Visualization) (string, error)
 
 	// SaveToFile saves a given string to a given path
 	SaveToFile(s string, path string) (generatedFilePath string, err error)
@@ -16,3 +21,82 @@ type Exporter interface {
 
 	yamlconfig.Marker
 }
+
+// Visualization holds the marked content of a file, along with the records used to mark it and a summary of its lines
+type Visualization struct {
+	Records []yamlconfig.Record
+	Content string
+	Summary Summary
+
+	// Page describes which lines of the file are on the content, or nil if the content holds the whole file
+	Page *Page
+
+	Header Header
+}
+
+// Header identifies the file and the layout of a visualization
+type Header struct {
+	FileName      string
+	LayoutVersion string
+	GeneratedAt   time.Time
+}
+
+// NewHeader returns the header of a visualization of the file on the given location, generated now
+func NewHeader(fileLocation string, layoutVersion string) Header {
+	return Header{
+		FileName:      filepath.Base(fileLocation),
+		LayoutVersion: layoutVersion,
+		GeneratedAt:   time.Now(),
+	}
+}
+
+// Summary holds counters of how the lines of a file are covered by the records and their fields
+type Summary struct {
+	Lines int
+
+	// UnmatchedLines are the lines that do not match any record
+	UnmatchedLines int
+
+	// LinesWithGaps are the lines that have characters that are not covered by any field of their record
+	LinesWithGaps int
+	GapCharacters int
+
+	// LinesWithOverflow are the lines that have characters after the end of the last field of their record
+	LinesWithOverflow  int
+	OverflowCharacters int
+
+	// ShortLines are the lines that end before the end of the last field of their record
+	ShortLines        int
+	MissingCharacters int
+}
+
+// addLine adds a given line, which should be marked with the given records, to the summary
+func (summary *Summary) addLine(records []yamlconfig.Record, line string) {
+	summary.Lines++
+
+	record, isRecordFound := yamlconfig.FindFirstRecordThatMatchesString(records, line)
+	if !isRecordFound {
+		summary.UnmatchedLines++
+		return
+	}
+
+	coverage := record.Coverage(line)
+	if coverage.GapCharacters > 0 {
+		summary.LinesWithGaps++
+		summary.GapCharacters += coverage.GapCharacters
+	}
+	if coverage.Overflow.Initial > 0 {
+		summary.LinesWithOverflow++
+		summary.OverflowCharacters += coverage.Overflow.Length()
+	}
+	if coverage.MissingCharacters > 0 {
+		summary.ShortLines++
+		summary.MissingCharacters += coverage.MissingCharacters
+	}
+}
+
+// MarkRecordsOnReader reads all lines of a given reader and marks each one of them with the given exporter.
+// It returns the visualization with the concatenation of the marked lines
+func MarkRecordsOnReader(exporter Exporter, records []yamlconfig.Record, r io.Reader) (Visualization, error) {
+	return markRecordsOnLines(exporter, records, r, -1)
+}
@@ -0,0 +1,75 @@
+package exporter
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+func TestMarkRecordsOnReader(t *testing.T) {
+	var records = []yamlconfig.Record{
+		{
+			Name:  "record A",
+			Regex: yamlconfig.MustCreateRegex("^A"),
+			Fields: []yamlconfig.Field{
+				{
+					Name:    "field 1",
+					Initial: 1,
+					End:     1,
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		content     string
+		want        string
+		wantSummary Summary
+	}{
+		{
+			"Should mark every line",
+			"Abc\nBcd",
+			"<span data-record='0'><div class='tooltip fieldcolor6 field-4b968514'>A<span class='tooltiptext'><b>field 1</b> (record A)\nPositions: 1-1 (length 1)\nValue: [A]</span></div><span class='overflow'>bc</span>\n</span><span class='unmatched'>Bcd</span>",
+			Summary{Lines: 2, UnmatchedLines: 1, LinesWithOverflow: 1, OverflowCharacters: 2},
+		},
+		{
+			"Should not mark the empty line after the last line break",
+			"Abc\n",
+			"<span data-record='0'><div class='tooltip fieldcolor6 field-4b968514'>A<span class='tooltiptext'><b>field 1</b> (record A)\nPositions: 1-1 (length 1)\nValue: [A]</span></div><span class='overflow'>bc</span>\n</span>",
+			Summary{Lines: 1, LinesWithOverflow: 1, OverflowCharacters: 2},
+		},
+		{
+			"Should not mark empty content",
+			"",
+			"",
+			Summary{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarkRecordsOnReader(GetHTMLExporter(), records, strings.NewReader(tt.content))
+			if err != nil {
+				t.Errorf("MarkRecordsOnReader() error = %v", err)
+				return
+			}
+			if got.Content != tt.want {
+				t.Errorf("MarkRecordsOnReader() = %v, want %v", got.Content, tt.want)
+			}
+			if got.Summary != tt.wantSummary {
+				t.Errorf("MarkRecordsOnReader() summary = %+v, want %+v", got.Summary, tt.wantSummary)
+			}
+		})
+	}
+}
+
+func TestNewHeader(t *testing.T) {
+	before := time.Now()
+	got := NewHeader(filepath.Join("data", "daily.txt"), "1.2")
+	if got.FileName != "daily.txt" || got.LayoutVersion != "1.2" || got.GeneratedAt.Before(before) {
+		t.Errorf("NewHeader() = %+v, want the file name daily.txt, the version 1.2 and the current time", got)
+	}
+}
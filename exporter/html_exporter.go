@@ -3,9 +3,10 @@ package exporter
 import (
 	"bytes"
 	"fmt"
+	"html/template"
 	"io/ioutil"
-	"log"
-	"text/template"
+	"strings"
+	"time"
 
 	"github.com/pedroppinheiro/fwf/yamlconfig"
 )
@@ -39,6 +40,15 @@ var (
 						font-size: 1.5em;
 					}
 					
+					.tooltip.invalid {
+						box-shadow: 0 0 5px rgb(200,0,0,1);
+						background-color: rgb(255,220,220);
+					}
+
+					.tooltip .tooltiperror {
+						color: #f99;
+					}
+
 					.tooltip:hover {
 						box-shadow: 0 0 5px rgb(100,0,0,1);
 						/*box-shadow: 0 0 5px rgba(0,0,0,0.5);*/
@@ -59,6 +69,126 @@ var (
 					pre > span {
 						display: block;
 						line-height: 1.5rem;
+						border-left: 4px solid transparent;
+					}
+
+					.gap {
+						background-color: rgb(255,235,130);
+					}
+
+					.overflow {
+						background-color: rgb(255,190,120);
+						text-decoration: line-through;
+					}
+
+					.missing {
+						background-color: rgb(255,150,150);
+					}
+
+					.missing::after {
+						content: " \2190 " attr(data-missing) " missing";
+						font-size: 0.8em;
+					}
+
+					pre > span.unmatched {
+						color: #999;
+						font-style: italic;
+					}
+
+					.summary {
+						font-family: sans-serif;
+						margin-bottom: 8px;
+					}
+
+					.summary > span {
+						display: inline-block;
+						padding: 2px 8px;
+						margin-right: 4px;
+						border-radius: 4px;
+					}
+
+					pre > span.target {
+						background-color: rgb(255,255,180);
+						outline: 1px solid rgb(200,200,0);
+					}
+
+					.pagination {
+						font-family: sans-serif;
+						margin-bottom: 8px;
+					}
+
+					.pagination > * {
+						margin-right: 8px;
+					}
+
+					.search {
+						font-family: sans-serif;
+						margin-bottom: 8px;
+					}
+
+					#searcherror {
+						color: #b00;
+					}
+
+					pre > span.searchmatch {
+						background-color: rgb(255,250,200);
+					}
+
+					pre .tooltip.searchmatch {
+						outline: 2px solid rgb(255,140,0);
+					}
+
+					pre.filtered > span:not(.searchmatch) {
+						display: none;
+					}
+
+					pre.filtered > span:before {
+						content: attr(data-line);
+					}
+
+					.content {
+						position: relative;
+					}
+
+					#ruler {
+						position: sticky;
+						top: 0;
+						z-index: 1;
+						display: flex;
+						background-color: #f4f4f4;
+						border-bottom: 1px solid #ddd;
+						color: #888;
+						line-height: 1.2em;
+						width: max-content;
+						min-width: 100%;
+					}
+
+					/* the gutter has the same width as the border and the line numbers of the lines */
+					#ruler .rulergutter {
+						flex-shrink: 0;
+						width: 45px;
+						margin-right: .5em;
+						color: rgb(200,100,0);
+						font-weight: bold;
+						align-self: center;
+					}
+
+					#ruler .rulertext {
+						position: relative;
+						white-space: pre;
+					}
+
+					#rulercursor, #columnguide {
+						display: none;
+						position: absolute;
+						top: 0;
+						bottom: 0;
+						pointer-events: none;
+						background-color: rgba(255,140,0,0.35);
+					}
+
+					pre > span.colored {
+						border-left-style: solid;
 					}
 
 					pre > span:before {
@@ -71,9 +201,381 @@ var (
 						color: #888;
 						width: 40px;
 					}
+
+					.legend table {
+						border-collapse: collapse;
+						margin: 4px 0 8px 16px;
+						font-family: sans-serif;
+						font-size: 0.9em;
+					}
+
+					.legend th, .legend td {
+						border-bottom: 1px solid #ddd;
+						padding: 2px 8px;
+						text-align: left;
+					}
+
+					.legend .swatch, .legend .recordcolor {
+						display: inline-block;
+						width: 12px;
+						height: 12px;
+						margin-left: 4px;
+						vertical-align: middle;
+					}
+
+					.reportheader {
+						font-family: sans-serif;
+						margin-bottom: 8px;
+						padding-bottom: 8px;
+						border-bottom: 1px solid #ddd;
+					}
+
+					.reportheader h2 {
+						margin: 0 0 4px 0;
+					}
+
+					.reportheader span {
+						margin-right: 16px;
+						color: #666;
+					}
+
+					body.dark {
+						background-color: #1e1e1e;
+						color: #ddd;
+					}
+
+					body.dark a {
+						color: #8ab4f8;
+					}
+
+					body.dark .reportheader span, body.dark pre > span.unmatched {
+						color: #999;
+					}
+
+					body.dark .tooltip.invalid {
+						background-color: rgba(200,0,0,0.35);
+					}
+
+					body.dark .gap {
+						background-color: rgba(255,235,130,0.3);
+					}
+
+					body.dark .overflow {
+						background-color: rgba(255,190,120,0.3);
+					}
+
+					body.dark .missing {
+						background-color: rgba(255,150,150,0.3);
+					}
+
+					body.dark pre > span.searchmatch, body.dark pre > span.target {
+						background-color: rgba(255,255,180,0.2);
+					}
+
+					body.dark #ruler {
+						background-color: #2a2a2a;
+						border-bottom-color: #444;
+					}
+
+					body.dark pre > span:before, body.dark .legend th, body.dark .legend td, body.dark .reportheader {
+						border-color: #444;
+					}
+
+					body.dark input, body.dark button {
+						background-color: #333;
+						color: #ddd;
+						border: 1px solid #555;
+					}
+
+					{{.FieldColorsCSS}}
 				</style>
+				<style id='hiddenfields'></style>
 			</head>
-			<body><pre>{{.}}</pre>
+			<body class='{{.Theme}}'>
+				{{- with .Header}}{{if .FileName}}
+				<header class='reportheader'>
+					<h2>{{.FileName}}</h2>
+					<span>Layout version: {{or .LayoutVersion "not informed"}}</span>
+					{{- if not .GeneratedAt.IsZero}}
+					<span>Generated at: {{.GeneratedAt.Format "2006-01-02 15:04:05 MST"}}</span>
+					{{- end}}
+				</header>
+				{{- end}}{{end}}
+				<div class='summary'>
+					<span>{{.Summary.Lines}} lines{{if .Page}} on this page{{end}}</span>
+					<span class='unmatched'>{{.Summary.UnmatchedLines}} without record</span>
+					<span class='gap'>{{.Summary.LinesWithGaps}} with unmapped gaps ({{.Summary.GapCharacters}} characters)</span>
+					<span class='overflow'>{{.Summary.LinesWithOverflow}} with characters after the last field ({{.Summary.OverflowCharacters}} characters)</span>
+					<span class='missing'>{{.Summary.ShortLines}} shorter than their record ({{.Summary.MissingCharacters}} missing characters)</span>
+				</div>
+				{{- if .Legend}}
+				<div class='legend'>
+					{{- range .Legend}}
+					<details open>
+						<summary>{{.Name}}{{if .Color}} <span class='recordcolor' style='background-color: {{.Color}}'></span>{{end}}</summary>
+						<table>
+							<tr><th>Show</th><th>Field</th><th>Positions</th><th>Length</th><th>Type</th><th>Description</th></tr>
+							{{- range .Fields}}
+							<tr>
+								<td><input type='checkbox' checked data-field='{{.ID}}'><span class='swatch {{.ColorClass}}'></span></td>
+								<td>{{.Name}}</td>
+								<td>{{.Initial}}-{{.End}}</td>
+								<td>{{.Length}}</td>
+								<td>{{.Type}}</td>
+								<td>{{.Description}}</td>
+							</tr>
+							{{- end}}
+						</table>
+					</details>
+					{{- end}}
+				</div>
+				<script>
+					(function () {
+						var checkboxes = document.querySelectorAll(".legend input[type=checkbox]");
+						checkboxes.forEach(function (checkbox) {
+							checkbox.addEventListener("change", function () {
+								var hiddenFields = [];
+								checkboxes.forEach(function (c) {
+									if (!c.checked) {
+										hiddenFields.push("pre ." + c.dataset.field);
+									}
+								});
+								document.getElementById("hiddenfields").textContent = hiddenFields.length === 0 ? "" :
+									hiddenFields.join(", ") + " { box-shadow: none; background-color: transparent; }";
+							});
+						});
+					})();
+				</script>
+				{{- end}}
+				<div class='search'>
+					<input id='search' type='search' size='60' placeholder='record = name, field = value, field ~ regex or any text'>
+					<label><input id='searchfilter' type='checkbox'> Show only matching lines</label>
+					<label>Column <input id='column' type='number' min='1' size='5'></label>
+					<span id='searchresult'></span>
+					<span id='searcherror'></span>
+				</div>
+				{{- with .Page}}
+				<form class='pagination' method='get'>
+					{{- if .HasPrevious}}
+					<a href='?page={{.Previous}}'>&laquo; Previous</a>
+					{{- end}}
+					<span>Page {{.Number}} of {{.Pages}}, lines {{.FirstLine}}-{{.LastLine}} of {{.TotalLines}}</span>
+					{{- if .HasNext}}
+					<a href='?page={{.Next}}'>Next &raquo;</a>
+					{{- end}}
+					<input type='number' name='line' min='1' max='{{.TotalLines}}' placeholder='line'>
+					<button>Go to line</button>
+				</form>
+				{{- end}}
+				<div class='content'>
+					<div id='ruler'>
+						<div class='rulergutter' id='rulercolumn'></div>
+						<div class='rulertext'><div id='rulernumbers'></div><div id='rulerticks'></div><div id='rulercursor'></div></div>
+					</div>
+					<pre{{with .Page}} data-first-line='{{.FirstLine}}' style='counter-reset: line {{.LineOffset}}'{{end}}>{{.Content}}</pre>
+					<div id='columnguide'></div>
+				</div>
+				<script>
+					(function () {
+						var pre = document.querySelector("pre");
+						var ruler = document.getElementById("ruler");
+						var rulerText = ruler.querySelector(".rulertext");
+						var rulerColumn = document.getElementById("rulercolumn");
+						var cursor = document.getElementById("rulercursor");
+						var guide = document.getElementById("columnguide");
+						var content = guide.parentNode;
+
+						ruler.style.font = window.getComputedStyle(pre).font;
+
+						var probe = document.createElement("span");
+						probe.textContent = "0000000000";
+						rulerText.appendChild(probe);
+						var characterWidth = probe.getBoundingClientRect().width / 10;
+						rulerText.removeChild(probe);
+
+						// the ruler has a number on column 1 and on every tenth column, ending on that column,
+						// and ticks on every fifth column
+						var columns = Math.ceil(pre.scrollWidth / characterWidth / 10) * 10 + 10;
+						var numbers = [];
+						var ticks = "";
+						for (var column = 1; column <= columns; column++) {
+							numbers.push(" ");
+							ticks += column % 10 === 0 ? "|" : column % 5 === 0 ? "+" : ".";
+						}
+						for (var column = 1; column <= columns; column = column === 1 ? 10 : column + 10) {
+							var label = String(column);
+							for (var i = 0; i < label.length; i++) {
+								numbers[column - label.length + i] = label[i];
+							}
+						}
+						document.getElementById("rulernumbers").textContent = numbers.join("");
+						document.getElementById("rulerticks").textContent = ticks;
+
+						function columnAt(x) {
+							return Math.floor((x - rulerText.getBoundingClientRect().left) / characterWidth) + 1;
+						}
+
+						pre.addEventListener("mousemove", function (event) {
+							var line = event.target.closest("pre > span");
+							var column = columnAt(event.clientX);
+							if (!line || column < 1) {
+								cursor.style.display = "none";
+								rulerColumn.textContent = "";
+								return;
+							}
+							cursor.style.display = "block";
+							cursor.style.left = (column - 1) * characterWidth + "px";
+							cursor.style.width = characterWidth + "px";
+							rulerColumn.textContent = column;
+							rulerColumn.title = "Line " + (line.dataset.line || "") + ", column " + column;
+						});
+
+						pre.addEventListener("mouseleave", function () {
+							cursor.style.display = "none";
+							rulerColumn.textContent = "";
+						});
+
+						document.getElementById("column").addEventListener("change", function () {
+							var column = Number(this.value);
+							if (!column || column < 1) {
+								guide.style.display = "none";
+								return;
+							}
+							var left = rulerText.getBoundingClientRect().left - content.getBoundingClientRect().left + (column - 1) * characterWidth;
+							guide.style.display = "block";
+							guide.style.left = left + "px";
+							guide.style.width = characterWidth + "px";
+							window.scrollTo({left: Math.max(0, content.getBoundingClientRect().left + window.scrollX + left - window.innerWidth / 2)});
+						});
+					})();
+				</script>
+				<script>
+					(function () {
+						var records = {{.Legend}} || [];
+						var pre = document.querySelector("pre");
+						var lines = Array.prototype.slice.call(pre.children);
+						var firstLine = Number(pre.dataset.firstLine || 1);
+						var searchInput = document.getElementById("search");
+						var filterCheckbox = document.getElementById("searchfilter");
+						var highlighted = [];
+						var timeout = null;
+
+						lines.forEach(function (line, i) {
+							line.dataset.line = firstLine + i;
+						});
+
+						// textOf returns the text of a line or of a field, without the text of the tooltips
+						function textOf(element) {
+							var walker = document.createTreeWalker(element, NodeFilter.SHOW_TEXT, null, false);
+							var text = "";
+							while (walker.nextNode()) {
+								if (!walker.currentNode.parentNode.closest(".tooltiptext")) {
+									text += walker.currentNode.textContent;
+								}
+							}
+							return text.replace(/[\r\n]+$/, "");
+						}
+
+						// parseCondition parses "name = value", "name ~ regex" or any other text, which is searched on the whole line
+						function parseCondition(text) {
+							var match = /^(.+?)\s*(=|~)\s*(.*)$/.exec(text);
+							if (!match) {
+								return {text: text.toLowerCase()};
+							}
+							var condition = {name: match[1].toLowerCase(), operator: match[2], value: match[3]};
+							if (condition.operator === "~") {
+								condition.regex = new RegExp(condition.value);
+							}
+							return condition;
+						}
+
+						function matchesValue(condition, value) {
+							return condition.regex ? condition.regex.test(value) : value.trim() === condition.value;
+						}
+
+						// matchesCondition tells if a line matches a condition, adding the matching fields to a given list
+						function matchesCondition(condition, line, matchingFields) {
+							if (condition.text !== undefined) {
+								return textOf(line).toLowerCase().indexOf(condition.text) !== -1;
+							}
+							var record = records[line.dataset.record];
+							if (!record) {
+								return false;
+							}
+							if (condition.name === "record") {
+								return condition.regex ? condition.regex.test(record.Name) : record.Name.toLowerCase() === condition.value.toLowerCase();
+							}
+							var isMatchFound = false;
+							(record.Fields || []).forEach(function (field) {
+								if (field.Name.toLowerCase() !== condition.name) {
+									return;
+								}
+								var element = line.querySelector("." + field.ID);
+								if (element && matchesValue(condition, textOf(element))) {
+									matchingFields.push(element);
+									isMatchFound = true;
+								}
+							});
+							return isMatchFound;
+						}
+
+						function search() {
+							highlighted.forEach(function (element) {
+								element.classList.remove("searchmatch");
+							});
+							highlighted = [];
+							document.getElementById("searcherror").textContent = "";
+							document.getElementById("searchresult").textContent = "";
+
+							var query = searchInput.value.trim();
+							var conditions;
+							try {
+								conditions = query === "" ? [] : query.split(/\s+and\s+/i).map(parseCondition);
+							} catch (error) {
+								document.getElementById("searcherror").textContent = error.message;
+								conditions = [];
+							}
+							pre.classList.toggle("filtered", filterCheckbox.checked && conditions.length > 0);
+							if (conditions.length === 0) {
+								return;
+							}
+
+							var matchingLines = 0;
+							lines.forEach(function (line) {
+								var matchingFields = [];
+								var isMatch = conditions.every(function (condition) {
+									return matchesCondition(condition, line, matchingFields);
+								});
+								if (isMatch) {
+									matchingLines++;
+									highlighted.push(line);
+									highlighted = highlighted.concat(matchingFields);
+								}
+							});
+							highlighted.forEach(function (element) {
+								element.classList.add("searchmatch");
+							});
+							document.getElementById("searchresult").textContent = matchingLines + " matching lines";
+						}
+
+						searchInput.addEventListener("input", function () {
+							clearTimeout(timeout);
+							timeout = setTimeout(search, 200);
+						});
+						filterCheckbox.addEventListener("change", search);
+					})();
+				</script>
+				{{- with .Page}}{{if .TargetLine}}
+				<script>
+					(function () {
+						var line = document.querySelector("pre").children[{{.TargetLine}} - {{.FirstLine}}];
+						if (line) {
+							line.classList.add("target");
+							line.scrollIntoView({block: "center"});
+						}
+					})();
+				</script>
+				{{- end}}{{end}}
 			</body>
 		</html>`
 )
@@ -83,11 +585,70 @@ var (
 type HTMLExporter struct {
 	htmlTemplate    string
 	defaultFileName string
+	theme           Theme
+
+	// masker masks the sensitive fields of the lines before they are marked, or it is nil when the lines are shown as they are.
+	// Lines without record are shown with every character that is not a space masked, as their fields are not known
+	masker LineMasker
 }
 
+// LineMasker masks the content of the sensitive fields of a line of a record, keeping the width of the fields
+type LineMasker interface {
+	MaskLine(record yamlconfig.Record, line string) string
+}
+
+// Theme is the set of colors of the html visualization
+type Theme string
+
+const (
+	// LightTheme has dark text on a light background, and it is the default theme
+	LightTheme Theme = "light"
+
+	// DarkTheme has light text on a dark background
+	DarkTheme Theme = "dark"
+)
+
 // GetHTMLExporter returns the initialized HTMLExporter with its custom template and marker
 func GetHTMLExporter() HTMLExporter {
-	return HTMLExporter{htmlTemplate, "index.html"}
+	return HTMLExporter{htmlTemplate, "index.html", LightTheme, nil}
+}
+
+// WithMasker returns a copy of the exporter that shows the lines with their sensitive fields masked by the given masker
+func (exporter HTMLExporter) WithMasker(masker LineMasker) HTMLExporter {
+	exporter.masker = masker
+	return exporter
+}
+
+// GetCustomHTMLExporter returns an HTMLExporter that uses the given template and theme. If the given template is empty the
+// built-in template is used. An error is returned if the template cannot be parsed or if the theme is unknown
+func GetCustomHTMLExporter(customTemplate string, theme Theme) (HTMLExporter, error) {
+	htmlExporter := GetHTMLExporter()
+
+	switch theme {
+	case LightTheme, DarkTheme:
+		htmlExporter.theme = theme
+	default:
+		return HTMLExporter{}, fmt.Errorf("unknown theme %q, it must be one of: light, dark", theme)
+	}
+
+	if customTemplate != "" {
+		htmlExporter.htmlTemplate = customTemplate
+		if _, err := htmlExporter.ExportVisualization(sampleVisualization); err != nil {
+			return HTMLExporter{}, err
+		}
+	}
+
+	return htmlExporter, nil
+}
+
+// sampleVisualization is the visualization of a page of a small file, with every part of the template data filled, on which
+// custom templates are tried when they are loaded, so that a template that references unknown data is rejected right away
+var sampleVisualization = Visualization{
+	Records: []yamlconfig.Record{{Name: "record", Fields: []yamlconfig.Field{{Name: "field", Initial: 1, End: 1}}}},
+	Content: "A",
+	Summary: Summary{Lines: 1},
+	Page:    &Page{Number: 1, Pages: 1, FirstLine: 1, LastLine: 1, TotalLines: 1, TargetLine: 1},
+	Header:  Header{FileName: "file.txt", LayoutVersion: "1", GeneratedAt: time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC)},
 }
 
 //ObtainInitialMarker returns a string corresponding to the initial field marker.
@@ -97,45 +658,187 @@ func (exporter HTMLExporter) ObtainInitialMarker(field yamlconfig.Field) string
 }
 
 //ObtainEndMarker returns a string corresponding to the end field marker.
-// A given field may be used to get more information
+// A given field may be used to get more information. The field's name is escaped, since it is placed inside the html
 func (exporter HTMLExporter) ObtainEndMarker(field yamlconfig.Field) string {
-	return fmt.Sprintf("<span class='tooltiptext'>%v</span></div>", field.Name)
+	return fmt.Sprintf("<span class='tooltiptext'>%v</span></div>", exporter.Escape(field.Name))
+}
+
+// Escape implements the yamlconfig.Escaper interface, so that the content of the lines is never interpreted as html
+func (exporter HTMLExporter) Escape(s string) string {
+	return template.HTMLEscapeString(s)
+}
+
+// htmlLineMarker marks the fields of a single line, so that the tooltip of each field can show its record, its value and
+// whether the value is valid for the field's type. It also marks the gaps between fields and the overflow after the last field
+type htmlLineMarker struct {
+	HTMLExporter
+	record      yamlconfig.Record
+	recordIndex int
+	line        string
+	coverage    yamlconfig.LineCoverage
+
+	// colorIndexByInitialPosition holds the index of the color of each field, see fieldColorIndexes
+	colorIndexByInitialPosition map[int]int
+
+	// isGapByInitialPosition tells which of the marked ranges are gaps instead of fields
+	isGapByInitialPosition map[int]bool
+}
+
+// newHTMLLineMarker returns the marker of a line that matches the record of the given index
+func newHTMLLineMarker(exporter HTMLExporter, record yamlconfig.Record, recordIndex int, line string) htmlLineMarker {
+	marker := htmlLineMarker{exporter, record, recordIndex, line, record.Coverage(line), map[int]int{}, map[int]bool{}}
+	fields := sortedFields(record)
+	for i, colorIndex := range fieldColorIndexes(fields) {
+		marker.colorIndexByInitialPosition[fields[i].Initial] = colorIndex
+	}
+	for _, gap := range marker.coverage.Gaps {
+		marker.isGapByInitialPosition[gap.Initial] = true
+	}
+	return marker
+}
+
+// rangesToMark returns the fields of the record along with the gaps and the overflow of the line, which are marked as well
+func (marker htmlLineMarker) rangesToMark() []yamlconfig.Field {
+	ranges := make([]yamlconfig.Field, 0, len(marker.record.Fields)+len(marker.coverage.Gaps)+1)
+	ranges = append(ranges, marker.record.Fields...)
+	ranges = append(ranges, marker.coverage.Gaps...)
+	if marker.isOverflow(marker.coverage.Overflow) {
+		ranges = append(ranges, marker.coverage.Overflow)
+	}
+	return ranges
+}
+
+// isOverflow returns true if the given range is the overflow of the line
+func (marker htmlLineMarker) isOverflow(field yamlconfig.Field) bool {
+	return marker.coverage.Overflow.Initial > 0 && field.Initial == marker.coverage.Overflow.Initial
+}
+
+// ObtainInitialMarker returns the initial field marker, which colors the field and highlights it when its value is invalid
+func (marker htmlLineMarker) ObtainInitialMarker(field yamlconfig.Field) string {
+	if marker.isGapByInitialPosition[field.Initial] {
+		return "<span class='gap'>"
+	}
+	if marker.isOverflow(field) {
+		return "<span class='overflow'>"
+	}
+
+	colorIndex := marker.colorIndexByInitialPosition[field.Initial]
+	classes := fmt.Sprintf("tooltip %v %v", fieldColorClass(colorIndex), fieldID(marker.record, field))
+	if _, err := marker.record.ParseFieldValue(field, marker.line); err != nil {
+		classes += " invalid"
+	}
+	return fmt.Sprintf("<div class='%v'>", classes)
+}
+
+// ObtainEndMarker returns the end field marker, with a tooltip that describes the field and its value on the line, along with
+// the meaning of the value for fields with known values
+func (marker htmlLineMarker) ObtainEndMarker(field yamlconfig.Field) string {
+	if marker.isGapByInitialPosition[field.Initial] || marker.isOverflow(field) {
+		return "</span>"
+	}
+
+	rawValue := field.RawValue(marker.line)
+
+	tooltip := fmt.Sprintf("<b>%v</b> (%v)", marker.Escape(field.Name), marker.Escape(marker.record.Name))
+	if field.Description != "" {
+		tooltip += "\n" + marker.Escape(field.Description)
+	}
+	tooltip += fmt.Sprintf("\nPositions: %v-%v (length %v)", field.Initial, field.End, field.Length())
+	tooltip += fmt.Sprintf("\nValue: [%v]", marker.Escape(rawValue))
+
+	if value, err := marker.record.ParseFieldValue(field, marker.line); err != nil {
+		tooltip += fmt.Sprintf("\n<span class='tooltiperror'>Error: %v</span>", marker.Escape(err.Error()))
+	} else if field.Type != "" && field.Type != yamlconfig.StringType {
+		tooltip += fmt.Sprintf("\nValue as %v: %v", field.Type, marker.Escape(yamlconfig.FormatValue(value)))
+	}
+	if description, isKnown := field.ValueDescription(rawValue); isKnown {
+		tooltip += fmt.Sprintf("\nMeaning: %v", marker.Escape(description))
+	}
+
+	return fmt.Sprintf("<span class='tooltiptext'>%v</span></div>", tooltip)
+}
+
+// maskUnmatchedLine replaces each character of a line without record that is not a space with "*", since any of them may
+// be sensitive
+func maskUnmatchedLine(line string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' {
+			return r
+		}
+		return '*'
+	}, line)
 }
 
 // MarkRecordsOnString goes through all the given records and marks a given string based on the records's fields.
+// Lines without record, gaps between fields, characters after the last field and lines shorter than their record are marked as well.
 // It returns the marked string
 func (exporter HTMLExporter) MarkRecordsOnString(records []yamlconfig.Record, s string) string {
-	var markedString string
-	record, isRecordFound := yamlconfig.FindFirstRecordThatMatchesString(records, s)
+	content := strings.TrimRight(s, "\r\n")
+	lineBreak := s[len(content):]
+
+	recordIndex, isRecordFound := yamlconfig.FindFirstRecordIndexThatMatchesString(records, s)
+	if !isRecordFound {
+		if exporter.masker != nil {
+			content = maskUnmatchedLine(content)
+		}
+		return "<span class='unmatched'>" + exporter.Escape(content) + lineBreak + "</span>"
+	}
+
+	record := records[recordIndex]
+	if exporter.masker != nil {
+		content = exporter.masker.MaskLine(record, content)
+	}
+	marker := newHTMLLineMarker(exporter, record, recordIndex, content)
 
-	if isRecordFound {
-		markedString += "<span>"
-		markedString += yamlconfig.ApplyMarkerToFieldsOnString(exporter, record.Fields, s)
-		markedString += "</span>"
-	} else {
-		markedString += "<span>"
-		markedString += ""
-		markedString += s
-		markedString += "</span>"
+	var classes []string
+	style := ""
+	if record.Color != "" {
+		classes = append(classes, "colored")
+		style = fmt.Sprintf(" style='border-left-color: %v'", exporter.Escape(string(record.Color)))
+	}
+	if marker.coverage.MissingCharacters > 0 {
+		classes = append(classes, "short")
+	}
+
+	markedString := "<span"
+	if len(classes) > 0 {
+		markedString += fmt.Sprintf(" class='%v'", strings.Join(classes, " "))
+	}
+	markedString += fmt.Sprintf(" data-record='%v'", recordIndex) + style + ">"
+	markedString += yamlconfig.ApplyMarkerToFieldsOnString(marker, marker.rangesToMark(), content)
+	if marker.coverage.MissingCharacters > 0 {
+		markedString += fmt.Sprintf("<span class='missing' data-missing='%v'></span>", marker.coverage.MissingCharacters)
 	}
+	markedString += lineBreak + "</span>"
 
 	return markedString
 }
 
-// ExportVisualization will take a given string and will use it on a HTML template to make it better to visualize the end result on a browser
-func (exporter HTMLExporter) ExportVisualization(s string) string {
+// ExportVisualization will take a given visualization and will use it on a HTML template to make it better to visualize the end result on a browser.
+// The content must be the result of MarkRecordsOnString, in which the content of the lines was already escaped.
+// The summary is shown at the top, followed by a legend of the records, in which each field may be toggled on and off.
+// An error is returned if the template can not be parsed or executed, e.g. when a custom template references unknown data
+func (exporter HTMLExporter) ExportVisualization(visualization Visualization) (string, error) {
 	t, err := template.New("customTemplate").Parse(exporter.htmlTemplate)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	var buf bytes.Buffer
-	err = t.Execute(&buf, s)
+	err = t.Execute(&buf, htmlTemplateData{
+		Header:         visualization.Header,
+		Theme:          exporter.theme,
+		Content:        template.HTML(visualization.Content),
+		Summary:        visualization.Summary,
+		Page:           visualization.Page,
+		Legend:         buildLegend(visualization.Records),
+		FieldColorsCSS: fieldColorsCSS(),
+	})
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
-	return buf.String()
+	return buf.String(), nil
 }
 
 // SaveToFile saves a given string to a given path
@@ -1,13 +1,25 @@
 package exporter
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pedroppinheiro/fwf/yamlconfig"
 )
 
 var exporter = HTMLExporter{
-	htmlTemplate: "<template>{{.}}</template>",
+	htmlTemplate: "<template>{{.Content}}</template>",
+}
+
+// exportVisualization returns the exported visualization, failing the test on errors
+func exportVisualization(t *testing.T, exporter Exporter, visualization Visualization) string {
+	t.Helper()
+	got, err := exporter.ExportVisualization(visualization)
+	if err != nil {
+		t.Fatalf("ExportVisualization() error = %v", err)
+	}
+	return got
 }
 
 func TestHTMLExporter_ExportVisualization(t *testing.T) {
@@ -31,7 +43,7 @@ func TestHTMLExporter_ExportVisualization(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.exporter.ExportVisualization(tt.args.s); got != tt.want {
+			if got := exportVisualization(t, tt.exporter, Visualization{Content: tt.args.s}); got != tt.want {
 				t.Errorf("HTMLExporter.ExportVisualization() = %v, want %v", got, tt.want)
 			}
 		})
@@ -78,19 +90,19 @@ func TestHTMLExporter_MarkRecordsOnString(t *testing.T) {
 			"Should correctly mark the fields of the first record",
 			GetHTMLExporter(),
 			args{differentRecords, "Athequickbrownfoxjumpsoverthelazydog"},
-			"<span><div class='tooltip'>A<span class='tooltiptext'>field 1</span></div>thequickbrownfoxjumpsoverthelazydog</span>",
+			"<span data-record='0'><div class='tooltip fieldcolor6 field-4b968514'>A<span class='tooltiptext'><b>field 1</b> (record A)\nPositions: 1-1 (length 1)\nValue: [A]</span></div><span class='overflow'>thequickbrownfoxjumpsoverthelazydog</span></span>",
 		},
 		{
 			"Should correctly mark the fields of the second record",
 			GetHTMLExporter(),
 			args{differentRecords, "Bthequickbrownfoxjumpsoverthelazydog"},
-			"<span><div class='tooltip'>B<span class='tooltiptext'></span></div>thequickbrownfoxjumpsoverthelazydog</span>",
+			"<span data-record='1'><div class='tooltip fieldcolor1 field-c802a036'>B<span class='tooltiptext'><b></b> (record B)\nPositions: 1-1 (length 1)\nValue: [B]</span></div><span class='overflow'>thequickbrownfoxjumpsoverthelazydog</span></span>",
 		},
 		{
 			"Should not mark due to not match any record",
 			GetHTMLExporter(),
 			args{differentRecords, "Cthequickbrownfoxjumpsoverthelazydog"},
-			"<span>Cthequickbrownfoxjumpsoverthelazydog</span>",
+			"<span class='unmatched'>Cthequickbrownfoxjumpsoverthelazydog</span>",
 		},
 	}
 	for _, tt := range tests {
@@ -101,3 +113,372 @@ func TestHTMLExporter_MarkRecordsOnString(t *testing.T) {
 		})
 	}
 }
+
+func TestHTMLExporter_MarkRecordsOnString_HostileInput(t *testing.T) {
+	var hostileRecords = []yamlconfig.Record{
+		{
+			Name:  "record A",
+			Regex: yamlconfig.MustCreateRegex("^A"),
+			Fields: []yamlconfig.Field{
+				{
+					Name:    "<img src=x onerror=alert(1)>",
+					Initial: 2,
+					End:     9,
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{
+			"Should escape the content of the fields and the field names",
+			"A<script>alert('x')</script>",
+			"<span data-record='0'><span class='gap'>A</span><div class='tooltip fieldcolor5 field-0be46a67'>&lt;script&gt;<span class='tooltiptext'><b>&lt;img src=x onerror=alert(1)&gt;</b> (record A)\nPositions: 2-9 (length 8)\nValue: [&lt;script&gt;]</span></div><span class='overflow'>alert(&#39;x&#39;)&lt;/script&gt;</span></span>",
+		},
+		{
+			"Should escape the content of lines that do not match any record",
+			"B<div onclick=\"alert(1)\">&amp;</div>",
+			"<span class='unmatched'>B&lt;div onclick=&#34;alert(1)&#34;&gt;&amp;amp;&lt;/div&gt;</span>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetHTMLExporter().MarkRecordsOnString(hostileRecords, tt.s); got != tt.want {
+				t.Errorf("HTMLExporter.MarkRecordsOnString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLExporter_ExportVisualization_HostileInput(t *testing.T) {
+	var hostileRecords = []yamlconfig.Record{
+		{
+			Name:  "</pre><script>alert(1)</script>",
+			Regex: yamlconfig.MustCreateRegex(".*"),
+			Fields: []yamlconfig.Field{
+				{
+					Name:    "</span></div><script>alert(1)</script>",
+					Initial: 1,
+					End:     8,
+				},
+			},
+		},
+	}
+
+	visualization, err := MarkRecordsOnReader(GetHTMLExporter(), hostileRecords, strings.NewReader("<script>alert(1)</script>\n</pre><b>bold</b>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := exportVisualization(t, GetHTMLExporter(), visualization)
+	for _, hostileContent := range []string{"<script>alert", "<b>bold", "</pre><", "</span></div><script"} {
+		if strings.Contains(got, hostileContent) {
+			t.Errorf("HTMLExporter.ExportVisualization() should not contain %v, got %v", hostileContent, got)
+		}
+	}
+}
+
+func TestHTMLExporter_MarkRecordsOnString_TypedFields(t *testing.T) {
+	var typedRecords = []yamlconfig.Record{
+		{
+			Name:  "record A",
+			Regex: yamlconfig.MustCreateRegex("^A"),
+			Fields: []yamlconfig.Field{
+				{
+					Name:        "amount",
+					Description: "amount in cents",
+					Initial:     2,
+					End:         5,
+					Type:        yamlconfig.DecimalType,
+					Decimals:    2,
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{
+			"Should show the description and the decoded value",
+			"A1234",
+			"<span data-record='0'><span class='gap'>A</span><div class='tooltip fieldcolor9 field-972c2281'>1234<span class='tooltiptext'><b>amount</b> (record A)\namount in cents\nPositions: 2-5 (length 4)\nValue: [1234]\nValue as decimal: 12.34</span></div></span>",
+		},
+		{
+			"Should highlight the field and show the error when the value is invalid",
+			"A12x4",
+			"<span data-record='0'><span class='gap'>A</span><div class='tooltip fieldcolor9 field-972c2281 invalid'>12x4<span class='tooltiptext'><b>amount</b> (record A)\namount in cents\nPositions: 2-5 (length 4)\nValue: [12x4]\n<span class='tooltiperror'>Error: field &#34;amount&#34;: &#34;12x4&#34; is not a valid decimal</span></span></div></span>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetHTMLExporter().MarkRecordsOnString(typedRecords, tt.s); got != tt.want {
+				t.Errorf("HTMLExporter.MarkRecordsOnString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLExporter_MarkRecordsOnString_KnownValues(t *testing.T) {
+	var codeRecords = []yamlconfig.Record{
+		{
+			Name:   "record A",
+			Regex:  yamlconfig.MustCreateRegex("^A"),
+			Fields: []yamlconfig.Field{{Name: "code", Initial: 2, End: 3, Values: &yamlconfig.Values{Descriptions: map[string]string{"01": "entry & exit"}}}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{
+			"Should show the meaning of a known value",
+			"A01",
+			"<span data-record='0'><span class='gap'>A</span><div class='tooltip fieldcolor0 field-1ed1cccc'>01<span class='tooltiptext'><b>code</b> (record A)\nPositions: 2-3 (length 2)\nValue: [01]\nMeaning: entry &amp; exit</span></div></span>",
+		},
+		{
+			"Should highlight the field and show the error when the value is unknown",
+			"A09",
+			"<span data-record='0'><span class='gap'>A</span><div class='tooltip fieldcolor0 field-1ed1cccc invalid'>09<span class='tooltiptext'><b>code</b> (record A)\nPositions: 2-3 (length 2)\nValue: [09]\n<span class='tooltiperror'>Error: field &#34;code&#34;: &#34;09&#34; is not one of its known values</span></span></div></span>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetHTMLExporter().MarkRecordsOnString(codeRecords, tt.s); got != tt.want {
+				t.Errorf("HTMLExporter.MarkRecordsOnString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLExporter_MarkRecordsOnString_CheckDigits(t *testing.T) {
+	var accountRecords = []yamlconfig.Record{
+		{
+			Name:  "record A",
+			Regex: yamlconfig.MustCreateRegex("^A"),
+			Fields: []yamlconfig.Field{
+				{Name: "account", Initial: 2, End: 5},
+				{Name: "digit", Initial: 6, End: 6, CheckDigit: &yamlconfig.CheckDigit{Algorithm: yamlconfig.LuhnCheckDigit, Of: []string{"account"}}},
+			},
+		},
+	}
+
+	got := GetHTMLExporter().MarkRecordsOnString(accountRecords, "A12340")
+	want := "<span data-record='0'><span class='gap'>A</span><div class='tooltip fieldcolor6 field-bafb9894'>1234<span class='tooltiptext'><b>account</b> (record A)\nPositions: 2-5 (length 4)\nValue: [1234]</span></div>" +
+		"<div class='tooltip fieldcolor2 field-d4a200ee invalid'>0<span class='tooltiptext'><b>digit</b> (record A)\nPositions: 6-6 (length 1)\nValue: [0]\n<span class='tooltiperror'>Error: field &#34;digit&#34;: &#34;0&#34; is not the luhn check digit of account</span></span></div></span>"
+	if got != want {
+		t.Errorf("HTMLExporter.MarkRecordsOnString() = %v, want %v", got, want)
+	}
+}
+
+func TestHTMLExporter_MarkRecordsOnString_Coverage(t *testing.T) {
+	var coverageRecords = []yamlconfig.Record{
+		{
+			Name:  "record A",
+			Regex: yamlconfig.MustCreateRegex("^A"),
+			Fields: []yamlconfig.Field{
+				{Name: "type", Initial: 1, End: 1},
+				{Name: "code", Initial: 4, End: 5},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{
+			"Should mark the gaps between fields and the characters after the last field",
+			"Abc12xy\n",
+			"<span data-record='0'><div class='tooltip fieldcolor3 field-139fe8c5'>A<span class='tooltiptext'><b>type</b> (record A)\nPositions: 1-1 (length 1)\nValue: [A]</span></div><span class='gap'>bc</span><div class='tooltip fieldcolor0 field-1ed1cccc'>12<span class='tooltiptext'><b>code</b> (record A)\nPositions: 4-5 (length 2)\nValue: [12]</span></div><span class='overflow'>xy</span>\n</span>",
+		},
+		{
+			"Should mark how many characters a short line lacks",
+			"Abc1\n",
+			"<span class='short' data-record='0'><div class='tooltip fieldcolor3 field-139fe8c5'>A<span class='tooltiptext'><b>type</b> (record A)\nPositions: 1-1 (length 1)\nValue: [A]</span></div><span class='gap'>bc</span><div class='tooltip fieldcolor0 field-1ed1cccc'>1<span class='tooltiptext'><b>code</b> (record A)\nPositions: 4-5 (length 2)\nValue: [1]</span></div><span class='missing' data-missing='1'></span>\n</span>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetHTMLExporter().MarkRecordsOnString(coverageRecords, tt.s); got != tt.want {
+				t.Errorf("HTMLExporter.MarkRecordsOnString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// testMasker masks the whole line with "#", except for its first character
+type testMasker struct{}
+
+func (testMasker) MaskLine(record yamlconfig.Record, line string) string {
+	return line[:1] + strings.Repeat("#", len(line)-1)
+}
+
+func TestHTMLExporter_MarkRecordsOnString_Masked(t *testing.T) {
+	var maskedRecords = []yamlconfig.Record{
+		{
+			Name:   "record A",
+			Regex:  yamlconfig.MustCreateRegex("^A"),
+			Fields: []yamlconfig.Field{{Name: "type", Initial: 1, End: 1}, {Name: "name", Initial: 2, End: 4, Sensitive: true}},
+		},
+	}
+
+	want := "<span data-record='0'><div class='tooltip fieldcolor3 field-139fe8c5'>A<span class='tooltiptext'><b>type</b> (record A)\nPositions: 1-1 (length 1)\nValue: [A]</span></div><div class='tooltip fieldcolor2 field-b2f094ce'>###<span class='tooltiptext'><b>name</b> (record A)\nPositions: 2-4 (length 3)\nValue: [###]</span></div>\n</span>"
+	if got := GetHTMLExporter().WithMasker(testMasker{}).MarkRecordsOnString(maskedRecords, "Abob\n"); got != want {
+		t.Errorf("HTMLExporter.MarkRecordsOnString() = %v, want %v", got, want)
+	}
+	if got := GetHTMLExporter().MarkRecordsOnString(maskedRecords, "Abob\n"); !strings.Contains(got, "bob") {
+		t.Errorf("HTMLExporter.MarkRecordsOnString() = %v, which should not be masked without a masker", got)
+	}
+
+	want = "<span class='unmatched'>***** ***\r\n</span>"
+	if got := GetHTMLExporter().WithMasker(testMasker{}).MarkRecordsOnString(maskedRecords, "Xjohn doe\r\n"); got != want {
+		t.Errorf("HTMLExporter.MarkRecordsOnString() = %v, want the line without record masked %v", got, want)
+	}
+}
+
+func TestHTMLExporter_ExportVisualization_Summary(t *testing.T) {
+	got := exportVisualization(t, GetHTMLExporter(), Visualization{
+		Summary: Summary{Lines: 7, UnmatchedLines: 2, LinesWithGaps: 3, GapCharacters: 4, LinesWithOverflow: 1, OverflowCharacters: 5, ShortLines: 1, MissingCharacters: 6},
+	})
+	for _, content := range []string{"7 lines", "2 without record", "3 with unmapped gaps (4 characters)", "1 with characters after the last field (5 characters)", "1 shorter than their record (6 missing characters)"} {
+		if !strings.Contains(got, content) {
+			t.Errorf("HTMLExporter.ExportVisualization() = %v, want it to contain %v", got, content)
+		}
+	}
+}
+
+func TestGetCustomHTMLExporter(t *testing.T) {
+	visualization := Visualization{
+		Content: "<span>A</span>",
+		Header:  Header{FileName: "file.txt", LayoutVersion: "2.1"},
+	}
+
+	tests := []struct {
+		name           string
+		customTemplate string
+		theme          Theme
+		wantContent    []string
+		wantErr        bool
+	}{
+		{
+			"Should use the built-in template when no template is given",
+			"",
+			LightTheme,
+			[]string{"<body class='light'>", "<h2>file.txt</h2>", "Layout version: 2.1", "<pre><span>A</span></pre>"},
+			false,
+		},
+		{
+			"Should use the dark theme",
+			"",
+			DarkTheme,
+			[]string{"<body class='dark'>"},
+			false,
+		},
+		{
+			"Should use a custom template",
+			"<h1>ACME</h1>{{.Header.FileName}} {{.Header.LayoutVersion}} {{.Theme}} {{.Summary.Lines}}<pre>{{.Content}}</pre>",
+			DarkTheme,
+			[]string{"<h1>ACME</h1>file.txt 2.1 dark 0<pre><span>A</span></pre>"},
+			false,
+		},
+		{
+			"Should not accept an invalid template",
+			"{{.Content",
+			LightTheme,
+			nil,
+			true,
+		},
+		{
+			"Should not accept a template that references unknown data",
+			"<pre>{{.Content}}</pre>{{.Nope}}",
+			LightTheme,
+			nil,
+			true,
+		},
+		{
+			"Should not accept a template that fails on a page",
+			"{{if .Page}}{{.Page.Nope}}{{end}}",
+			LightTheme,
+			nil,
+			true,
+		},
+		{
+			"Should not accept an unknown theme",
+			"",
+			Theme("blue"),
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			htmlExporter, err := GetCustomHTMLExporter(tt.customTemplate, tt.theme)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetCustomHTMLExporter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			got := exportVisualization(t, htmlExporter, visualization)
+			for _, content := range tt.wantContent {
+				if !strings.Contains(got, content) {
+					t.Errorf("HTMLExporter.ExportVisualization() = %v, want it to contain %v", got, content)
+				}
+			}
+		})
+	}
+}
+
+func TestHTMLExporter_ExportVisualization_Header(t *testing.T) {
+	tests := []struct {
+		name           string
+		header         Header
+		wantContent    []string
+		notWantContent []string
+	}{
+		{
+			"Should show the file name, the layout version and when the report was generated",
+			Header{FileName: "daily.txt", LayoutVersion: "3", GeneratedAt: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)},
+			[]string{"<h2>daily.txt</h2>", "Layout version: 3", "Generated at: 2021-03-04 05:06:07 UTC"},
+			[]string{},
+		},
+		{
+			"Should show that the layout version was not informed",
+			Header{FileName: "daily.txt"},
+			[]string{"Layout version: not informed"},
+			[]string{"Generated at"},
+		},
+		{
+			"Should not show the header without a file name",
+			Header{},
+			[]string{},
+			[]string{"class='reportheader'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := exportVisualization(t, GetHTMLExporter(), Visualization{Header: tt.header})
+			for _, content := range tt.wantContent {
+				if !strings.Contains(got, content) {
+					t.Errorf("HTMLExporter.ExportVisualization() = %v, want it to contain %v", got, content)
+				}
+			}
+			for _, content := range tt.notWantContent {
+				if strings.Contains(got, content) {
+					t.Errorf("HTMLExporter.ExportVisualization() = %v, want it not to contain %v", got, content)
+				}
+			}
+		})
+	}
+}
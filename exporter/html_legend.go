@@ -0,0 +1,151 @@
+package exporter
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html/template"
+	"sort"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// fieldColors are the colors used to highlight the fields of a record on the html, see fieldColorIndexes.
+// Red is not used since it highlights invalid values
+var fieldColors = []struct{ red, green, blue int }{
+	{46, 125, 50},  // green
+	{21, 101, 192}, // blue
+	{239, 108, 0},  // orange
+	{106, 27, 154}, // purple
+	{0, 131, 143},  // teal
+	{173, 20, 87},  // pink
+	{158, 157, 36}, // olive
+	{78, 52, 46},   // brown
+	{40, 53, 147},  // indigo
+	{96, 125, 139}, // gray
+}
+
+// htmlTemplateData is the data given to the html template, including custom templates, so its fields are documented on the README
+type htmlTemplateData struct {
+	// Header identifies the file, the version of the layout and when the report was generated
+	Header Header
+
+	// Theme is the name of the theme, which is used as the class of the body
+	Theme Theme
+
+	// Content holds the marked lines
+	Content template.HTML
+
+	// Summary holds the counters of the lines
+	Summary Summary
+
+	// Page describes which lines of the file are shown, or nil if the whole file is shown
+	Page *Page
+
+	// Legend describes the records and their fields
+	Legend []legendRecord
+
+	// FieldColorsCSS holds the classes that color the fields
+	FieldColorsCSS template.CSS
+}
+
+// legendRecord describes a record on the legend of the html
+type legendRecord struct {
+	Name   string
+	Color  string
+	Fields []legendField
+}
+
+// legendField describes a field on the legend of the html
+type legendField struct {
+	ID          string
+	ColorClass  string
+	Name        string
+	Initial     int
+	End         int
+	Length      int
+	Type        string
+	Description string
+}
+
+// fieldColorClass returns the css class of the color with the given index
+func fieldColorClass(index int) string {
+	return fmt.Sprintf("fieldcolor%v", index%len(fieldColors))
+}
+
+// nameHash returns the hash of the given names, which do not change when other fields or records are added
+func nameHash(names ...string) uint32 {
+	hash := fnv.New32a()
+	hash.Write([]byte(strings.Join(names, "\x00")))
+	return hash.Sum32()
+}
+
+// fieldColorIndexes returns the index of the color of each one of the given fields, sorted by their positions. The color
+// of a field comes from its name, so that it keeps its color when other fields are added, unless it has the color of the
+// field before it, in which case the next color is used, so that adjacent fields are always told apart
+func fieldColorIndexes(fields []yamlconfig.Field) []int {
+	colorIndexes := make([]int, len(fields))
+	for i, field := range fields {
+		colorIndexes[i] = int(nameHash(field.Name) % uint32(len(fieldColors)))
+		if i > 0 && colorIndexes[i] == colorIndexes[i-1] {
+			colorIndexes[i] = (colorIndexes[i] + 1) % len(fieldColors)
+		}
+	}
+	return colorIndexes
+}
+
+// fieldID returns the identifier of a field of a record on the html, which is used to toggle it on and off. It comes
+// from the names of the record and of the field, so that it does not change when other fields or records are added
+func fieldID(record yamlconfig.Record, field yamlconfig.Field) string {
+	return fmt.Sprintf("field-%08x", nameHash(record.Name, field.Name))
+}
+
+// fieldColorsCSS returns the css classes of each one of the field colors
+func fieldColorsCSS() template.CSS {
+	var css strings.Builder
+	for i, color := range fieldColors {
+		fmt.Fprintf(&css, ".%v { box-shadow: 0 0 4px rgb(%v,%v,%v); background-color: rgba(%v,%v,%v,0.15); }\n",
+			fieldColorClass(i), color.red, color.green, color.blue, color.red, color.green, color.blue)
+		fmt.Fprintf(&css, ".legend .%v { background-color: rgb(%v,%v,%v); }\n",
+			fieldColorClass(i), color.red, color.green, color.blue)
+	}
+	return template.CSS(css.String())
+}
+
+// sortedFields returns a copy of the fields of a record sorted by their initial position
+func sortedFields(record yamlconfig.Record) []yamlconfig.Field {
+	fields := make([]yamlconfig.Field, len(record.Fields))
+	copy(fields, record.Fields)
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Initial < fields[j].Initial
+	})
+	return fields
+}
+
+// buildLegend returns the legend of the given records
+func buildLegend(records []yamlconfig.Record) []legendRecord {
+	var legend []legendRecord
+	for _, record := range records {
+		legendRecord := legendRecord{Name: record.Name, Color: string(record.Color)}
+		fields := sortedFields(record)
+		colorIndexes := fieldColorIndexes(fields)
+		for fieldIndex, field := range fields {
+			fieldType := string(field.Type)
+			if fieldType == "" {
+				fieldType = string(yamlconfig.StringType)
+			}
+			legendRecord.Fields = append(legendRecord.Fields, legendField{
+				ID:          fieldID(record, field),
+				ColorClass:  fieldColorClass(colorIndexes[fieldIndex]),
+				Name:        field.Name,
+				Initial:     field.Initial,
+				End:         field.End,
+				Length:      field.Length(),
+				Type:        fieldType,
+				Description: field.Description,
+			})
+		}
+		legend = append(legend, legendRecord)
+	}
+	return legend
+}
@@ -0,0 +1,121 @@
+package exporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+var legendRecords = []yamlconfig.Record{
+	{
+		Name:  "record A",
+		Regex: yamlconfig.MustCreateRegex("^A"),
+		Color: "#1565c0",
+		Fields: []yamlconfig.Field{
+			{Name: "field 2", Initial: 2, End: 3, Type: yamlconfig.IntegerType},
+			{Name: "field 1", Initial: 1, End: 1, Description: "the record type"},
+		},
+	},
+}
+
+func Test_buildLegend_StableFields(t *testing.T) {
+	record := yamlconfig.Record{Name: "record A", Fields: []yamlconfig.Field{
+		{Name: "amount", Initial: 5, End: 9},
+		{Name: "date", Initial: 10, End: 17},
+	}}
+	withFieldAtTheStart := record
+	withFieldAtTheStart.Fields = append([]yamlconfig.Field{{Name: "type", Initial: 1, End: 1}}, record.Fields...)
+
+	got := buildLegend([]yamlconfig.Record{record})[0].Fields
+	gotWithFieldAtTheStart := buildLegend([]yamlconfig.Record{withFieldAtTheStart})[0].Fields
+	for i, field := range got {
+		if other := gotWithFieldAtTheStart[i+1]; other.ID != field.ID {
+			t.Errorf("buildLegend() ID of %q = %v after a field is added at the start, want %v", field.Name, other.ID, field.ID)
+		}
+	}
+	if got[1].ColorClass != gotWithFieldAtTheStart[2].ColorClass {
+		t.Errorf("buildLegend() color of %q = %v after a field is added at the start, want %v", got[1].Name, gotWithFieldAtTheStart[2].ColorClass, got[1].ColorClass)
+	}
+
+	for i := 1; i < len(gotWithFieldAtTheStart); i++ {
+		if gotWithFieldAtTheStart[i].ColorClass == gotWithFieldAtTheStart[i-1].ColorClass {
+			t.Errorf("buildLegend() should give adjacent fields different colors, got %v", gotWithFieldAtTheStart)
+		}
+	}
+}
+
+func Test_buildLegend(t *testing.T) {
+	want := []legendRecord{
+		{
+			Name:  "record A",
+			Color: "#1565c0",
+			Fields: []legendField{
+				{ID: "field-4b968514", ColorClass: "fieldcolor6", Name: "field 1", Initial: 1, End: 1, Length: 1, Type: "string", Description: "the record type"},
+				{ID: "field-4e9689cd", ColorClass: "fieldcolor3", Name: "field 2", Initial: 2, End: 3, Length: 2, Type: "integer"},
+			},
+		},
+	}
+
+	if got := buildLegend(legendRecords); !reflect.DeepEqual(got, want) {
+		t.Errorf("buildLegend() = %v, want %v", got, want)
+	}
+}
+
+func TestHTMLExporter_MarkRecordsOnString_Colors(t *testing.T) {
+	got := GetHTMLExporter().MarkRecordsOnString(legendRecords, "A12")
+
+	for _, content := range []string{
+		"<span class='colored' data-record='0' style='border-left-color: #1565c0'>",
+		"<div class='tooltip fieldcolor6 field-4b968514'>A",
+		"<div class='tooltip fieldcolor3 field-4e9689cd'>12",
+	} {
+		if !strings.Contains(got, content) {
+			t.Errorf("HTMLExporter.MarkRecordsOnString() = %v, want it to contain %v", got, content)
+		}
+	}
+}
+
+func TestHTMLExporter_ExportVisualization_Legend(t *testing.T) {
+	got := exportVisualization(t, GetHTMLExporter(), Visualization{Records: legendRecords})
+
+	for _, content := range []string{
+		"<summary>record A <span class='recordcolor' style='background-color: #1565c0'></span></summary>",
+		"<input type='checkbox' checked data-field='field-4e9689cd'><span class='swatch fieldcolor3'></span>",
+		"<td>the record type</td>",
+		".fieldcolor1 { box-shadow: 0 0 4px rgb(21,101,192);",
+	} {
+		if !strings.Contains(got, content) {
+			t.Errorf("HTMLExporter.ExportVisualization() = %v, want it to contain %v", got, content)
+		}
+	}
+
+	if got := exportVisualization(t, GetHTMLExporter(), Visualization{}); strings.Contains(got, "class='legend'") {
+		t.Errorf("HTMLExporter.ExportVisualization() should not have a legend without records, got %v", got)
+	}
+}
+
+func TestHTMLExporter_ExportVisualization_Search(t *testing.T) {
+	got := exportVisualization(t, GetHTMLExporter(), Visualization{Records: legendRecords})
+
+	for _, content := range []string{
+		"<input id='search'",
+		`var records = [{"Name":"record A"`,
+		`{"ID":"field-4e9689cd","ColorClass":"fieldcolor3","Name":"field 2"`,
+	} {
+		if !strings.Contains(got, content) {
+			t.Errorf("HTMLExporter.ExportVisualization() = %v, want it to contain %v", got, content)
+		}
+	}
+}
+
+func TestHTMLExporter_ExportVisualization_Ruler(t *testing.T) {
+	got := exportVisualization(t, GetHTMLExporter(), Visualization{Records: legendRecords})
+
+	for _, content := range []string{"<div id='ruler'>", "<div id='columnguide'>", "<input id='column'"} {
+		if !strings.Contains(got, content) {
+			t.Errorf("HTMLExporter.ExportVisualization() = %v, want it to contain %v", got, content)
+		}
+	}
+}
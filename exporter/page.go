@@ -0,0 +1,158 @@
+package exporter
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// LineIndex holds the byte offset in which each page of a file starts, so that a page can be read
+// without reading all the lines before it
+type LineIndex struct {
+	LinesPerPage int
+	Lines        int
+
+	// PageOffsets holds the byte offset of the first line of each page
+	PageOffsets []int64
+}
+
+// Page describes the part of a file that is shown on a visualization
+type Page struct {
+	// Number is the number of the page, starting at 1
+	Number int
+	Pages  int
+
+	// FirstLine and LastLine are the numbers of the first and the last lines of the page, starting at 1
+	FirstLine  int
+	LastLine   int
+	TotalLines int
+
+	// TargetLine is the line that should be highlighted when the page is shown, or 0 if there is none
+	TargetLine int
+}
+
+// LineOffset returns how many lines of the file come before the page
+func (page Page) LineOffset() int {
+	return page.FirstLine - 1
+}
+
+// HasPrevious returns true if there is a page before the page
+func (page Page) HasPrevious() bool {
+	return page.Number > 1
+}
+
+// HasNext returns true if there is a page after the page
+func (page Page) HasNext() bool {
+	return page.Number < page.Pages
+}
+
+// Previous returns the number of the page before the page
+func (page Page) Previous() int {
+	return page.Number - 1
+}
+
+// Next returns the number of the page after the page
+func (page Page) Next() int {
+	return page.Number + 1
+}
+
+// BuildLineIndex reads all lines of a given reader and returns the index of its pages, each one with the given number of lines
+func BuildLineIndex(r io.Reader, linesPerPage int) (LineIndex, error) {
+	reader := bufio.NewReader(r)
+	index := LineIndex{LinesPerPage: linesPerPage, PageOffsets: []int64{0}}
+	var offset int64
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return LineIndex{}, err
+		}
+
+		if line != "" {
+			if index.Lines > 0 && index.Lines%linesPerPage == 0 {
+				index.PageOffsets = append(index.PageOffsets, offset)
+			}
+			index.Lines++
+			offset += int64(len(line))
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+	return index, nil
+}
+
+// Pages returns how many pages the indexed file has. A file without lines has a single empty page
+func (index LineIndex) Pages() int {
+	return len(index.PageOffsets)
+}
+
+// PageOfLine returns the number of the page that holds a given line, starting at 1.
+// Lines before the first one and after the last one are on the first and on the last page respectively
+func (index LineIndex) PageOfLine(line int) int {
+	if line < 1 {
+		return 1
+	}
+	page := (line-1)/index.LinesPerPage + 1
+	if page > index.Pages() {
+		return index.Pages()
+	}
+	return page
+}
+
+// MarkPageOnReader reads the lines of a given page of a reader and marks each one of them with the given exporter.
+// The page number is clamped to the pages of the index. The summary of the returned visualization counts only the lines of the page
+func MarkPageOnReader(exporter Exporter, records []yamlconfig.Record, r io.ReadSeeker, index LineIndex, pageNumber int) (Visualization, error) {
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+	if pageNumber > index.Pages() {
+		pageNumber = index.Pages()
+	}
+
+	if _, err := r.Seek(index.PageOffsets[pageNumber-1], io.SeekStart); err != nil {
+		return Visualization{}, err
+	}
+
+	visualization, err := markRecordsOnLines(exporter, records, r, index.LinesPerPage)
+	if err != nil {
+		return Visualization{}, err
+	}
+
+	firstLine := (pageNumber-1)*index.LinesPerPage + 1
+	visualization.Page = &Page{
+		Number:     pageNumber,
+		Pages:      index.Pages(),
+		FirstLine:  firstLine,
+		LastLine:   firstLine + visualization.Summary.Lines - 1,
+		TotalLines: index.Lines,
+	}
+	return visualization, nil
+}
+
+// markRecordsOnLines reads up to a given number of lines of a given reader, or all of them if the number is negative,
+// and marks each one of them with the given exporter
+func markRecordsOnLines(exporter Exporter, records []yamlconfig.Record, r io.Reader, maxLines int) (Visualization, error) {
+	reader := bufio.NewReader(r)
+	visualization := Visualization{Records: records}
+	var content strings.Builder
+	for maxLines < 0 || visualization.Summary.Lines < maxLines {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return Visualization{}, err
+		}
+
+		if line != "" {
+			content.WriteString(exporter.MarkRecordsOnString(records, line))
+			visualization.Summary.addLine(records, line)
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+	visualization.Content = content.String()
+	return visualization, nil
+}
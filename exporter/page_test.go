@@ -0,0 +1,122 @@
+package exporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+func TestBuildLineIndex(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		linesPerPage int
+		want         LineIndex
+	}{
+		{
+			"Should index the offset of the first line of each page",
+			"A1\nA2\nA3\nA4\nA5",
+			2,
+			LineIndex{LinesPerPage: 2, Lines: 5, PageOffsets: []int64{0, 6, 12}},
+		},
+		{
+			"Should not create a page for the empty line after the last line break",
+			"A1\nA2\n",
+			2,
+			LineIndex{LinesPerPage: 2, Lines: 2, PageOffsets: []int64{0}},
+		},
+		{
+			"Should have a single page when there are no lines",
+			"",
+			2,
+			LineIndex{LinesPerPage: 2, Lines: 0, PageOffsets: []int64{0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildLineIndex(strings.NewReader(tt.content), tt.linesPerPage)
+			if err != nil {
+				t.Errorf("BuildLineIndex() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildLineIndex() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineIndex_PageOfLine(t *testing.T) {
+	index := LineIndex{LinesPerPage: 2, Lines: 5, PageOffsets: []int64{0, 6, 12}}
+	tests := []struct {
+		name string
+		line int
+		want int
+	}{
+		{"Should find the page of the first line", 1, 1},
+		{"Should find the page of the last line of a page", 4, 2},
+		{"Should find the page of the last line", 5, 3},
+		{"Should use the first page for lines before the first one", 0, 1},
+		{"Should use the last page for lines after the last one", 99, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := index.PageOfLine(tt.line); got != tt.want {
+				t.Errorf("LineIndex.PageOfLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkPageOnReader(t *testing.T) {
+	var records = []yamlconfig.Record{
+		{
+			Name:   "record A",
+			Regex:  yamlconfig.MustCreateRegex("^A"),
+			Fields: []yamlconfig.Field{{Name: "field 1", Initial: 1, End: 2}},
+		},
+	}
+	content := "A1\nA2\nA3\nA4\nA5"
+	index, err := BuildLineIndex(strings.NewReader(content), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		pageNumber  int
+		wantLines   []string
+		wantNoLines []string
+		wantPage    Page
+	}{
+		{"Should mark the lines of the first page", 1, []string{"[A1]", "[A2]"}, []string{"[A3]"}, Page{Number: 1, Pages: 3, FirstLine: 1, LastLine: 2, TotalLines: 5}},
+		{"Should mark the lines of a middle page", 2, []string{"[A3]", "[A4]"}, []string{"[A2]", "[A5]"}, Page{Number: 2, Pages: 3, FirstLine: 3, LastLine: 4, TotalLines: 5}},
+		{"Should mark the lines of an incomplete last page", 3, []string{"[A5]"}, []string{"[A4]"}, Page{Number: 3, Pages: 3, FirstLine: 5, LastLine: 5, TotalLines: 5}},
+		{"Should clamp pages after the last one", 7, []string{"[A5]"}, []string{"[A4]"}, Page{Number: 3, Pages: 3, FirstLine: 5, LastLine: 5, TotalLines: 5}},
+		{"Should clamp pages before the first one", 0, []string{"[A1]"}, []string{"[A3]"}, Page{Number: 1, Pages: 3, FirstLine: 1, LastLine: 2, TotalLines: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarkPageOnReader(GetHTMLExporter(), records, strings.NewReader(content), index, tt.pageNumber)
+			if err != nil {
+				t.Errorf("MarkPageOnReader() error = %v", err)
+				return
+			}
+			for _, line := range tt.wantLines {
+				if !strings.Contains(got.Content, line) {
+					t.Errorf("MarkPageOnReader() = %v, want it to contain %v", got.Content, line)
+				}
+			}
+			for _, line := range tt.wantNoLines {
+				if strings.Contains(got.Content, line) {
+					t.Errorf("MarkPageOnReader() = %v, want it not to contain %v", got.Content, line)
+				}
+			}
+			if got.Page == nil || *got.Page != tt.wantPage {
+				t.Errorf("MarkPageOnReader() page = %+v, want %+v", got.Page, tt.wantPage)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/transform"
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// runExtractCommand handles "fwf extract", which writes some fields of a file as a new fixed-width file
+func runExtractCommand(args []string) {
+	flags := flag.NewFlagSet("extract", flag.ExitOnError)
+	extractYAMLLocation := flags.String("yaml", "", "the full path for the yaml configuration")
+	extractFileLocation := flags.String("file", "", "the full path for the file whose fields should be extracted")
+	fields := flags.String("fields", "", "the comma separated list of the fields to be extracted, in the order they are written, e.g. \"id,amount\"")
+	outputLocation := flags.String("o", "", "the full path for the file to be created, the lines are written to the standard output if none is given")
+	layoutLocation := flags.String("layout", "", "the full path for the yaml configuration of the new file to be created, if any")
+	unmatchedOption := flags.String("unmatched", "error", "what to do with the lines that do not match any record or whose record does not have all the fields: \"file\" writes them to the file given with \"-o\" followed by \".unmatched\", \"skip\" ignores them and \"error\" stops on the first one")
+	flags.Parse(args)
+
+	if *extractYAMLLocation == "" {
+		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf extract -h\" for help")
+	}
+	if *extractFileLocation == "" {
+		panic("Please provide a valid file location with the flag \"-file\", use \"fwf extract -h\" for help")
+	}
+	if *fields == "" {
+		panic("Please provide the fields to be extracted with the flag \"-fields\", use \"fwf extract -h\" for help")
+	}
+	unmatched, err := transform.ParseUnmatched(*unmatchedOption)
+	if err != nil {
+		panic(err)
+	}
+	if unmatched == transform.WriteUnmatched && *outputLocation == "" {
+		panic("Please provide the file to be created with the flag \"-o\" to write the unmatched lines to a file, use \"fwf extract -h\" for help")
+	}
+
+	configuration := readConfigurationFromYAML(*extractYAMLLocation)
+	fieldNames := strings.Split(*fields, ",")
+	for i := range fieldNames {
+		fieldNames[i] = strings.TrimSpace(fieldNames[i])
+	}
+	extraction, err := transform.NewExtraction(configuration.Records, fieldNames)
+	if err != nil {
+		panic(err)
+	}
+
+	file := getFile(*extractFileLocation)
+	defer file.Close()
+
+	var output io.Writer = os.Stdout
+	if *outputLocation != "" {
+		outputFile, err := os.Create(*outputLocation)
+		if err != nil {
+			panic(err)
+		}
+		defer outputFile.Close()
+		output = outputFile
+	}
+	writer := bufio.NewWriter(output)
+
+	var unmatchedFile *os.File
+	var unmatchedWriter *bufio.Writer
+	openUnmatched := func() (io.Writer, error) {
+		var err error
+		if unmatchedFile, err = os.Create(*outputLocation + ".unmatched"); err != nil {
+			return nil, err
+		}
+		unmatchedWriter = bufio.NewWriter(unmatchedFile)
+		return unmatchedWriter, nil
+	}
+
+	result, err := extraction.Extract(configuration.Records, file, writer, openUnmatched, unmatched)
+	if unmatchedFile != nil {
+		if flushErr := unmatchedWriter.Flush(); flushErr != nil && err == nil {
+			err = flushErr
+		}
+		if closeErr := unmatchedFile.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	if flushErr := writer.Flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	if *layoutLocation != "" {
+		layout := yamlconfig.Configuration{Records: []yamlconfig.Record{extraction.Layout}}
+		layout, err := layout.RelocateValues(filepath.Dir(*extractYAMLLocation), filepath.Dir(*layoutLocation))
+		if err != nil {
+			panic(err)
+		}
+		yamlContent, err := yamlconfig.WriteConfiguration(layout)
+		if err != nil {
+			panic(err)
+		}
+		if err = ioutil.WriteFile(*layoutLocation, yamlContent, 0666); err != nil {
+			panic(err)
+		}
+		log.Printf("Configuration created successfully on %v\n", *layoutLocation)
+	}
+
+	if *outputLocation != "" {
+		log.Printf("%v lines extracted on %v\n", result.Lines, *outputLocation)
+	}
+	if result.UnmatchedLines > 0 {
+		if unmatched == transform.WriteUnmatched {
+			log.Printf("%v lines that could not be extracted written on %v\n", result.UnmatchedLines, *outputLocation+".unmatched")
+		} else {
+			log.Printf("%v lines that could not be extracted skipped\n", result.UnmatchedLines)
+		}
+	}
+}
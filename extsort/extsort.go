@@ -0,0 +1,300 @@
+package extsort
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+// Entry is a line to be sorted by its key. Entries with the same key are sorted by their number, which is usually
+// the number of the line on its file, so that the sort is stable
+type Entry struct {
+	Key    string
+	Number int
+	Line   string
+}
+
+// less returns true if the entry should come before a given entry
+func (entry Entry) less(other Entry) bool {
+	if entry.Key != other.Key {
+		return entry.Key < other.Key
+	}
+	return entry.Number < other.Number
+}
+
+// size returns an approximation of the memory used by the entry
+func (entry Entry) size() int {
+	return len(entry.Key) + len(entry.Line) + 64
+}
+
+// Sorter sorts entries that may not fit in memory. Entries are kept in memory until they reach the given size, then they
+// are sorted and written to a temporary file. The sorted files are merged when the entries are read
+type Sorter struct {
+	maxBytesInMemory int
+	bytesInMemory    int
+	entries          []Entry
+	chunkLocations   []string
+}
+
+// NewSorter returns a Sorter that keeps up to the given number of bytes of entries in memory
+func NewSorter(maxBytesInMemory int) *Sorter {
+	return &Sorter{maxBytesInMemory: maxBytesInMemory}
+}
+
+// Add adds an entry to be sorted
+func (sorter *Sorter) Add(entry Entry) error {
+	sorter.entries = append(sorter.entries, entry)
+	sorter.bytesInMemory += entry.size()
+	if sorter.bytesInMemory >= sorter.maxBytesInMemory {
+		return sorter.spill()
+	}
+	return nil
+}
+
+// spill sorts the entries in memory and writes them to a temporary file
+func (sorter *Sorter) spill() error {
+	sortEntries(sorter.entries)
+
+	chunk, err := ioutil.TempFile("", "fwf-sort-*")
+	if err != nil {
+		return err
+	}
+	sorter.chunkLocations = append(sorter.chunkLocations, chunk.Name())
+
+	writer := bufio.NewWriter(chunk)
+	for _, entry := range sorter.entries {
+		if err = writeEntry(writer, entry); err != nil {
+			chunk.Close()
+			return err
+		}
+	}
+	if err = writer.Flush(); err != nil {
+		chunk.Close()
+		return err
+	}
+	if err = chunk.Close(); err != nil {
+		return err
+	}
+
+	sorter.entries = nil
+	sorter.bytesInMemory = 0
+	return nil
+}
+
+// Sort returns an Iterator over all the added entries, in order. No entries should be added after it is called.
+// The temporary files are handed over to the Iterator, which must be closed to remove them
+func (sorter *Sorter) Sort() (*Iterator, error) {
+	sortEntries(sorter.entries)
+
+	iterator := &Iterator{chunkLocations: sorter.chunkLocations}
+	sorter.chunkLocations = nil
+	if len(sorter.entries) > 0 {
+		iterator.sources = append(iterator.sources, &memorySource{entries: sorter.entries})
+	}
+	for _, location := range iterator.chunkLocations {
+		chunk, err := os.Open(location)
+		if err != nil {
+			iterator.Close()
+			return nil, err
+		}
+		iterator.chunks = append(iterator.chunks, chunk)
+		iterator.sources = append(iterator.sources, &chunkSource{reader: bufio.NewReader(chunk)})
+	}
+
+	for _, source := range iterator.sources {
+		if err := source.advance(); err != nil {
+			iterator.Close()
+			return nil, err
+		}
+		if source.hasEntry() {
+			heap.Push(&iterator.heap, source)
+		}
+	}
+	return iterator, nil
+}
+
+// Close removes the temporary files that were not handed over to an Iterator, e.g. when the entries could not be added
+// or sorted. It should be deferred right after the Sorter is created
+func (sorter *Sorter) Close() error {
+	var closeErr error
+	for _, location := range sorter.chunkLocations {
+		if err := os.Remove(location); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	sorter.chunkLocations = nil
+	return closeErr
+}
+
+// sortEntries sorts entries in memory
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].less(entries[j])
+	})
+}
+
+// Iterator reads sorted entries
+type Iterator struct {
+	sources        []source
+	heap           sourceHeap
+	chunks         []*os.File
+	chunkLocations []string
+	entry          Entry
+	err            error
+}
+
+// Next advances to the next entry, which is then available through Entry. It returns false when there are no more
+// entries or when an error occurs, which is then available through Err
+func (iterator *Iterator) Next() bool {
+	if iterator.err != nil || iterator.heap.Len() == 0 {
+		return false
+	}
+
+	source := iterator.heap[0]
+	iterator.entry = source.current()
+	if iterator.err = source.advance(); iterator.err != nil {
+		return false
+	}
+	if source.hasEntry() {
+		heap.Fix(&iterator.heap, 0)
+	} else {
+		heap.Pop(&iterator.heap)
+	}
+	return true
+}
+
+// Entry returns the current entry
+func (iterator *Iterator) Entry() Entry {
+	return iterator.entry
+}
+
+// Err returns the error that stopped the iteration, if any
+func (iterator *Iterator) Err() error {
+	return iterator.err
+}
+
+// Close closes and removes the temporary files
+func (iterator *Iterator) Close() error {
+	var closeErr error
+	for _, chunk := range iterator.chunks {
+		if err := chunk.Close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	for _, location := range iterator.chunkLocations {
+		if err := os.Remove(location); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	return closeErr
+}
+
+// source is a sorted sequence of entries, either in memory or on a temporary file
+type source interface {
+	current() Entry
+	hasEntry() bool
+	advance() error
+}
+
+// memorySource is a sorted sequence of entries in memory
+type memorySource struct {
+	entries []Entry
+	index   int
+	started bool
+}
+
+func (source *memorySource) current() Entry {
+	return source.entries[source.index]
+}
+
+func (source *memorySource) hasEntry() bool {
+	return source.index < len(source.entries)
+}
+
+func (source *memorySource) advance() error {
+	if source.started {
+		source.index++
+	}
+	source.started = true
+	return nil
+}
+
+// chunkSource is a sorted sequence of entries on a temporary file
+type chunkSource struct {
+	reader *bufio.Reader
+	entry  Entry
+	isOver bool
+}
+
+func (source *chunkSource) current() Entry {
+	return source.entry
+}
+
+func (source *chunkSource) hasEntry() bool {
+	return !source.isOver
+}
+
+func (source *chunkSource) advance() error {
+	entry, err := readEntry(source.reader)
+	if err == io.EOF {
+		source.isOver = true
+		return nil
+	}
+	source.entry = entry
+	return err
+}
+
+// sourceHeap orders the sources by their current entries
+type sourceHeap []source
+
+func (h sourceHeap) Len() int            { return len(h) }
+func (h sourceHeap) Less(i, j int) bool  { return h[i].current().less(h[j].current()) }
+func (h sourceHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *sourceHeap) Push(x interface{}) { *h = append(*h, x.(source)) }
+func (h *sourceHeap) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// writeEntry writes an entry with the lengths of its key and line, so that both may have any character
+func writeEntry(writer *bufio.Writer, entry Entry) error {
+	var buffer [binary.MaxVarintLen64]byte
+	for _, value := range []int{len(entry.Key), entry.Number, len(entry.Line)} {
+		n := binary.PutUvarint(buffer[:], uint64(value))
+		if _, err := writer.Write(buffer[:n]); err != nil {
+			return err
+		}
+	}
+	if _, err := writer.WriteString(entry.Key); err != nil {
+		return err
+	}
+	_, err := writer.WriteString(entry.Line)
+	return err
+}
+
+// readEntry reads an entry written by writeEntry. It returns io.EOF when there are no more entries
+func readEntry(reader *bufio.Reader) (Entry, error) {
+	var values [3]uint64
+	for i := range values {
+		value, err := binary.ReadUvarint(reader)
+		if err != nil {
+			if err == io.EOF && i > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return Entry{}, err
+		}
+		values[i] = value
+	}
+
+	content := make([]byte, values[0]+values[2])
+	if _, err := io.ReadFull(reader, content); err != nil {
+		return Entry{}, err
+	}
+	return Entry{Key: string(content[:values[0]]), Number: int(values[1]), Line: string(content[values[0]:])}, nil
+}
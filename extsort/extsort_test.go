@@ -0,0 +1,105 @@
+package extsort
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSorter(t *testing.T) {
+	var entries []Entry
+	random := rand.New(rand.NewSource(1))
+	for i := 1; i <= 500; i++ {
+		entries = append(entries, Entry{Key: fmt.Sprintf("key %03d", random.Intn(100)), Number: i, Line: fmt.Sprintf("line\x00%v\n", i)})
+	}
+	want := make([]Entry, len(entries))
+	copy(want, entries)
+	sort.SliceStable(want, func(i, j int) bool {
+		return want[i].Key < want[j].Key
+	})
+
+	tests := []struct {
+		name             string
+		entries          []Entry
+		maxBytesInMemory int
+		want             []Entry
+		wantChunks       bool
+	}{
+		{"Should sort the entries in memory", entries, 1 << 20, want, false},
+		{"Should sort the entries on temporary files", entries, 2000, want, true},
+		{"Should sort a single entry per temporary file", entries[:10], 1, sortedCopy(entries[:10]), true},
+		{"Should not return entries when none was added", nil, 1000, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorter := NewSorter(tt.maxBytesInMemory)
+			for _, entry := range tt.entries {
+				if err := sorter.Add(entry); err != nil {
+					t.Fatal(err)
+				}
+			}
+			chunkLocations := sorter.chunkLocations
+			if hasChunks := len(chunkLocations) > 0; hasChunks != tt.wantChunks {
+				t.Errorf("Sorter.Add() created temporary files = %v, want %v", hasChunks, tt.wantChunks)
+			}
+
+			iterator, err := sorter.Sort()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []Entry
+			for iterator.Next() {
+				got = append(got, iterator.Entry())
+			}
+			if err = iterator.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if err = iterator.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sorter.Sort() = %v, want %v", got, tt.want)
+			}
+			for _, location := range chunkLocations {
+				if _, err := os.Stat(location); !os.IsNotExist(err) {
+					t.Errorf("Iterator.Close() should remove %v", location)
+				}
+			}
+			if err = sorter.Close(); err != nil {
+				t.Errorf("Sorter.Close() error = %v, want the files removed by the iterator to be ignored", err)
+			}
+		})
+	}
+}
+
+func TestSorter_Close(t *testing.T) {
+	sorter := NewSorter(1)
+	for i := 1; i <= 3; i++ {
+		if err := sorter.Add(Entry{Key: fmt.Sprint(i), Number: i, Line: "line"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chunkLocations := sorter.chunkLocations
+
+	if err := sorter.Close(); err != nil {
+		t.Fatalf("Sorter.Close() error = %v", err)
+	}
+	for _, location := range chunkLocations {
+		if _, err := os.Stat(location); !os.IsNotExist(err) {
+			t.Errorf("Sorter.Close() should remove %v, which was not sorted", location)
+		}
+	}
+}
+
+func sortedCopy(entries []Entry) []Entry {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+	return sorted
+}
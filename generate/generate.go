@@ -0,0 +1,501 @@
+package generate
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pedroppinheiro/fwf/transform"
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+const (
+	// maxAttempts is how many lines are generated for a record before giving up on finding one that is read as the record
+	maxAttempts = 100
+
+	// maxDigits is the maximum number of digits of the generated numbers, so that they fit on an int64
+	maxDigits = 18
+)
+
+var (
+	// firstDate and lastDate are the range of the generated dates
+	firstDate = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	lastDate  = time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC)
+
+	// totalRegex matches a total such as "count=details" or "total amount=sum(amount)"
+	totalRegex = regexp.MustCompile(`^([^=]+)=\s*(lines|details|sum\(([^)]+)\))$`)
+)
+
+// TotalKind is what a total of the trailer counts
+type TotalKind string
+
+const (
+	// LinesTotal is the number of lines of the file, including the header and the trailer
+	LinesTotal TotalKind = "lines"
+
+	// DetailsTotal is the number of details of the file
+	DetailsTotal TotalKind = "details"
+
+	// SumTotal is the sum of the values of a field of the details
+	SumTotal TotalKind = "sum"
+)
+
+// Total is a field of the trailer whose value is a total of the file
+type Total struct {
+	Field string
+	Kind  TotalKind
+
+	// SumField is the field of the details whose values are summed by a SumTotal
+	SumField string
+}
+
+// ParseTotals parses a comma separated list of totals, each one written as the name of a field of the trailer followed
+// by "=" and by "lines", "details" or "sum(field)", e.g. "count=details,total amount=sum(amount)"
+func ParseTotals(list string) ([]Total, error) {
+	var totals []Total
+	for _, part := range strings.Split(list, ",") {
+		match := totalRegex.FindStringSubmatch(strings.TrimSpace(part))
+		if match == nil {
+			return nil, fmt.Errorf("ParseTotals(): error - %q is not a total, it must be written as field=lines, field=details or field=sum(field)", part)
+		}
+
+		total := Total{Field: strings.TrimSpace(match[1]), Kind: TotalKind(match[2])}
+		if match[3] != "" {
+			total.Kind, total.SumField = SumTotal, strings.TrimSpace(match[3])
+		}
+		totals = append(totals, total)
+	}
+	return totals, nil
+}
+
+// Options tells the structure of the generated file
+type Options struct {
+	// Header and Trailer are the names of the records of the first and of the last lines, which are optional
+	Header  string
+	Trailer string
+
+	// Details are the names of the records of the lines between the header and the trailer, which are chosen at random.
+	// When empty, all the records except for the header and the trailer are used
+	Details []string
+
+	// Count is the number of details
+	Count int
+
+	// Totals are the fields of the trailer whose values are totals of the file
+	Totals []Total
+
+	// Seed is the seed of the random values, so that the same seed generates the same file
+	Seed int64
+
+	// LineBreak is written after each line
+	LineBreak string
+}
+
+// Result tells how many lines were generated
+type Result struct {
+	Lines int
+}
+
+// Generate writes a file with random values that are valid for the types of the fields, in which each line is read as the
+// record it was generated for: the characters required by the regex of the record are kept, and lines that would be read as
+// another record are generated again. The totals of the trailer are calculated from the details, which have their summed
+// fields limited so that the sums fit on the trailer
+func Generate(records []yamlconfig.Record, w io.Writer, options Options) (Result, error) {
+	header, details, trailer, err := newLineGenerators(records, options)
+	if err != nil {
+		return Result{}, err
+	}
+	if err = limitSummedFields(details, trailer, options); err != nil {
+		return Result{}, err
+	}
+
+	random := rand.New(rand.NewSource(options.Seed))
+	result := Result{}
+	writeLine := func(line string) error {
+		result.Lines++
+		_, err := io.WriteString(w, line+options.LineBreak)
+		return err
+	}
+
+	if header != nil {
+		line, err := header.line(random)
+		if err != nil {
+			return Result{}, err
+		}
+		if err = writeLine(line); err != nil {
+			return Result{}, err
+		}
+	}
+
+	sums := make([]int64, len(options.Totals))
+	for i := 0; i < options.Count; i++ {
+		detail := details[random.Intn(len(details))]
+		line, err := detail.line(random)
+		if err != nil {
+			return Result{}, err
+		}
+		if err = addToSums(sums, detail, trailer, line, options.Totals); err != nil {
+			return Result{}, err
+		}
+		if err = writeLine(line); err != nil {
+			return Result{}, err
+		}
+	}
+
+	if trailer != nil {
+		line, err := trailer.line(random)
+		if err != nil {
+			return Result{}, err
+		}
+		if line, err = trailer.setTotals(line, result.Lines+1, options, sums); err != nil {
+			return Result{}, err
+		}
+		if err = writeLine(line); err != nil {
+			return Result{}, err
+		}
+	}
+	return result, nil
+}
+
+// newLineGenerators returns the generators of the header, of the details and of the trailer. The header and the trailer
+// are nil when they are not given
+func newLineGenerators(records []yamlconfig.Record, options Options) (header *lineGenerator, details []*lineGenerator, trailer *lineGenerator, err error) {
+	if options.Count < 0 {
+		return nil, nil, nil, fmt.Errorf("Generate(): error - the number of details can not be negative")
+	}
+
+	detailNames := options.Details
+	if len(detailNames) == 0 {
+		for _, record := range records {
+			if record.Name != options.Header && record.Name != options.Trailer {
+				detailNames = append(detailNames, record.Name)
+			}
+		}
+	}
+	if len(detailNames) == 0 && options.Count > 0 {
+		return nil, nil, nil, fmt.Errorf("Generate(): error - there is no record for the details")
+	}
+
+	newGenerator := func(recordName string) (*lineGenerator, error) {
+		for _, record := range records {
+			if record.Name == recordName {
+				return newLineGenerator(records, record)
+			}
+		}
+		return nil, fmt.Errorf("Generate(): error - there is no record %q", recordName)
+	}
+
+	if options.Header != "" {
+		if header, err = newGenerator(options.Header); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	for _, detailName := range detailNames {
+		detail, err := newGenerator(detailName)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		details = append(details, detail)
+	}
+	if options.Trailer != "" {
+		if trailer, err = newGenerator(options.Trailer); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if trailer == nil && len(options.Totals) > 0 {
+		return nil, nil, nil, fmt.Errorf("Generate(): error - the totals are written on the trailer, but no trailer was given")
+	}
+	return header, details, trailer, nil
+}
+
+// limitSummedFields limits the values of the fields of the details that are summed by the totals, so that their sums fit on
+// the fields of the trailer. An error is returned if a total can not be written on the trailer
+func limitSummedFields(details []*lineGenerator, trailer *lineGenerator, options Options) error {
+	for _, total := range options.Totals {
+		totalField, isFound := trailer.record.FieldByName(total.Field)
+		if !isFound {
+			return fmt.Errorf("Generate(): error - the trailer %q does not have the field %q", trailer.record.Name, total.Field)
+		}
+		if totalField.Type == yamlconfig.DateType {
+			return fmt.Errorf("Generate(): error - the total %q can not be written on a date field", total.Field)
+		}
+		if total.Kind != SumTotal {
+			continue
+		}
+
+		isSummed := false
+		for _, detail := range details {
+			field, isFound := detail.record.FieldByName(total.SumField)
+			if !isFound {
+				continue
+			}
+			isSummed = true
+			if field.Type != yamlconfig.IntegerType && field.Type != yamlconfig.DecimalType {
+				return fmt.Errorf("Generate(): error - the field %q of the record %q is summed, but it is not a number", field.Name, detail.record.Name)
+			}
+			if field.Decimals > totalField.Decimals {
+				return fmt.Errorf("Generate(): error - the total %q has less decimal places than the field %q that it sums", total.Field, field.Name)
+			}
+
+			maxTotal := maxNumber(totalField.Length()) / int64(math.Pow10(totalField.Decimals-field.Decimals))
+			maxValue := maxTotal
+			if options.Count > 0 {
+				maxValue = maxTotal / int64(options.Count)
+			}
+			if maxValue < 1 {
+				return fmt.Errorf("Generate(): error - the sum of %v values of the field %q does not fit on the total %q", options.Count, field.Name, total.Field)
+			}
+			if currentMax, isLimited := detail.maxValues[field.Name]; !isLimited || maxValue < currentMax {
+				detail.maxValues[field.Name] = maxValue
+			}
+		}
+		if !isSummed {
+			return fmt.Errorf("Generate(): error - no detail has the field %q summed by the total %q", total.SumField, total.Field)
+		}
+	}
+	return nil
+}
+
+// addToSums adds the values of the summed fields of a detail to the sums of the totals, which are kept without the decimal
+// points of the fields of the trailer
+func addToSums(sums []int64, detail *lineGenerator, trailer *lineGenerator, line string, totals []Total) error {
+	for i, total := range totals {
+		field, isFound := detail.record.FieldByName(total.SumField)
+		if total.Kind != SumTotal || !isFound {
+			continue
+		}
+		value := strings.TrimSpace(field.RawValue(line))
+		if value == "" {
+			continue
+		}
+		units, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Generate(): error - the field %q of the record %q can not be summed: %v", field.Name, detail.record.Name, err)
+		}
+		totalField, _ := trailer.record.FieldByName(total.Field)
+		sums[i] += units * int64(math.Pow10(totalField.Decimals-field.Decimals))
+	}
+	return nil
+}
+
+// maxNumber returns the maximum number with the given number of digits, up to maxDigits
+func maxNumber(digits int) int64 {
+	if digits > maxDigits {
+		digits = maxDigits
+	}
+	return int64(math.Pow10(digits)) - 1
+}
+
+// lineGenerator generates the lines of a record
+type lineGenerator struct {
+	record  yamlconfig.Record
+	records []yamlconfig.Record
+
+	// sample holds the characters required by the regex of the record, see sampleRegex
+	sample []rune
+	width  int
+
+	// maxValues are the maximum values of the numeric fields, by their names, which are written without their decimal points
+	maxValues map[string]int64
+
+	// isCheckedByGroup tells, by their names, the fields whose digits are checked by another field
+	isCheckedByGroup map[string]bool
+}
+
+// newLineGenerator returns the generator of the lines of a record, which are checked against all the records
+func newLineGenerator(records []yamlconfig.Record, record yamlconfig.Record) (*lineGenerator, error) {
+	sample, err := sampleRegex(record.Regex.String())
+	if err != nil {
+		return nil, fmt.Errorf("Generate(): error - the lines of the record %q can not be generated: %v", record.Name, err)
+	}
+
+	width := len(sample)
+	isCheckedByGroup := map[string]bool{}
+	for _, field := range record.Fields {
+		if field.End > width {
+			width = field.End
+		}
+		if field.ChecksGroupDigits() {
+			for _, name := range field.CheckDigit.Of {
+				isCheckedByGroup[name] = true
+			}
+		}
+	}
+	return &lineGenerator{record: record, records: records, sample: sample, width: width, maxValues: map[string]int64{}, isCheckedByGroup: isCheckedByGroup}, nil
+}
+
+// line returns a random line of the record
+func (generator *lineGenerator) line(random *rand.Rand) (string, error) {
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		line := strings.Repeat(" ", generator.width)
+		for _, field := range generator.record.Fields {
+			content, err := field.EncodeValue(generator.randomValue(random, field))
+			if err != nil {
+				return "", fmt.Errorf("Generate(): error - the field %q of the record %q can not be generated: %v", field.Name, generator.record.Name, err)
+			}
+			line = field.ReplaceValue(line, content)
+		}
+		line, err := generator.setGroupCheckDigits(line)
+		if err != nil {
+			return "", err
+		}
+
+		runes := []rune(line)
+		for i, r := range generator.sample {
+			if r != anyCharacter {
+				runes[i] = r
+			}
+		}
+		line = string(runes)
+
+		if generator.isReadAsTheRecord(line) {
+			return line, nil
+		}
+	}
+	return "", fmt.Errorf("Generate(): error - no line generated for the record %q was read as it, check whether its regex matches the values of its fields or whether a record before it matches its lines", generator.record.Name)
+}
+
+// setGroupCheckDigits returns a line with the check digits of the fields that check other fields calculated from them
+func (generator *lineGenerator) setGroupCheckDigits(line string) (string, error) {
+	for _, field := range generator.record.Fields {
+		if !field.ChecksGroupDigits() {
+			continue
+		}
+		checked, err := field.CheckDigit.Algorithm.WithCheckDigits(generator.record.GroupValue(field, line))
+		if err != nil {
+			return "", fmt.Errorf("Generate(): error - the check digits of the field %q of the record %q can not be generated: %v", field.Name, generator.record.Name, err)
+		}
+		content, err := field.EncodeValue(checked[len(checked)-field.Length():])
+		if err != nil {
+			return "", fmt.Errorf("Generate(): error - the field %q of the record %q can not be generated: %v", field.Name, generator.record.Name, err)
+		}
+		line = field.ReplaceValue(line, content)
+	}
+	return line, nil
+}
+
+// isReadAsTheRecord returns true if a line is read as the record of the generator
+func (generator *lineGenerator) isReadAsTheRecord(line string) bool {
+	record, isRecordFound := yamlconfig.FindFirstRecordThatMatchesString(generator.records, line)
+	return isRecordFound && record.Name == generator.record.Name
+}
+
+// setTotals returns a line of the trailer with the totals of the file, which has the given number of lines
+func (generator *lineGenerator) setTotals(line string, lines int, options Options, sums []int64) (string, error) {
+	for i, total := range options.Totals {
+		field, _ := generator.record.FieldByName(total.Field)
+
+		var value string
+		switch total.Kind {
+		case LinesTotal:
+			value = strconv.Itoa(lines)
+		case DetailsTotal:
+			value = strconv.Itoa(options.Count)
+		case SumTotal:
+			value = strconv.FormatInt(sums[i], 10)
+			if field.Decimals > 0 {
+				value = fmt.Sprintf("%0*d", field.Decimals+1, sums[i])
+				value = value[:len(value)-field.Decimals] + "." + value[len(value)-field.Decimals:]
+			}
+		}
+
+		content, err := field.EncodeValue(value)
+		if err != nil {
+			return "", fmt.Errorf("Generate(): error - the total %q can not be written: %v", total.Field, err)
+		}
+		line = field.ReplaceValue(line, content)
+	}
+
+	if !generator.isReadAsTheRecord(line) {
+		return "", fmt.Errorf("Generate(): error - the trailer is not read as the record %q after its totals are written", generator.record.Name)
+	}
+	return line, nil
+}
+
+// randomValue returns a random value for a field, which is valid for its type and fits on it. Fields with known values get
+// one of them, and fields with check digits, or checked by other fields, get digits
+func (generator *lineGenerator) randomValue(random *rand.Rand, field yamlconfig.Field) string {
+	if field.HasValues() && len(field.Values.Descriptions) > 0 {
+		values := field.Values.SortedValues()
+		return values[random.Intn(len(values))]
+	}
+	if field.ChecksOwnDigits() {
+		return randomCheckedValue(random, field)
+	}
+	if field.ChecksGroupDigits() || generator.isCheckedByGroup[field.Name] {
+		return randomDigits(random, field.Length())
+	}
+	if field.Generator != "" {
+		hashed := make([]byte, 16)
+		random.Read(hashed)
+		fake := []rune(transform.FakeValue(field.Generator, "", hashed))
+		if len(fake) > field.Length() {
+			fake = fake[:field.Length()]
+		}
+		return string(fake)
+	}
+
+	switch field.Type {
+	case yamlconfig.IntegerType, yamlconfig.DecimalType:
+		maxValue, isLimited := generator.maxValues[field.Name]
+		if !isLimited || maxValue > maxNumber(field.Length()) {
+			maxValue = maxNumber(field.Length())
+		}
+		// the number of digits is chosen first, so that small numbers are as common as large ones
+		limit := int64(9)
+		for digits := random.Intn(len(strconv.FormatInt(maxValue, 10))); digits > 0 && limit < maxValue; digits-- {
+			limit = limit*10 + 9
+		}
+		if limit > maxValue {
+			limit = maxValue
+		}
+		return strconv.FormatInt(random.Int63n(limit+1), 10)
+
+	case yamlconfig.DateType:
+		seconds := random.Int63n(int64(lastDate.Sub(firstDate) / time.Second))
+		return field.FormatDate(firstDate.Add(time.Duration(seconds) * time.Second))
+	}
+
+	length := 1 + random.Intn(field.Length())
+	var value strings.Builder
+	for value.Len() < length {
+		if value.Len() > 0 {
+			value.WriteByte(' ')
+		}
+		for i := 2 + random.Intn(7); i > 0; i-- {
+			value.WriteByte(byte('A' + random.Intn(26)))
+		}
+	}
+	return strings.TrimSpace(value.String()[:length])
+}
+
+// randomCheckedValue returns a random value for a field that ends with its own check digits
+func randomCheckedValue(random *rand.Rand, field yamlconfig.Field) string {
+	algorithm := field.CheckDigit.Algorithm
+	value := randomDigits(random, field.Length())
+	if length := algorithm.ValueLength(); length > 0 {
+		value = randomDigits(random, length)
+	}
+	if algorithm == yamlconfig.IBANCheckDigit {
+		if len(value) > 34 {
+			value = value[:34]
+		}
+		value = "BR" + value[2:]
+	}
+	checked, _ := algorithm.WithCheckDigits(value)
+	return checked
+}
+
+// randomDigits returns the given number of random digits
+func randomDigits(random *rand.Rand, count int) string {
+	digits := make([]byte, count)
+	for i := range digits {
+		digits[i] = byte('0' + random.Intn(10))
+	}
+	return string(digits)
+}
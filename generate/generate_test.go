@@ -0,0 +1,181 @@
+package generate
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+var testRecords = []yamlconfig.Record{
+	{
+		Name:  "header",
+		Regex: yamlconfig.MustCreateRegex("^H"),
+		Fields: []yamlconfig.Field{
+			{Name: "type", Initial: 1, End: 1},
+			{Name: "date", Initial: 2, End: 11, Type: yamlconfig.DateType, Format: "DD/MM/YYYY"},
+		},
+	},
+	{
+		Name:  "detail",
+		Regex: yamlconfig.MustCreateRegex("^D"),
+		Fields: []yamlconfig.Field{
+			{Name: "type", Initial: 1, End: 1},
+			{Name: "id", Initial: 2, End: 4, Type: yamlconfig.IntegerType},
+			{Name: "name", Initial: 5, End: 14, Sensitive: true, Mask: yamlconfig.FakeMask, Generator: yamlconfig.NameGenerator},
+			{Name: "amount", Initial: 15, End: 20, Type: yamlconfig.DecimalType, Decimals: 2},
+			{Name: "kind", Initial: 21, End: 22, Values: &yamlconfig.Values{Descriptions: map[string]string{"01": "entry", "02": "cancellation"}}},
+			{Name: "cpf", Initial: 23, End: 33, CheckDigit: &yamlconfig.CheckDigit{Algorithm: yamlconfig.CPFCheckDigit}},
+			{Name: "agency", Initial: 34, End: 37},
+			{Name: "digit", Initial: 38, End: 38, Type: yamlconfig.IntegerType, CheckDigit: &yamlconfig.CheckDigit{Algorithm: yamlconfig.Mod11CheckDigit, Of: []string{"agency"}}},
+		},
+	},
+	{
+		Name:  "trailer",
+		Regex: yamlconfig.MustCreateRegex("^T"),
+		Fields: []yamlconfig.Field{
+			{Name: "type", Initial: 1, End: 1},
+			{Name: "lines", Initial: 2, End: 4, Type: yamlconfig.IntegerType},
+			{Name: "details", Initial: 5, End: 7, Type: yamlconfig.IntegerType},
+			{Name: "total", Initial: 8, End: 15, Type: yamlconfig.DecimalType, Decimals: 3},
+		},
+	},
+}
+
+var testOptions = Options{
+	Header:    "header",
+	Trailer:   "trailer",
+	Count:     200,
+	Totals:    []Total{{Field: "lines", Kind: LinesTotal}, {Field: "details", Kind: DetailsTotal}, {Field: "total", Kind: SumTotal, SumField: "amount"}},
+	Seed:      7,
+	LineBreak: "\r\n",
+}
+
+func TestParseTotals(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []Total
+		wantErr bool
+	}{
+		{
+			name: "Should parse the totals",
+			list: "lines=lines, count = details,total amount=sum(amount)",
+			want: []Total{{Field: "lines", Kind: LinesTotal}, {Field: "count", Kind: DetailsTotal}, {Field: "total amount", Kind: SumTotal, SumField: "amount"}},
+		},
+		{
+			name:    "Should return an error for unknown totals",
+			list:    "count=average(amount)",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTotals(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTotals() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTotals() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	output := &bytes.Buffer{}
+	result, err := Generate(testRecords, output, testOptions)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result.Lines != testOptions.Count+2 {
+		t.Errorf("Generate() = %v lines, want %v", result.Lines, testOptions.Count+2)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\r\n"), "\r\n")
+	if len(lines) != result.Lines {
+		t.Fatalf("Generate() wrote %v lines, want %v", len(lines), result.Lines)
+	}
+
+	var sum int64
+	for i, line := range lines {
+		record, isRecordFound := yamlconfig.FindFirstRecordThatMatchesString(testRecords, line)
+		wantRecord := "detail"
+		if i == 0 {
+			wantRecord = "header"
+		} else if i == len(lines)-1 {
+			wantRecord = "trailer"
+		}
+		if !isRecordFound || record.Name != wantRecord {
+			t.Fatalf("Generate() line %v = %q, want a line of the record %q", i+1, line, wantRecord)
+		}
+
+		for _, field := range record.Fields {
+			if _, err := record.ParseFieldValue(field, line); err != nil {
+				t.Errorf("Generate() line %v = %q, which has an invalid value: %v", i+1, line, err)
+			}
+		}
+		if record.Name == "detail" {
+			amount, _ := strconv.ParseInt(strings.TrimSpace(line[14:20]), 10, 64)
+			sum += amount * 10
+		}
+	}
+
+	trailer := lines[len(lines)-1]
+	if want := "T202200" + strconv.FormatInt(sum+100000000, 10)[1:]; trailer != want {
+		t.Errorf("Generate() trailer = %q, want %q", trailer, want)
+	}
+
+	again := &bytes.Buffer{}
+	Generate(testRecords, again, testOptions)
+	if again.String() != output.String() {
+		t.Errorf("Generate() should generate the same file with the same seed")
+	}
+}
+
+func TestGenerate_errors(t *testing.T) {
+	anyRecord := yamlconfig.Record{Name: "any", Regex: yamlconfig.MustCreateRegex(".*"), Fields: []yamlconfig.Field{{Name: "text", Initial: 1, End: 5}}}
+
+	tests := []struct {
+		name    string
+		records []yamlconfig.Record
+		options Options
+	}{
+		{
+			name:    "Should return an error for unknown records",
+			records: testRecords,
+			options: Options{Header: "first", Count: 1},
+		},
+		{
+			name:    "Should return an error for totals without trailer",
+			records: testRecords,
+			options: Options{Count: 1, Totals: []Total{{Field: "lines", Kind: LinesTotal}}},
+		},
+		{
+			name:    "Should return an error when the sum does not fit on the total",
+			records: testRecords,
+			options: Options{Trailer: "trailer", Details: []string{"detail"}, Count: 100000000, Totals: []Total{{Field: "total", Kind: SumTotal, SumField: "amount"}}},
+		},
+		{
+			name:    "Should return an error when the summed field is not on the details",
+			records: testRecords,
+			options: Options{Trailer: "trailer", Details: []string{"detail"}, Count: 1, Totals: []Total{{Field: "total", Kind: SumTotal, SumField: "price"}}},
+		},
+		{
+			name:    "Should return an error when the lines of a record are read as a record before it",
+			records: append([]yamlconfig.Record{anyRecord}, testRecords...),
+			options: Options{Details: []string{"detail"}, Count: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Generate(tt.records, &bytes.Buffer{}, tt.options); err == nil {
+				t.Errorf("Generate() error = nil, want an error")
+			}
+		})
+	}
+}
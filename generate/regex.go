@@ -0,0 +1,57 @@
+package generate
+
+import (
+	"fmt"
+	"regexp/syntax"
+)
+
+// anyCharacter marks the positions of a regex sample that may have any character
+const anyCharacter rune = -1
+
+// sampleRegex returns the characters that a line must start with to match a regex, in which the positions that may have any
+// character are anyCharacter. Repetitions are sampled with their minimum number of characters, alternations with their
+// first alternative and character classes with their first character. An error is returned if the regex has a construct
+// that can not be sampled
+func sampleRegex(regex string) ([]rune, error) {
+	parsed, err := syntax.Parse(regex, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+
+	var sample []rune
+	var appendSample func(parsed *syntax.Regexp) error
+	appendSample = func(parsed *syntax.Regexp) error {
+		switch parsed.Op {
+		case syntax.OpLiteral:
+			sample = append(sample, parsed.Rune...)
+		case syntax.OpCharClass:
+			if len(parsed.Rune) == 0 {
+				return fmt.Errorf("the character class of %q does not match any character", regex)
+			}
+			sample = append(sample, parsed.Rune[0])
+		case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+			sample = append(sample, anyCharacter)
+		case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+			syntax.OpWordBoundary, syntax.OpNoWordBoundary, syntax.OpStar, syntax.OpQuest:
+		case syntax.OpCapture, syntax.OpPlus, syntax.OpAlternate:
+			return appendSample(parsed.Sub[0])
+		case syntax.OpRepeat:
+			for i := 0; i < parsed.Min; i++ {
+				if err := appendSample(parsed.Sub[0]); err != nil {
+					return err
+				}
+			}
+		case syntax.OpConcat:
+			for _, sub := range parsed.Sub {
+				if err := appendSample(sub); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("%q can not be sampled", regex)
+		}
+		return nil
+	}
+
+	return sample, appendSample(parsed.Simplify())
+}
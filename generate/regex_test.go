@@ -0,0 +1,33 @@
+package generate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_sampleRegex(t *testing.T) {
+	tests := []struct {
+		name    string
+		regex   string
+		want    []rune
+		wantErr bool
+	}{
+		{"Should sample the literal at the start", "^H", []rune("H"), false},
+		{"Should sample any character with the minimum repetitions", "^.{3}D.*$", []rune{anyCharacter, anyCharacter, anyCharacter, 'D'}, false},
+		{"Should sample the first alternative and the first character of classes", "^(01|02)[A-C]", []rune("01A"), false},
+		{"Should sample nothing for a regex that matches any line", ".*", nil, false},
+		{"Should get error on regexes that can not be parsed", "^(H", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sampleRegex(tt.regex)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("sampleRegex() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sampleRegex() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
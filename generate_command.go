@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pedroppinheiro/fwf/generate"
+)
+
+// runGenerateCommand handles "fwf generate", which writes a file with random values from a layout
+func runGenerateCommand(args []string) {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	generateYAMLLocation := flags.String("yaml", "", "the full path for the yaml configuration")
+	count := flags.Int("count", 1000, "the number of details to be generated")
+	header := flags.String("header", "", "the record of the first line, if any")
+	trailer := flags.String("trailer", "", "the record of the last line, if any")
+	details := flags.String("details", "", "the comma separated list of the records of the details, which are chosen at random, all the records except for the header and the trailer are used if none is given")
+	totals := flags.String("totals", "", "the comma separated list of the fields of the trailer that hold totals of the file, e.g. \"count=details,total=sum(amount)\", in which \"lines\" is the number of lines, \"details\" is the number of details and \"sum(field)\" is the sum of a field of the details")
+	seed := flags.Int64("seed", 0, "the seed of the random values, so that the same seed generates the same file, a new seed is used if none is given")
+	isCRLF := flags.Bool("crlf", false, "ends the lines with \"\\r\\n\" instead of \"\\n\"")
+	outputLocation := flags.String("o", "", "the full path for the file to be created, the lines are written to the standard output if none is given")
+	flags.Parse(args)
+
+	if *generateYAMLLocation == "" {
+		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf generate -h\" for help")
+	}
+	if *count < 0 {
+		panic("Please provide a number of details that is not negative with the flag \"-count\", use \"fwf generate -h\" for help")
+	}
+
+	options := generate.Options{Header: *header, Trailer: *trailer, Count: *count, Seed: *seed, LineBreak: "\n"}
+	if *details != "" {
+		for _, detail := range strings.Split(*details, ",") {
+			options.Details = append(options.Details, strings.TrimSpace(detail))
+		}
+	}
+	if *totals != "" {
+		var err error
+		if options.Totals, err = generate.ParseTotals(*totals); err != nil {
+			panic(err)
+		}
+	}
+	if options.Seed == 0 {
+		options.Seed = time.Now().UnixNano()
+	}
+	if *isCRLF {
+		options.LineBreak = "\r\n"
+	}
+
+	configuration := readConfigurationFromYAML(*generateYAMLLocation)
+
+	var output io.Writer = os.Stdout
+	if *outputLocation != "" {
+		outputFile, err := os.Create(*outputLocation)
+		if err != nil {
+			panic(err)
+		}
+		defer outputFile.Close()
+		output = outputFile
+	}
+	writer := bufio.NewWriter(output)
+
+	result, err := generate.Generate(configuration.Records, writer, options)
+	if flushErr := writer.Flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	if *outputLocation != "" {
+		log.Printf("File created successfully on %v\n", *outputLocation)
+		log.Printf("%v lines generated with the seed %v\n", result.Lines, options.Seed)
+	}
+}
@@ -0,0 +1,225 @@
+package infer
+
+import (
+	"fmt"
+	"regexp"
+	"unicode"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+const (
+	// maxRecordGroups is the maximum number of distinct first characters in which the lines
+	// will still be considered as different records
+	maxRecordGroups = 10
+
+	// minLinesForConstantColumns is the minimum number of lines a group must have so that
+	// constant columns can be used to detect fields
+	minLinesForConstantColumns = 10
+)
+
+// characterClass represents the kind of a character found on a column
+type characterClass int
+
+const (
+	missingClass characterClass = iota
+	spaceClass
+	digitClass
+	letterClass
+	otherClass
+)
+
+// columnProfile holds what was found on a single column, across all lines of a group
+type columnProfile struct {
+	classes  map[characterClass]int
+	first    rune
+	constant bool
+	present  int
+}
+
+// isBlank returns true if every line that reaches the column has a space on it
+func (profile columnProfile) isBlank() bool {
+	return profile.present > 0 && profile.classes[spaceClass] == profile.present
+}
+
+// pureClass returns the class of the column if all lines that reach it share the same class,
+// and missingClass otherwise
+func (profile columnProfile) pureClass() characterClass {
+	for class, count := range profile.classes {
+		if count == profile.present {
+			return class
+		}
+	}
+	return missingClass
+}
+
+// lineGroup holds the lines that start with the same character
+type lineGroup struct {
+	firstCharacter rune
+	lines          [][]rune
+}
+
+// InferConfiguration analyzes a sample of lines of a fixed-width file and proposes a Configuration
+// for it. Lines are split in records by their first character, when there are few distinct ones,
+// and the fields of each record are detected by looking at the columns where
+// a whitespace run ends, the character class changes or a constant column starts or ends
+func InferConfiguration(lines []string) yamlconfig.Configuration {
+	groups := groupLinesByFirstCharacter(lines)
+	configuration := yamlconfig.Configuration{}
+
+	if len(groups) == 0 {
+		return configuration
+	}
+
+	if !shouldSplitIntoRecords(groups) {
+		var allLines [][]rune
+		for _, group := range groups {
+			allLines = append(allLines, group.lines...)
+		}
+		configuration.Records = append(configuration.Records, yamlconfig.Record{
+			Name:   "record",
+			Regex:  yamlconfig.MustCreateRegex(".*"),
+			Fields: inferFields(allLines),
+		})
+		return configuration
+	}
+
+	for _, group := range groups {
+		firstCharacter := string(group.firstCharacter)
+		configuration.Records = append(configuration.Records, yamlconfig.Record{
+			Name:   fmt.Sprintf("record %v", firstCharacter),
+			Regex:  yamlconfig.MustCreateRegex("^" + regexp.QuoteMeta(firstCharacter)),
+			Fields: inferFields(group.lines),
+		})
+	}
+
+	return configuration
+}
+
+// groupLinesByFirstCharacter groups the non empty lines by their first character,
+// keeping the order in which each character first appeared
+func groupLinesByFirstCharacter(lines []string) []lineGroup {
+	var groups []lineGroup
+	indexByCharacter := map[rune]int{}
+
+	for _, line := range lines {
+		runes := []rune(line)
+		if len(runes) == 0 {
+			continue
+		}
+
+		index, exists := indexByCharacter[runes[0]]
+		if !exists {
+			index = len(groups)
+			indexByCharacter[runes[0]] = index
+			groups = append(groups, lineGroup{firstCharacter: runes[0]})
+		}
+		groups[index].lines = append(groups[index].lines, runes)
+	}
+
+	return groups
+}
+
+// shouldSplitIntoRecords returns true if the first character seems to be a record type, which is
+// when there are only a few distinct first characters and they repeat along the sample
+func shouldSplitIntoRecords(groups []lineGroup) bool {
+	if len(groups) < 2 || len(groups) > maxRecordGroups {
+		return false
+	}
+
+	lineCount := 0
+	for _, group := range groups {
+		if unicode.IsSpace(group.firstCharacter) {
+			return false
+		}
+		lineCount += len(group.lines)
+	}
+
+	return len(groups)*2 <= lineCount
+}
+
+// classify returns the characterClass of a given rune
+func classify(r rune) characterClass {
+	switch {
+	case unicode.IsSpace(r):
+		return spaceClass
+	case unicode.IsDigit(r):
+		return digitClass
+	case unicode.IsLetter(r):
+		return letterClass
+	default:
+		return otherClass
+	}
+}
+
+// profileColumns builds the columnProfile of every column of the given lines
+func profileColumns(lines [][]rune) []columnProfile {
+	width := 0
+	for _, line := range lines {
+		if len(line) > width {
+			width = len(line)
+		}
+	}
+
+	profiles := make([]columnProfile, width)
+	for column := range profiles {
+		profile := columnProfile{classes: map[characterClass]int{}, constant: true}
+		for _, line := range lines {
+			if column >= len(line) {
+				continue
+			}
+			r := line[column]
+			if profile.present == 0 {
+				profile.first = r
+			} else if r != profile.first {
+				profile.constant = false
+			}
+			profile.present++
+			profile.classes[classify(r)]++
+		}
+		profiles[column] = profile
+	}
+
+	return profiles
+}
+
+// isFieldBoundary returns true if a new field seems to start on the column "current",
+// given the column right before it
+func isFieldBoundary(previous, current columnProfile, canUseConstantColumns bool) bool {
+	if previous.isBlank() && !current.isBlank() {
+		return true
+	}
+
+	if previous.isBlank() || current.isBlank() {
+		return false
+	}
+
+	previousClass, currentClass := previous.pureClass(), current.pureClass()
+	if previousClass != missingClass && currentClass != missingClass && previousClass != currentClass {
+		return true
+	}
+
+	return canUseConstantColumns && previous.constant != current.constant
+}
+
+// inferFields proposes the fields of a record based on the profile of its columns
+func inferFields(lines [][]rune) []yamlconfig.Field {
+	profiles := profileColumns(lines)
+	canUseConstantColumns := len(lines) >= minLinesForConstantColumns
+
+	var fields []yamlconfig.Field
+	initial := 1
+	for column := 1; column <= len(profiles); column++ {
+		isLastColumn := column == len(profiles)
+		if isLastColumn || isFieldBoundary(profiles[column-1], profiles[column], canUseConstantColumns) {
+			fields = append(fields, yamlconfig.Field{
+				Name:    fmt.Sprintf("field %v", len(fields)+1),
+				Initial: initial,
+				End:     column,
+			})
+			initial = column + 1
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,142 @@
+package infer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+func TestInferConfiguration(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  yamlconfig.Configuration
+	}{
+		{
+			"Empty sample should give an empty configuration",
+			[]string{},
+			yamlconfig.Configuration{},
+		},
+		{
+			"Should detect fields separated by whitespace runs and class transitions",
+			[]string{
+				"John Smith         40",
+				"Homer Simpson      30",
+				"Foo Bar            20",
+			},
+			yamlconfig.Configuration{
+				Records: []yamlconfig.Record{
+					{
+						Name:  "record",
+						Regex: yamlconfig.MustCreateRegex(".*"),
+						Fields: []yamlconfig.Field{
+							{Name: "field 1", Initial: 1, End: 19},
+							{Name: "field 2", Initial: 20, End: 21},
+						},
+					},
+				},
+			},
+		},
+		{
+			"Should split records by their first character",
+			[]string{
+				"H20200101",
+				"D0001Alice",
+				"D0002Bob  ",
+				"D0003Carol",
+				"T0003",
+				"H20200102",
+				"T0000",
+			},
+			yamlconfig.Configuration{
+				Records: []yamlconfig.Record{
+					{
+						Name:  "record H",
+						Regex: yamlconfig.MustCreateRegex("^H"),
+						Fields: []yamlconfig.Field{
+							{Name: "field 1", Initial: 1, End: 1},
+							{Name: "field 2", Initial: 2, End: 9},
+						},
+					},
+					{
+						Name:  "record D",
+						Regex: yamlconfig.MustCreateRegex("^D"),
+						Fields: []yamlconfig.Field{
+							{Name: "field 1", Initial: 1, End: 1},
+							{Name: "field 2", Initial: 2, End: 5},
+							{Name: "field 3", Initial: 6, End: 10},
+						},
+					},
+					{
+						Name:  "record T",
+						Regex: yamlconfig.MustCreateRegex("^T"),
+						Fields: []yamlconfig.Field{
+							{Name: "field 1", Initial: 1, End: 1},
+							{Name: "field 2", Initial: 2, End: 5},
+						},
+					},
+				},
+			},
+		},
+		{
+			"Should detect constant columns when the sample is big enough",
+			[]string{"BRLa", "BRLb", "BRLc", "BRLd", "BRLe", "BRLf", "BRLg", "BRLh", "BRLi", "BRLj"},
+			yamlconfig.Configuration{
+				Records: []yamlconfig.Record{
+					{
+						Name:  "record",
+						Regex: yamlconfig.MustCreateRegex(".*"),
+						Fields: []yamlconfig.Field{
+							{Name: "field 1", Initial: 1, End: 3},
+							{Name: "field 2", Initial: 4, End: 4},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InferConfiguration(tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InferConfiguration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_shouldSplitIntoRecords(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  bool
+	}{
+		{
+			"Should not split when every line starts with a different character",
+			[]string{"John", "Homer", "Foo"},
+			false,
+		},
+		{
+			"Should not split when every line starts with the same character",
+			[]string{"A1", "A2", "A3"},
+			false,
+		},
+		{
+			"Should split when few first characters repeat along the sample",
+			[]string{"A1", "B1", "A2", "B2"},
+			true,
+		},
+		{
+			"Should not split when lines start with spaces",
+			[]string{" 1", "B1", " 2", "B2"},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldSplitIntoRecords(groupLinesByFirstCharacter(tt.lines)); got != tt.want {
+				t.Errorf("shouldSplitIntoRecords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
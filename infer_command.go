@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/infer"
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// runInferCommand handles "fwf infer", which proposes a yaml configuration based on a sample of a file
+func runInferCommand(args []string) {
+	flags := flag.NewFlagSet("infer", flag.ExitOnError)
+	inferFileLocation := flags.String("file", "", "the full path for the file to be analyzed")
+	sampleSize := flags.Int("lines", 1000, "the number of lines, from the beginning of the file, to be analyzed")
+	outputLocation := flags.String("o", "", "the full path for the yaml file to be created. If empty the yaml is written to the standard output")
+	flags.Parse(args)
+
+	if *inferFileLocation == "" {
+		panic("Please provide a valid file location with the flag \"-file\", use \"fwf infer -h\" for help")
+	}
+
+	file := getFile(*inferFileLocation)
+	defer file.Close()
+
+	lines, err := readSample(file, *sampleSize)
+	if err != nil {
+		panic(err)
+	}
+
+	yamlContent, err := yamlconfig.WriteConfiguration(infer.InferConfiguration(lines))
+	if err != nil {
+		panic(err)
+	}
+
+	if *outputLocation == "" {
+		os.Stdout.Write(yamlContent)
+		return
+	}
+
+	if err = ioutil.WriteFile(*outputLocation, yamlContent, 0666); err != nil {
+		panic(err)
+	}
+	log.Printf("Configuration created successfully on %v\n", *outputLocation)
+}
+
+// readSample reads up to maxLines lines of a given file, without their line endings
+func readSample(file *os.File, maxLines int) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for len(lines) < maxLines && scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	return lines, scanner.Err()
+}
@@ -3,14 +3,17 @@ package main
 import (
 	"bufio"
 	"flag"
-	"io"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/pedroppinheiro/fwf/exporter"
+	"github.com/pedroppinheiro/fwf/transform"
 	"github.com/pedroppinheiro/fwf/yamlconfig"
 )
 
@@ -18,16 +21,82 @@ var (
 	yamlLocation         string
 	fileLocation         string
 	fileExportedLocation string
+	exporterName         string
+	templateLocation     string
+	themeName            string
+	isMasked             bool
 )
 
 func init() {
 	flag.StringVar(&yamlLocation, "yaml", "", "the full path for the yaml configuration")
 	flag.StringVar(&fileLocation, "file", "", "the full path for the file to generate the visualization")
 	flag.StringVar(&fileExportedLocation, "o", "./", "the path to where the exported file should be created")
-	flag.Parse()
+	flag.StringVar(&exporterName, "exporter", "html", "the exporter to be used: \"html\" creates an html file and opens it in the browser, \"ansi\" writes the colored file to the standard output")
+	addHTMLFlags(flag.CommandLine)
+}
+
+// addHTMLFlags adds the flags that customize the html exporter to a given set of flags
+func addHTMLFlags(flags *flag.FlagSet) {
+	flags.StringVar(&templateLocation, "template", "", "the full path for a custom html template, the built-in template is used if none is given")
+	flags.StringVar(&themeName, "theme", "light", "the theme of the html: \"light\" or \"dark\"")
+	flags.BoolVar(&isMasked, "mask", false, "shows the sensitive fields masked, like on \"fwf mask\", with the key on the environment variable "+maskKeyVariable)
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "infer":
+			runInferCommand(os.Args[2:])
+			return
+		case "serve":
+			runServeCommand(os.Args[2:])
+			return
+		case "view":
+			runViewCommand(os.Args[2:])
+			return
+		case "diff":
+			runDiffCommand(os.Args[2:])
+			return
+		case "reconcile":
+			runReconcileCommand(os.Args[2:])
+			return
+		case "schema-diff":
+			runSchemaDiffCommand(os.Args[2:])
+			return
+		case "query":
+			runQueryCommand(os.Args[2:])
+			return
+		case "stats":
+			runStatsCommand(os.Args[2:])
+			return
+		case "split":
+			runSplitCommand(os.Args[2:])
+			return
+		case "extract":
+			runExtractCommand(os.Args[2:])
+			return
+		case "set":
+			runSetCommand(os.Args[2:])
+			return
+		case "patch":
+			runPatchCommand(os.Args[2:])
+			return
+		case "sort":
+			runSortCommand(os.Args[2:])
+			return
+		case "mask":
+			runMaskCommand(os.Args[2:])
+			return
+		case "generate":
+			runGenerateCommand(os.Args[2:])
+			return
+		case "validate":
+			runValidateCommand(os.Args[2:])
+			return
+		}
+	}
+
+	flag.Parse()
 	if yamlLocation == "" {
 		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf -h\" or \"fwf --help\" for help")
 	}
@@ -38,20 +107,23 @@ func main() {
 	configuration := readConfigurationFromYAML(yamlLocation)
 	file := getFile(fileLocation)
 	defer file.Close()
-	reader := bufio.NewReader(file)
-
-	fileExporter := getCurrentExporter()
-	exportedContent := ""
-	for {
-		line, err := reader.ReadString('\n')
 
-		exportedContent += fileExporter.MarkRecordsOnString(configuration.Records, line)
+	fileExporter := getCurrentExporter(configuration.Records)
+	visualization, err := exporter.MarkRecordsOnReader(fileExporter, configuration.Records, file)
+	if err != nil {
+		panic(err)
+	}
+	visualization.Header = exporter.NewHeader(fileLocation, configuration.Version)
+	finalExportedContent, err := fileExporter.ExportVisualization(visualization)
+	if err != nil {
+		panic(err)
+	}
 
-		if err == io.EOF {
-			break
-		}
+	if exporterName == "ansi" {
+		fmt.Print(finalExportedContent)
+		return
 	}
-	finalExportedContent := fileExporter.ExportVisualization(exportedContent)
+
 	generatedFilePath, err := fileExporter.SaveToFile(finalExportedContent, fileExportedLocation)
 
 	if err == nil {
@@ -68,6 +140,9 @@ func readConfigurationFromYAML(yamlLocation string) yamlconfig.Configuration {
 	if err != nil {
 		panic(err)
 	}
+	if err = configuration.LoadValues(filepath.Dir(yamlLocation)); err != nil {
+		panic(err)
+	}
 	return configuration
 }
 
@@ -87,8 +162,49 @@ func getFile(fileLocation string) *os.File {
 	return file
 }
 
-func getCurrentExporter() exporter.Exporter {
-	return exporter.GetHTMLExporter()
+// readLines returns all lines of the file on the given location, without their line breaks
+func readLines(fileLocation string) []string {
+	file := getFile(fileLocation)
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		panic(err)
+	}
+	return lines
+}
+
+func getCurrentExporter(records []yamlconfig.Record) exporter.Exporter {
+	switch exporterName {
+	case "html":
+		customTemplate := ""
+		if templateLocation != "" {
+			customTemplate = string(readFileContent(templateLocation))
+		}
+		htmlExporter, err := exporter.GetCustomHTMLExporter(customTemplate, exporter.Theme(themeName))
+		if err != nil {
+			panic(err)
+		}
+		if isMasked {
+			masker := transform.NewMasker(os.Getenv(maskKeyVariable))
+			if err = masker.Validate(records); err != nil {
+				panic("Please provide the key of the masks on the environment variable " + maskKeyVariable + ", use \"fwf -h\" or \"fwf --help\" for help")
+			}
+			htmlExporter = htmlExporter.WithMasker(masker)
+		}
+		return htmlExporter
+	case "ansi":
+		if isMasked {
+			panic("The sensitive fields can only be masked by the html exporter, use \"fwf mask\" to mask the file instead")
+		}
+		return exporter.GetANSIExporter()
+	}
+	panic(fmt.Sprintf("Unknown exporter %q, use \"fwf -h\" or \"fwf --help\" for help", exporterName))
 }
 
 // OpenInBrowser opens the file in the given path in the browser. https://stackoverflow.com/a/35921541/1252947
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/pedroppinheiro/fwf/transform"
+)
+
+// maskKeyVariable is the environment variable that holds the key used to mask the sensitive fields, which is not taken
+// from a flag so that it is not kept on the history of the shell
+const maskKeyVariable = "FWF_MASK_KEY"
+
+// runMaskCommand handles "fwf mask", which writes a copy of a file with the content of its sensitive fields masked
+func runMaskCommand(args []string) {
+	flags := flag.NewFlagSet("mask", flag.ExitOnError)
+	maskYAMLLocation := flags.String("yaml", "", "the full path for the yaml configuration, in which the sensitive fields are declared")
+	maskFileLocation := flags.String("file", "", "the full path for the file to be masked, which is not changed")
+	outputLocation := flags.String("o", "", "the full path for the masked file to be created")
+	unmatchedOption := flags.String("unmatched", "error", "what to do with the lines that do not match any record, which can not be masked: \"file\" writes them to the file given with \"-o\" followed by \".unmatched\", \"skip\" ignores them and \"error\" stops on the first one")
+	flags.Parse(args)
+
+	if *maskYAMLLocation == "" {
+		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf mask -h\" for help")
+	}
+	if *maskFileLocation == "" {
+		panic("Please provide a valid file location with the flag \"-file\", use \"fwf mask -h\" for help")
+	}
+	if *outputLocation == "" {
+		panic("Please provide the full path for the masked file with the flag \"-o\", use \"fwf mask -h\" for help")
+	}
+	if absolutePath(*outputLocation) == absolutePath(*maskFileLocation) {
+		panic("The masked file can not be the file being masked, please provide another path with the flag \"-o\", use \"fwf mask -h\" for help")
+	}
+	unmatched, err := transform.ParseUnmatched(*unmatchedOption)
+	if err != nil {
+		panic(err)
+	}
+
+	configuration := readConfigurationFromYAML(*maskYAMLLocation)
+	masker := transform.NewMasker(os.Getenv(maskKeyVariable))
+	if err = masker.Validate(configuration.Records); err != nil {
+		panic("Please provide the key of the masks on the environment variable " + maskKeyVariable + ", use \"fwf mask -h\" for help")
+	}
+
+	file := getFile(*maskFileLocation)
+	defer file.Close()
+
+	outputFile, err := os.Create(*outputLocation)
+	if err != nil {
+		panic(err)
+	}
+	defer outputFile.Close()
+	writer := bufio.NewWriter(outputFile)
+
+	var unmatchedFile *os.File
+	var unmatchedWriter *bufio.Writer
+	openUnmatched := func() (io.Writer, error) {
+		var err error
+		if unmatchedFile, err = os.Create(*outputLocation + ".unmatched"); err != nil {
+			return nil, err
+		}
+		unmatchedWriter = bufio.NewWriter(unmatchedFile)
+		return unmatchedWriter, nil
+	}
+
+	result, err := transform.Mask(configuration.Records, file, writer, masker, openUnmatched, unmatched)
+	if unmatchedFile != nil {
+		if flushErr := unmatchedWriter.Flush(); flushErr != nil && err == nil {
+			err = flushErr
+		}
+		if closeErr := unmatchedFile.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	if flushErr := writer.Flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	log.Printf("File created successfully on %v\n", *outputLocation)
+	log.Printf("%v lines masked, %v fields masked on them\n", result.Lines, result.MaskedFields)
+	if result.UnmatchedLines > 0 {
+		if unmatched == transform.WriteUnmatched {
+			log.Printf("%v lines that could not be masked written, as they are, on %v\n", result.UnmatchedLines, *outputLocation+".unmatched")
+		} else {
+			log.Printf("%v lines that could not be masked skipped\n", result.UnmatchedLines)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+
+	"github.com/pedroppinheiro/fwf/transform"
+)
+
+// runPatchCommand handles "fwf patch", which applies the edits of a patch file to a file
+func runPatchCommand(args []string) {
+	flags := flag.NewFlagSet("patch", flag.ExitOnError)
+	patchYAMLLocation := flags.String("yaml", "", "the full path for the yaml configuration")
+	patchFileLocation := flags.String("file", "", "the full path for the file to be edited, which is not changed")
+	patchLocation := flags.String("patch", "", "the full path for the patch, a csv with the columns \"line\", \"field\" and \"value\" whose first row is the header")
+	outputLocation := flags.String("o", "", "the full path for the edited file to be created")
+	auditLocation := flags.String("audit", "", "the full path for the audit log, a csv with the changes made on each line (default the edited file followed by \".audit.csv\")")
+	flags.Parse(args)
+
+	if *patchYAMLLocation == "" {
+		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf patch -h\" for help")
+	}
+	if *patchFileLocation == "" {
+		panic("Please provide a valid file location with the flag \"-file\", use \"fwf patch -h\" for help")
+	}
+	if *patchLocation == "" {
+		panic("Please provide a valid patch location with the flag \"-patch\", use \"fwf patch -h\" for help")
+	}
+
+	configuration := readConfigurationFromYAML(*patchYAMLLocation)
+
+	patchFile := getFile(*patchLocation)
+	edits, err := transform.ReadPatch(patchFile)
+	patchFile.Close()
+	if err != nil {
+		panic(err)
+	}
+
+	var result transform.PatchResult
+	writeEditedFile(*patchFileLocation, *outputLocation, *auditLocation, "patch", func(file io.Reader, w io.Writer, onChange func(transform.Change) error) error {
+		var err error
+		result, err = transform.ApplyPatch(configuration.Records, file, w, edits, onChange)
+		return err
+	})
+
+	log.Printf("%v edits applied, %v edits did not change their lines\n", result.Changed, result.Unchanged)
+}
@@ -0,0 +1,567 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+const (
+	// RecordColumn is the name of the record of a line, which may be used on expressions and projections like a field
+	RecordColumn = "record"
+
+	// LineColumn is the number of a line on its file, starting at 1, which may be used on expressions and projections like a field
+	LineColumn = "line"
+)
+
+// Expression is a condition over the fields of a line, e.g. `record = "detail" AND amount > 1000`
+type Expression interface {
+	Matches(row Row) bool
+}
+
+// Parse parses an expression over the fields of the given records. Fields are referenced by their names, or by their names
+// between backticks when they have spaces or are named like the keywords or like RecordColumn and LineColumn.
+// The supported operators are =, ==, !=, <>, <, <=, >, >=, IN, NOT IN, LIKE, NOT LIKE, AND, OR, NOT, IF ... THEN and
+// parentheses, in which IF a THEN b matches the lines that do not match a or that match b. An error is returned if the expression is not valid or if it references a field that no record has
+func Parse(expression string, records []yamlconfig.Record) (Expression, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, records: records}
+	parsedExpression, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.current().kind != endToken {
+		return nil, p.unexpected()
+	}
+	return parsedExpression, nil
+}
+
+// tokenKind is the kind of a token of an expression
+type tokenKind int
+
+const (
+	endToken tokenKind = iota
+	identifierToken
+	fieldToken
+	stringToken
+	numberToken
+	operatorToken
+	punctuationToken
+)
+
+// token is a part of an expression. Its position is the position of its first character on the expression, starting at 1
+type token struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+// is returns true if the token is a given keyword, operator or punctuation
+func (t token) is(text string) bool {
+	switch t.kind {
+	case identifierToken:
+		return strings.EqualFold(t.text, text)
+	case operatorToken, punctuationToken:
+		return t.text == text
+	}
+	return false
+}
+
+// tokenize splits an expression into its tokens, the last one being always an endToken
+func tokenize(expression string) ([]token, error) {
+	runes := []rune(expression)
+	var tokens []token
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+
+		case r == '(' || r == ')' || r == ',':
+			i++
+			tokens = append(tokens, token{punctuationToken, string(r), start + 1})
+
+		case strings.ContainsRune("=!<>", r):
+			i++
+			if i < len(runes) && (runes[i] == '=' || (r == '<' && runes[i] == '>')) {
+				i++
+			}
+			operator := string(runes[start:i])
+			if operator == "!" {
+				return nil, fmt.Errorf("Parse(): error - unknown operator \"!\" at position %v", start+1)
+			}
+			tokens = append(tokens, token{operatorToken, operator, start + 1})
+
+		case r == '"' || r == '\'' || r == '`':
+			var text strings.Builder
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				text.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("Parse(): error - missing the closing %c of the text at position %v", r, start+1)
+			}
+			i++
+			kind := stringToken
+			if r == '`' {
+				kind = fieldToken
+			}
+			tokens = append(tokens, token{kind, text.String(), start + 1})
+
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			for i++; i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.'); i++ {
+			}
+			tokens = append(tokens, token{numberToken, string(runes[start:i]), start + 1})
+
+		case unicode.IsLetter(r) || r == '_':
+			for i++; i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_'); i++ {
+			}
+			tokens = append(tokens, token{identifierToken, string(runes[start:i]), start + 1})
+
+		default:
+			return nil, fmt.Errorf("Parse(): error - unexpected character %q at position %v", r, start+1)
+		}
+	}
+	return append(tokens, token{endToken, "", len(runes) + 1}), nil
+}
+
+// parser is a recursive descent parser of expressions. From the lowest to the highest precedence: OR, AND, NOT and comparisons.
+// IF ... THEN is parsed like NOT, and its condition and consequence extend up to THEN and up to the end of the expression, or of
+// the parentheses around it
+type parser struct {
+	tokens  []token
+	index   int
+	records []yamlconfig.Record
+}
+
+func (p *parser) current() token {
+	return p.tokens[p.index]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.index]
+	if t.kind != endToken {
+		p.index++
+	}
+	return t
+}
+
+// unexpected returns the error for the current token
+func (p *parser) unexpected() error {
+	t := p.current()
+	if t.kind == endToken {
+		return fmt.Errorf("Parse(): error - unexpected end of the expression")
+	}
+	return fmt.Errorf("Parse(): error - unexpected %q at position %v", t.text, t.position)
+}
+
+// expect consumes the given keyword, operator or punctuation
+func (p *parser) expect(text string) error {
+	if !p.current().is(text) {
+		return p.unexpected()
+	}
+	p.advance()
+	return nil
+}
+
+func (p *parser) parseOr() (Expression, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.current().is("OR") {
+		p.advance()
+		var right Expression
+		if right, err = p.parseAnd(); err == nil {
+			left = orExpression{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseAnd() (Expression, error) {
+	left, err := p.parseNot()
+	for err == nil && p.current().is("AND") {
+		p.advance()
+		var right Expression
+		if right, err = p.parseNot(); err == nil {
+			left = andExpression{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseNot() (Expression, error) {
+	if p.current().is("IF") {
+		p.advance()
+		condition, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect("THEN"); err != nil {
+			return nil, err
+		}
+		consequence, err := p.parseOr()
+		return ifExpression{condition, consequence}, err
+	}
+	if p.current().is("NOT") {
+		p.advance()
+		expression, err := p.parseNot()
+		return notExpression{expression}, err
+	}
+	if p.current().is("(") {
+		p.advance()
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return expression, p.expect(")")
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expression, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	isNegated := false
+	if p.current().is("NOT") {
+		p.advance()
+		isNegated = true
+		if !p.current().is("IN") && !p.current().is("LIKE") {
+			return nil, p.unexpected()
+		}
+	}
+
+	switch t := p.current(); {
+	case t.is("IN"):
+		p.advance()
+		if err = p.expect("("); err != nil {
+			return nil, err
+		}
+		var values []operand
+		for {
+			value, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			if !p.current().is(",") {
+				break
+			}
+			p.advance()
+		}
+		return inExpression{left, values, isNegated}, p.expect(")")
+
+	case t.is("LIKE"):
+		p.advance()
+		if p.current().kind != stringToken {
+			return nil, p.unexpected()
+		}
+		pattern := p.advance()
+		return likeExpression{left, likeRegex(pattern.text), isNegated}, nil
+
+	case t.kind == operatorToken:
+		p.advance()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return comparison{left, t.text, right}, nil
+	}
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	t := p.current()
+	switch t.kind {
+	case stringToken:
+		p.advance()
+		return literal{t.text}, nil
+
+	case numberToken:
+		number, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Parse(): error - %q at position %v is not a valid number", t.text, t.position)
+		}
+		p.advance()
+		return literal{number}, nil
+
+	case identifierToken:
+		for _, keyword := range []string{"AND", "OR", "NOT", "IN", "LIKE", "IF", "THEN"} {
+			if t.is(keyword) {
+				return nil, p.unexpected()
+			}
+		}
+		if t.is(RecordColumn) || t.is(LineColumn) {
+			p.advance()
+			return Column{Name: strings.ToLower(t.text)}, nil
+		}
+		fallthrough
+
+	case fieldToken:
+		if !hasField(p.records, t.text) {
+			return nil, fmt.Errorf("Parse(): error - no record has the field %q, at position %v", t.text, t.position)
+		}
+		p.advance()
+		return Column{Name: t.text, IsField: true}, nil
+	}
+	return nil, p.unexpected()
+}
+
+// hasField returns true if any of the records has a field with the given name
+func hasField(records []yamlconfig.Record, fieldName string) bool {
+	for _, record := range records {
+		if _, isFound := record.FieldByName(fieldName); isFound {
+			return true
+		}
+	}
+	return false
+}
+
+// likeRegex converts a LIKE pattern, in which % matches any text and _ matches any character, to a regex
+func likeRegex(pattern string) *regexp.Regexp {
+	var regex strings.Builder
+	regex.WriteString("(?s)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			regex.WriteString(".*")
+		case '_':
+			regex.WriteString(".")
+		default:
+			regex.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	regex.WriteString("$")
+	return regexp.MustCompile(regex.String())
+}
+
+// operand is a value on an expression, either a literal or the value of a column
+type operand interface {
+	valueOf(row Row) interface{}
+}
+
+// literal is a string or a number, which is a float64, written on an expression
+type literal struct {
+	value interface{}
+}
+
+func (l literal) valueOf(row Row) interface{} {
+	return l.value
+}
+
+// valueOf returns the value of the column used on comparisons. Values that are missing or that are not valid for the type
+// of their field are nil
+func (column Column) valueOf(row Row) interface{} {
+	value, _, err := row.Value(column)
+	if err != nil {
+		return nil
+	}
+	if text, isText := value.(string); isText {
+		return strings.TrimSpace(text)
+	}
+	return value
+}
+
+// compareValues compares two values, returning -1, 0 or 1 as the first value is lower, equal or greater than the second.
+// When any of the values is a number, or a date, the other is converted to a number, or a date, e.g. a date field is
+// compared with "2021-01-31" as a date. False is returned if the values can not be compared, e.g. when any of them is nil
+func compareValues(a interface{}, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+
+	switch {
+	case isNumber(a) || isNumber(b):
+		aNumber, isANumber := toNumber(a)
+		bNumber, isBNumber := toNumber(b)
+		if !isANumber || !isBNumber {
+			return 0, false
+		}
+		return compareOrdered(aNumber < bNumber, aNumber > bNumber), true
+
+	case isDate(a) || isDate(b):
+		aDate, isADate := toDate(a)
+		bDate, isBDate := toDate(b)
+		if !isADate || !isBDate {
+			return 0, false
+		}
+		return compareOrdered(aDate.Before(bDate), aDate.After(bDate)), true
+	}
+
+	aText, isAText := a.(string)
+	bText, isBText := b.(string)
+	if !isAText || !isBText {
+		return 0, false
+	}
+	return strings.Compare(aText, bText), true
+}
+
+// compareOrdered returns -1, 0 or 1 as a value is lower, equal or greater than another one
+func compareOrdered(isLower bool, isGreater bool) int {
+	switch {
+	case isLower:
+		return -1
+	case isGreater:
+		return 1
+	}
+	return 0
+}
+
+// isNumber returns true if the value is an integer or a decimal
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case int64, float64:
+		return true
+	}
+	return false
+}
+
+// toNumber converts integers, decimals and texts with numbers to float64
+func toNumber(value interface{}) (float64, bool) {
+	switch typedValue := value.(type) {
+	case int64:
+		return float64(typedValue), true
+	case float64:
+		return typedValue, true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(typedValue), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// isDate returns true if the value is a date
+func isDate(value interface{}) bool {
+	_, isDate := value.(time.Time)
+	return isDate
+}
+
+// toDate converts dates, and texts written like "2021-01-31" or "2021-01-31 23:59:59", to time.Time
+func toDate(value interface{}) (time.Time, bool) {
+	switch typedValue := value.(type) {
+	case time.Time:
+		return typedValue, true
+	case string:
+		for _, layout := range []string{"2006-01-02", "2006-01-02 15:04:05"} {
+			if date, err := time.Parse(layout, strings.TrimSpace(typedValue)); err == nil {
+				return date, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+type orExpression struct {
+	left  Expression
+	right Expression
+}
+
+func (expression orExpression) Matches(row Row) bool {
+	return expression.left.Matches(row) || expression.right.Matches(row)
+}
+
+type andExpression struct {
+	left  Expression
+	right Expression
+}
+
+func (expression andExpression) Matches(row Row) bool {
+	return expression.left.Matches(row) && expression.right.Matches(row)
+}
+
+type notExpression struct {
+	expression Expression
+}
+
+func (expression notExpression) Matches(row Row) bool {
+	return !expression.expression.Matches(row)
+}
+
+// ifExpression matches when its condition does not match or when its consequence matches
+type ifExpression struct {
+	condition   Expression
+	consequence Expression
+}
+
+func (expression ifExpression) Matches(row Row) bool {
+	return !expression.condition.Matches(row) || expression.consequence.Matches(row)
+}
+
+// comparison compares two operands. It does not match when the operands can not be compared, whatever the operator
+type comparison struct {
+	left     operand
+	operator string
+	right    operand
+}
+
+func (expression comparison) Matches(row Row) bool {
+	result, isComparable := compareValues(expression.left.valueOf(row), expression.right.valueOf(row))
+	if !isComparable {
+		return false
+	}
+	switch expression.operator {
+	case "=", "==":
+		return result == 0
+	case "!=", "<>":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	}
+	return false
+}
+
+// inExpression matches when the operand is equal to any of the values. Like a comparison, it does not match a value
+// that can not be compared, even when negated
+type inExpression struct {
+	operand   operand
+	values    []operand
+	isNegated bool
+}
+
+func (expression inExpression) Matches(row Row) bool {
+	value := expression.operand.valueOf(row)
+	if value == nil {
+		return false
+	}
+	for _, candidate := range expression.values {
+		if result, isComparable := compareValues(value, candidate.valueOf(row)); isComparable && result == 0 {
+			return !expression.isNegated
+		}
+	}
+	return expression.isNegated
+}
+
+// likeExpression matches when the text of the operand matches a pattern
+type likeExpression struct {
+	operand   operand
+	regex     *regexp.Regexp
+	isNegated bool
+}
+
+func (expression likeExpression) Matches(row Row) bool {
+	value := expression.operand.valueOf(row)
+	if value == nil {
+		return false
+	}
+	return expression.regex.MatchString(yamlconfig.FormatValue(value)) != expression.isNegated
+}
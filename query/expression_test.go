@@ -0,0 +1,102 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+var testRecords = []yamlconfig.Record{
+	{
+		Name:   "header",
+		Regex:  yamlconfig.MustCreateRegex("^H"),
+		Fields: []yamlconfig.Field{{Name: "date", Initial: 2, End: 9, Type: yamlconfig.DateType}},
+	},
+	{
+		Name:  "detail",
+		Regex: yamlconfig.MustCreateRegex("^D"),
+		Fields: []yamlconfig.Field{
+			{Name: "id", Initial: 2, End: 4},
+			{Name: "state", Initial: 5, End: 6},
+			{Name: "amount", Initial: 7, End: 13, Type: yamlconfig.DecimalType, Decimals: 2},
+			{Name: "full name", Initial: 14, End: 23},
+			{Name: "date", Initial: 24, End: 31, Type: yamlconfig.DateType},
+		},
+	},
+}
+
+var (
+	testHeader = Row{Number: 1, Line: "H20210131", Record: &testRecords[0]}
+	testDetail = Row{Number: 2, Line: "D001SP0150000John Smith20210115", Record: &testRecords[1]}
+)
+
+func TestParse_Matches(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		row        Row
+		want       bool
+	}{
+		{name: "Should compare the record", expression: `record = "detail"`, row: testDetail, want: true},
+		{name: "Should compare the record on another line", expression: `record = "detail"`, row: testHeader, want: false},
+		{name: "Should compare the line number", expression: `line >= 2`, row: testDetail, want: true},
+		{name: "Should compare texts without their spaces", expression: `state = 'SP'`, row: testDetail, want: true},
+		{name: "Should compare decimals as numbers", expression: `amount > 1000`, row: testDetail, want: true},
+		{name: "Should compare decimals with their decimal places", expression: `amount = 1500.00`, row: testDetail, want: true},
+		{name: "Should compare texts with numbers as numbers", expression: `id = 1`, row: testDetail, want: true},
+		{name: "Should compare dates with texts", expression: `date < "2021-01-31"`, row: testDetail, want: true},
+		{name: "Should compare dates with dates of other records", expression: `date <= "2021-01-31"`, row: testHeader, want: true},
+		{name: "Should not match missing fields", expression: `amount > 0`, row: testHeader, want: false},
+		{name: "Should not match values that can not be compared", expression: `amount != "abc"`, row: testDetail, want: false},
+		{name: "Should find values in a list", expression: `state IN ("SP", "RJ")`, row: testDetail, want: true},
+		{name: "Should find values out of a list", expression: `state NOT IN ("MG", "RJ")`, row: testDetail, want: true},
+		{name: "Should match patterns", expression: "`full name` LIKE 'J_hn%'", row: testDetail, want: true},
+		{name: "Should not match negated patterns", expression: "`full name` NOT LIKE '%Smith'", row: testDetail, want: false},
+		{name: "Should combine conditions", expression: `record = "detail" AND amount > 1000 AND state IN ("SP","RJ")`, row: testDetail, want: true},
+		{name: "Should give precedence to AND over OR", expression: `state = "RJ" AND amount > 0 OR id = "001"`, row: testDetail, want: true},
+		{name: "Should respect parentheses", expression: `state = "RJ" AND (amount > 0 OR id = "001")`, row: testDetail, want: false},
+		{name: "Should negate conditions", expression: `not (state = "RJ") and not record = "header"`, row: testDetail, want: true},
+		{name: "Should accept == as =", expression: `state == "SP"`, row: testDetail, want: true},
+		{name: "Should compare fields with fields", expression: `date < date OR amount >= amount`, row: testDetail, want: true},
+		{name: "Should match conditionals whose condition does not match", expression: `IF state = "RJ" THEN amount < 0`, row: testDetail, want: true},
+		{name: "Should match conditionals whose consequence matches", expression: `if state = "SP" then amount > 0 and id = "001"`, row: testDetail, want: true},
+		{name: "Should compare texts with blank texts", expression: "IF state = 'SP' THEN `full name` != ''", row: testDetail, want: true},
+		{name: "Should not match conditionals whose consequence does not match", expression: `IF state = "SP" THEN amount < 0 OR id = "002"`, row: testDetail, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, err := Parse(tt.expression, testRecords)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := expression.Matches(tt.row); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       string
+	}{
+		{name: "Should not accept unknown fields", expression: `amount > 0 AND city = "x"`, want: `Parse(): error - no record has the field "city", at position 16`},
+		{name: "Should not accept unclosed texts", expression: `state = "SP`, want: `Parse(): error - missing the closing " of the text at position 9`},
+		{name: "Should not accept unclosed parentheses", expression: `(state = "SP"`, want: `Parse(): error - unexpected end of the expression`},
+		{name: "Should not accept missing operands", expression: `state = AND id = 1`, want: `Parse(): error - unexpected "AND" at position 9`},
+		{name: "Should not accept patterns that are not texts", expression: `state LIKE 1`, want: `Parse(): error - unexpected "1" at position 12`},
+		{name: "Should not accept unknown characters", expression: `state = "SP" ; id = 1`, want: `Parse(): error - unexpected character ';' at position 14`},
+		{name: "Should not accept an empty expression", expression: ``, want: `Parse(): error - unexpected end of the expression`},
+		{name: "Should not accept conditionals without THEN", expression: `IF state = "SP" amount > 0`, want: `Parse(): error - unexpected "amount" at position 17`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.expression, testRecords)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Parse() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
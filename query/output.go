@@ -0,0 +1,262 @@
+package query
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// lineColumnWidth is the width of the column with the number of the line on a table. As the rows are written as soon as they
+// are found, the width is not the width of the last line number, and larger numbers just push the other columns
+const lineColumnWidth = 7
+
+// Writer writes the rows selected by a query. Flush must be called after the last row
+type Writer interface {
+	Write(row Row) error
+	Flush() error
+}
+
+// tableWriter writes the rows as a table, in which the width of each column is the length of its field
+type tableWriter struct {
+	writer          *bufio.Writer
+	columns         []Column
+	widths          []int
+	isRightAligned  []bool
+	isHeaderWritten bool
+}
+
+// NewTableWriter returns a Writer that writes the rows as a table with the given columns. The width of each column is the
+// largest length of its field on the given records, so rows can be written as soon as they are found. Numbers are right aligned
+func NewTableWriter(w io.Writer, records []yamlconfig.Record, columns []Column) Writer {
+	table := &tableWriter{
+		writer:         bufio.NewWriter(w),
+		columns:        columns,
+		widths:         make([]int, len(columns)),
+		isRightAligned: make([]bool, len(columns)),
+	}
+
+	for i, column := range columns {
+		table.widths[i] = len(column.Name)
+		switch {
+		case !column.IsField && column.Name == LineColumn:
+			table.widths[i] = maxInt(table.widths[i], lineColumnWidth)
+			table.isRightAligned[i] = true
+		case !column.IsField && column.Name == RecordColumn:
+			for _, record := range records {
+				table.widths[i] = maxInt(table.widths[i], len(record.Name))
+			}
+		default:
+			for _, record := range records {
+				if field, isFound := record.FieldByName(column.Name); isFound {
+					table.widths[i] = maxInt(table.widths[i], displayWidth(field))
+					table.isRightAligned[i] = field.Type == yamlconfig.IntegerType || field.Type == yamlconfig.DecimalType
+				}
+			}
+		}
+	}
+	return table
+}
+
+// displayWidth returns the largest width of the formatted values of a field
+func displayWidth(field yamlconfig.Field) int {
+	if field.Type != yamlconfig.DateType {
+		return field.Length()
+	}
+	if strings.Contains(field.Format, "hh") {
+		return maxInt(field.Length(), len("2006-01-02 15:04:05"))
+	}
+	return maxInt(field.Length(), len("2006-01-02"))
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// writeHeader writes the names of the columns followed by a separator
+func (table *tableWriter) writeHeader() {
+	table.isHeaderWritten = true
+	names := make([]string, len(table.columns))
+	separators := make([]string, len(table.columns))
+	for i, column := range table.columns {
+		names[i] = column.Name
+		separators[i] = strings.Repeat("-", table.widths[i])
+	}
+	table.writeCells(names)
+	table.writer.WriteString(strings.Join(separators, "-+-") + "\n")
+}
+
+// writeCells writes a row of the table, padding each cell to the width of its column
+func (table *tableWriter) writeCells(cells []string) {
+	padded := make([]string, len(cells))
+	for i, cell := range cells {
+		padding := strings.Repeat(" ", maxInt(table.widths[i]-len([]rune(cell)), 0))
+		if table.isRightAligned[i] {
+			padded[i] = padding + cell
+		} else {
+			padded[i] = cell + padding
+		}
+	}
+	table.writer.WriteString(strings.TrimRight(strings.Join(padded, " | "), " ") + "\n")
+}
+
+func (table *tableWriter) Write(row Row) error {
+	if !table.isHeaderWritten {
+		table.writeHeader()
+	}
+	cells := make([]string, len(table.columns))
+	for i, column := range table.columns {
+		cells[i] = row.Text(column)
+	}
+	table.writeCells(cells)
+	return nil
+}
+
+func (table *tableWriter) Flush() error {
+	if !table.isHeaderWritten {
+		table.writeHeader()
+	}
+	return table.writer.Flush()
+}
+
+// csvWriter writes the rows as csv, with a header
+type csvWriter struct {
+	writer          *csv.Writer
+	columns         []Column
+	isHeaderWritten bool
+}
+
+// NewCSVWriter returns a Writer that writes the rows as csv with the given columns, preceded by a header with their names
+func NewCSVWriter(w io.Writer, columns []Column) Writer {
+	return &csvWriter{writer: csv.NewWriter(w), columns: columns}
+}
+
+func (writer *csvWriter) writeHeader() error {
+	writer.isHeaderWritten = true
+	names := make([]string, len(writer.columns))
+	for i, column := range writer.columns {
+		names[i] = column.Name
+	}
+	return writer.writer.Write(names)
+}
+
+func (writer *csvWriter) Write(row Row) error {
+	if !writer.isHeaderWritten {
+		if err := writer.writeHeader(); err != nil {
+			return err
+		}
+	}
+	cells := make([]string, len(writer.columns))
+	for i, column := range writer.columns {
+		cells[i] = row.Text(column)
+	}
+	return writer.writer.Write(cells)
+}
+
+func (writer *csvWriter) Flush() error {
+	if !writer.isHeaderWritten {
+		if err := writer.writeHeader(); err != nil {
+			return err
+		}
+	}
+	writer.writer.Flush()
+	return writer.writer.Error()
+}
+
+// jsonWriter writes each row as a json object on its own line
+type jsonWriter struct {
+	writer  *bufio.Writer
+	columns []Column
+}
+
+// NewJSONWriter returns a Writer that writes each row as a json object on its own line, with the given columns as keys, in order.
+// Numbers are written as json numbers, dates as texts and values that are missing or blank as null. If no columns are given,
+// each object has the number of the line, the record and the fields of the record of the row, each field with known values
+// followed by the description of its value, see WithDescriptions
+func NewJSONWriter(w io.Writer, columns []Column) Writer {
+	return &jsonWriter{writer: bufio.NewWriter(w), columns: columns}
+}
+
+func (writer *jsonWriter) Write(row Row) error {
+	columns := writer.columns
+	if columns == nil {
+		columns = []Column{{Name: LineColumn}, {Name: RecordColumn}}
+		for _, field := range row.Record.Fields {
+			columns = append(columns, Column{Name: field.Name, IsField: true})
+			if field.HasValues() {
+				columns = append(columns, descriptionColumn(field.Name))
+			}
+		}
+	}
+
+	writer.writer.WriteString("{")
+	for i, column := range columns {
+		if i > 0 {
+			writer.writer.WriteString(",")
+		}
+		key, err := json.Marshal(column.Name)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(jsonValue(row, column))
+		if err != nil {
+			return err
+		}
+		writer.writer.Write(key)
+		writer.writer.WriteString(":")
+		writer.writer.Write(value)
+	}
+	_, err := writer.writer.WriteString("}\n")
+	return err
+}
+
+// jsonValue returns the value of a column as it is written on json
+func jsonValue(row Row, column Column) interface{} {
+	value, isFound, err := row.Value(column)
+	switch typedValue := value.(type) {
+	case string:
+		if trimmedValue := strings.TrimSpace(typedValue); trimmedValue != "" {
+			return trimmedValue
+		}
+		return nil
+	case time.Time:
+		return yamlconfig.FormatValue(typedValue)
+	case float64:
+		return json.Number(strconv.FormatFloat(typedValue, 'f', -1, 64))
+	}
+	if isFound && err != nil {
+		return row.Text(column)
+	}
+	return value
+}
+
+func (writer *jsonWriter) Flush() error {
+	return writer.writer.Flush()
+}
+
+// lineWriter writes the rows as they are on the file
+type lineWriter struct {
+	writer *bufio.Writer
+}
+
+// NewLineWriter returns a Writer that writes the lines of the rows as they are, so that the output is a fixed width file too
+func NewLineWriter(w io.Writer) Writer {
+	return &lineWriter{writer: bufio.NewWriter(w)}
+}
+
+func (writer *lineWriter) Write(row Row) error {
+	_, err := writer.writer.WriteString(row.Line + "\n")
+	return err
+}
+
+func (writer *lineWriter) Flush() error {
+	return writer.writer.Flush()
+}
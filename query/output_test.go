@@ -0,0 +1,80 @@
+package query
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriters(t *testing.T) {
+	columns := []Column{{Name: RecordColumn}, {Name: "id", IsField: true}, {Name: "amount", IsField: true}, {Name: "date", IsField: true}}
+
+	tests := []struct {
+		name      string
+		newWriter func(buf *bytes.Buffer) Writer
+		rows      []Row
+		want      string
+	}{
+		{
+			name:      "Should write a table with the widths of the fields",
+			newWriter: func(buf *bytes.Buffer) Writer { return NewTableWriter(buf, testRecords, columns) },
+			rows:      []Row{testHeader, testDetail},
+			want: "record | id  |  amount | date\n" +
+				"-------+-----+---------+-----------\n" +
+				"header |     |         | 2021-01-31\n" +
+				"detail | 001 |    1500 | 2021-01-15\n",
+		},
+		{
+			name:      "Should write the header of a table without rows",
+			newWriter: func(buf *bytes.Buffer) Writer { return NewTableWriter(buf, testRecords, columns[:2]) },
+			want:      "record | id\n-------+----\n",
+		},
+		{
+			name:      "Should write csv",
+			newWriter: func(buf *bytes.Buffer) Writer { return NewCSVWriter(buf, columns) },
+			rows:      []Row{testHeader, testDetail},
+			want:      "record,id,amount,date\nheader,,,2021-01-31\ndetail,001,1500,2021-01-15\n",
+		},
+		{
+			name:      "Should write json with the given columns",
+			newWriter: func(buf *bytes.Buffer) Writer { return NewJSONWriter(buf, columns) },
+			rows:      []Row{testHeader},
+			want:      `{"record":"header","id":null,"amount":null,"date":"2021-01-31"}` + "\n",
+		},
+		{
+			name:      "Should write json with the fields of each record",
+			newWriter: func(buf *bytes.Buffer) Writer { return NewJSONWriter(buf, nil) },
+			rows:      []Row{testDetail, {Number: 3, Line: "D002RJ00000x0", Record: &testRecords[1]}},
+			want: `{"line":2,"record":"detail","id":"001","state":"SP","amount":1500,"full name":"John Smith","date":"2021-01-15"}` + "\n" +
+				`{"line":3,"record":"detail","id":"002","state":"RJ","amount":"00000x0","full name":null,"date":null}` + "\n",
+		},
+		{
+			name:      "Should write blank strings as null",
+			newWriter: func(buf *bytes.Buffer) Writer { return NewJSONWriter(buf, nil) },
+			rows:      []Row{{Number: 4, Line: "D   MG0000100          20210115", Record: &testRecords[1]}},
+			want:      `{"line":4,"record":"detail","id":null,"state":"MG","amount":1,"full name":null,"date":"2021-01-15"}` + "\n",
+		},
+		{
+			name:      "Should write the lines as they are",
+			newWriter: func(buf *bytes.Buffer) Writer { return NewLineWriter(buf) },
+			rows:      []Row{testHeader, testDetail},
+			want:      "H20210131\nD001SP0150000John Smith20210115\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer := tt.newWriter(&buf)
+			for _, row := range tt.rows {
+				if err := writer.Write(row); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := writer.Flush(); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
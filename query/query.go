@@ -0,0 +1,172 @@
+package query
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// Row is a line of a file along with the record it matches. Its number starts at 1
+type Row struct {
+	Number int
+	Line   string
+	Record *yamlconfig.Record
+}
+
+// Column is a value of a row that may be used on expressions and projections: a field, the record of the line or the number
+// of the line, whose names are RecordColumn and LineColumn. Projections may also have the descriptions of the values of the
+// fields that have known values, see WithDescriptions
+type Column struct {
+	Name    string
+	IsField bool
+
+	// DescriptionOf is the name of the field whose value is described by the column, if it is a column of descriptions
+	DescriptionOf string
+}
+
+// descriptionSuffix is appended to the name of a field to name the column with the descriptions of its values
+const descriptionSuffix = " description"
+
+// Value returns the value of a column on the row. Fields are decoded according to their types only, see
+// yamlconfig.Field.ParseTypedValue, and the number of the line is an int64. Values that are not among the known values of
+// their fields, or that do not have valid check digits, are still returned, so that they can be queried, and their
+// descriptions are nil. False is returned if the record of the row does not have the field, and an error if the value is
+// not valid for the type of the field
+func (row Row) Value(column Column) (interface{}, bool, error) {
+	if column.DescriptionOf != "" {
+		field, isFound := row.Record.FieldByName(column.DescriptionOf)
+		if !isFound {
+			return nil, false, nil
+		}
+		if description, isKnown := field.ValueDescription(field.RawValue(row.Line)); isKnown {
+			return description, true, nil
+		}
+		return nil, true, nil
+	}
+
+	if !column.IsField {
+		switch column.Name {
+		case RecordColumn:
+			return row.Record.Name, true, nil
+		case LineColumn:
+			return int64(row.Number), true, nil
+		}
+	}
+
+	field, isFound := row.Record.FieldByName(column.Name)
+	if !isFound {
+		return nil, false, nil
+	}
+	value, err := field.ParseTypedValue(field.RawValue(row.Line))
+	return value, true, err
+}
+
+// Text returns the value of a column as text, without the spaces around it. Decoded values are formatted with
+// yamlconfig.FormatValue, and values that are not valid for the type of their field are returned as they are
+func (row Row) Text(column Column) string {
+	value, isFound, err := row.Value(column)
+	if !isFound {
+		return ""
+	}
+	if err != nil {
+		field, _ := row.Record.FieldByName(column.Name)
+		return strings.TrimSpace(field.RawValue(row.Line))
+	}
+	return strings.TrimSpace(yamlconfig.FormatValue(value))
+}
+
+// ParseColumns parses a comma separated list of columns, e.g. "record, id, `full name`". Like on expressions, fields are
+// referenced by their names, or by their names between backticks. An error is returned if no record has one of the fields
+func ParseColumns(list string, records []yamlconfig.Record) ([]Column, error) {
+	var columns []Column
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case len(name) > 1 && strings.HasPrefix(name, "`") && strings.HasSuffix(name, "`"):
+			name = name[1 : len(name)-1]
+		case strings.EqualFold(name, RecordColumn) || strings.EqualFold(name, LineColumn):
+			columns = append(columns, Column{Name: strings.ToLower(name)})
+			continue
+		}
+
+		if !hasField(records, name) {
+			return nil, fmt.Errorf("ParseColumns(): error - no record has the field %q", name)
+		}
+		columns = append(columns, Column{Name: name, IsField: true})
+	}
+	return columns, nil
+}
+
+// DefaultColumns returns the number of the line, the record and the fields of all the records, in the order they are declared.
+// Fields with the same name on more than one record are a single column
+func DefaultColumns(records []yamlconfig.Record) []Column {
+	columns := []Column{{Name: LineColumn}, {Name: RecordColumn}}
+	isAdded := map[string]bool{}
+	for _, record := range records {
+		for _, field := range record.Fields {
+			if !isAdded[field.Name] {
+				isAdded[field.Name] = true
+				columns = append(columns, Column{Name: field.Name, IsField: true})
+			}
+		}
+	}
+	return columns
+}
+
+// WithDescriptions returns the given columns with a column after each field that has known values on any of the given
+// records, named like the field followed by " description", with the descriptions of its values
+func WithDescriptions(columns []Column, records []yamlconfig.Record) []Column {
+	var described []Column
+	for _, column := range columns {
+		described = append(described, column)
+		if column.IsField && hasValues(records, column.Name) {
+			described = append(described, descriptionColumn(column.Name))
+		}
+	}
+	return described
+}
+
+// descriptionColumn returns the column with the descriptions of the values of a field
+func descriptionColumn(fieldName string) Column {
+	return Column{Name: fieldName + descriptionSuffix, DescriptionOf: fieldName}
+}
+
+// hasValues returns true if a field with the given name has known values on any of the records
+func hasValues(records []yamlconfig.Record, fieldName string) bool {
+	for _, record := range records {
+		if field, isFound := record.FieldByName(fieldName); isFound && field.HasValues() {
+			return true
+		}
+	}
+	return false
+}
+
+// Run reads the lines of a file, one at a time, and calls the given function for each line that matches a record and the given
+// expression. The expression may be nil to select all the lines that match a record. Lines that do not match any record
+// are never selected
+func Run(records []yamlconfig.Record, file io.Reader, where Expression, handle func(Row) error) error {
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		recordIndex, isRecordFound := yamlconfig.FindFirstRecordIndexThatMatchesString(records, line)
+		if !isRecordFound {
+			continue
+		}
+
+		row := Row{Number: lineNumber, Line: line, Record: &records[recordIndex]}
+		if where != nil && !where.Matches(row) {
+			continue
+		}
+		if err := handle(row); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
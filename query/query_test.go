@@ -0,0 +1,151 @@
+package query
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+func TestRun(t *testing.T) {
+	file := "H20210131\nD001SP0150000John Smith20210115\nXunknown\nD002RJ0000100Mary      20210120\n"
+
+	tests := []struct {
+		name  string
+		where string
+		want  []int
+	}{
+		{name: "Should select the lines that match a record", where: "", want: []int{1, 2, 4}},
+		{name: "Should select the lines that match the expression", where: `amount < 100`, want: []int{4}},
+		{name: "Should select no lines", where: `record = "trailer"`, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var where Expression
+			if tt.where != "" {
+				var err error
+				if where, err = Parse(tt.where, testRecords); err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+			}
+
+			var got []int
+			err := Run(testRecords, strings.NewReader(file), where, func(row Row) error {
+				got = append(got, row.Number)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Run() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []Column
+		wantErr bool
+	}{
+		{
+			name: "Should parse fields and the record and line columns",
+			list: "line, Record,id,`full name`",
+			want: []Column{{Name: LineColumn}, {Name: RecordColumn}, {Name: "id", IsField: true}, {Name: "full name", IsField: true}},
+		},
+		{
+			name:    "Should not accept unknown fields",
+			list:    "id, city",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseColumns(tt.list, testRecords)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColumns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultColumns(t *testing.T) {
+	want := []Column{
+		{Name: LineColumn},
+		{Name: RecordColumn},
+		{Name: "date", IsField: true},
+		{Name: "id", IsField: true},
+		{Name: "state", IsField: true},
+		{Name: "amount", IsField: true},
+		{Name: "full name", IsField: true},
+	}
+	if got := DefaultColumns(testRecords); !reflect.DeepEqual(got, want) {
+		t.Errorf("DefaultColumns() = %v, want %v", got, want)
+	}
+}
+
+func TestWithDescriptions(t *testing.T) {
+	records := []yamlconfig.Record{
+		{
+			Name:  "detail",
+			Regex: yamlconfig.MustCreateRegex("^D"),
+			Fields: []yamlconfig.Field{
+				{Name: "id", Initial: 2, End: 4},
+				{Name: "state", Initial: 5, End: 6, Values: &yamlconfig.Values{Descriptions: map[string]string{"SP": "São Paulo"}}},
+			},
+		},
+	}
+	columns := WithDescriptions([]Column{{Name: RecordColumn}, {Name: "id", IsField: true}, {Name: "state", IsField: true}}, records)
+
+	wantColumns := []Column{{Name: RecordColumn}, {Name: "id", IsField: true}, {Name: "state", IsField: true}, {Name: "state description", DescriptionOf: "state"}}
+	if !reflect.DeepEqual(columns, wantColumns) {
+		t.Fatalf("WithDescriptions() = %v, want %v", columns, wantColumns)
+	}
+
+	rows := []Row{
+		{Number: 1, Line: "D001SP", Record: &records[0]},
+		{Number: 2, Line: "D002RJ", Record: &records[0]},
+	}
+	tests := []struct {
+		name      string
+		newWriter func(buf *bytes.Buffer) Writer
+		want      string
+	}{
+		{
+			name:      "Should write the descriptions on csv",
+			newWriter: func(buf *bytes.Buffer) Writer { return NewCSVWriter(buf, columns) },
+			want:      "record,id,state,state description\ndetail,001,SP,São Paulo\ndetail,002,RJ,\n",
+		},
+		{
+			name:      "Should write the descriptions on json",
+			newWriter: func(buf *bytes.Buffer) Writer { return NewJSONWriter(buf, nil) },
+			want: `{"line":1,"record":"detail","id":"001","state":"SP","state description":"São Paulo"}` + "\n" +
+				`{"line":2,"record":"detail","id":"002","state":"RJ","state description":null}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer := tt.newWriter(&buf)
+			for _, row := range rows {
+				if err := writer.Write(row); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := writer.Flush(); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+
+	"github.com/pedroppinheiro/fwf/query"
+)
+
+// runQueryCommand handles "fwf query", which selects the lines of a file whose fields match an expression
+func runQueryCommand(args []string) {
+	flags := flag.NewFlagSet("query", flag.ExitOnError)
+	queryYAMLLocation := flags.String("yaml", "", "the full path for the yaml configuration")
+	queryFileLocation := flags.String("file", "", "the full path for the file to be queried, the standard input is read if none is given")
+	where := flags.String("where", "", "the expression that selects the lines, e.g. 'record = \"detail\" AND amount > 1000 AND state IN (\"SP\",\"RJ\")', all the lines that match a record are selected if none is given")
+	fields := flags.String("fields", "", "the comma separated list of the fields written for each line, e.g. \"line,record,id,amount\", the fields of all the records are written if none is given")
+	format := flags.String("format", "table", "the format written to the standard output: \"table\", \"json\" (an object per line), \"csv\" or \"fwf\" (the lines as they are on the file)")
+	flags.Parse(args)
+
+	if *queryYAMLLocation == "" {
+		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf query -h\" for help")
+	}
+
+	configuration := readConfigurationFromYAML(*queryYAMLLocation)
+
+	var expression query.Expression
+	if *where != "" {
+		var err error
+		if expression, err = query.Parse(*where, configuration.Records); err != nil {
+			panic(err)
+		}
+	}
+
+	var columns []query.Column
+	if *fields != "" {
+		var err error
+		if columns, err = query.ParseColumns(*fields, configuration.Records); err != nil {
+			panic(err)
+		}
+	}
+
+	var writer query.Writer
+	switch *format {
+	case "table":
+		if columns == nil {
+			columns = query.DefaultColumns(configuration.Records)
+		}
+		writer = query.NewTableWriter(os.Stdout, configuration.Records, columns)
+	case "csv":
+		if columns == nil {
+			columns = query.DefaultColumns(configuration.Records)
+		}
+		writer = query.NewCSVWriter(os.Stdout, query.WithDescriptions(columns, configuration.Records))
+	case "json":
+		if columns != nil {
+			columns = query.WithDescriptions(columns, configuration.Records)
+		}
+		writer = query.NewJSONWriter(os.Stdout, columns)
+	case "fwf":
+		if columns != nil {
+			panic("The flag \"-fields\" can not be used with the format \"fwf\", as the lines are written as they are, use \"fwf query -h\" for help")
+		}
+		writer = query.NewLineWriter(os.Stdout)
+	default:
+		panic("Unknown format \"" + *format + "\", use \"fwf query -h\" for help")
+	}
+
+	var file io.Reader = os.Stdin
+	if *queryFileLocation != "" {
+		openedFile := getFile(*queryFileLocation)
+		defer openedFile.Close()
+		file = openedFile
+	}
+
+	if err := query.Run(configuration.Records, file, expression, writer.Write); err != nil {
+		panic(err)
+	}
+	if err := writer.Flush(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pedroppinheiro/fwf/diff"
+)
+
+// runReconcileCommand handles "fwf reconcile", which matches the lines of two files by their keys and reports the lines
+// that are only on one of them and the matched lines that are different. The files do not have to fit in memory
+func runReconcileCommand(args []string) {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage of reconcile: fwf reconcile -yaml=configuration.yaml [flags] old.txt new.txt")
+		flags.PrintDefaults()
+	}
+	reconcileYAMLLocation := flags.String("yaml", "", "the full path for the yaml configuration")
+	keyField := flags.String("key", "", "the name of the field used to match the lines of both files, if none is given the keys of the records are used")
+	outputLocation := flags.String("o", "", "the full path for the report, it is written to the standard output if none is given")
+	memory := flags.Int("memory", 256, "the maximum memory, in megabytes, used to sort each file by its keys")
+	flags.Parse(args)
+
+	if *reconcileYAMLLocation == "" {
+		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf reconcile -h\" for help")
+	}
+	if flags.NArg() != 2 {
+		panic("Please provide the old and the new files after the flags, use \"fwf reconcile -h\" for help")
+	}
+	if *memory < 1 {
+		panic("Please provide a positive memory with the flag \"-memory\", use \"fwf reconcile -h\" for help")
+	}
+
+	configuration := readConfigurationFromYAML(*reconcileYAMLLocation)
+	keyOf, _ := getKeyFunc(configuration.Records, *keyField)
+	if keyOf == nil {
+		panic("Please declare the key of the records on the yaml or provide a key field with the flag \"-key\", use \"fwf reconcile -h\" for help")
+	}
+
+	oldFileLocation, newFileLocation := flags.Arg(0), flags.Arg(1)
+	oldFile, newFile := getFile(oldFileLocation), getFile(newFileLocation)
+	defer oldFile.Close()
+	defer newFile.Close()
+
+	report, err := diff.NewReconciliationReport(filepath.Base(oldFileLocation), filepath.Base(newFileLocation))
+	if err != nil {
+		panic(err)
+	}
+	defer report.Close()
+
+	result, err := diff.Reconcile(configuration.Records, oldFile, newFile, keyOf, *memory*1024*1024, report.Add)
+	if err != nil {
+		panic(err)
+	}
+
+	output := os.Stdout
+	if *outputLocation != "" {
+		if output, err = os.Create(*outputLocation); err != nil {
+			panic(err)
+		}
+		defer output.Close()
+	}
+	if err = report.WriteReport(output, result); err != nil {
+		panic(err)
+	}
+
+	if result.HasDifferences() {
+		report.Close()
+		output.Close()
+		os.Exit(1)
+	}
+}
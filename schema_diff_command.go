@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pedroppinheiro/fwf/diff"
+)
+
+// runSchemaDiffCommand handles "fwf schema-diff", which compares two versions of a layout
+func runSchemaDiffCommand(args []string) {
+	flags := flag.NewFlagSet("schema-diff", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage of schema-diff: fwf schema-diff [flags] old.yaml new.yaml")
+		flags.PrintDefaults()
+	}
+	format := flags.String("format", "text", "the format of the report written to the standard output: \"text\" or \"json\"")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		panic("Please provide the old and the new yaml configurations after the flags, use \"fwf schema-diff -h\" for help")
+	}
+
+	oldConfiguration, newConfiguration := readConfigurationFromYAML(flags.Arg(0)), readConfigurationFromYAML(flags.Arg(1))
+	changes := diff.CompareLayouts(oldConfiguration, newConfiguration)
+
+	var err error
+	switch *format {
+	case "text":
+		err = diff.WriteLayoutChangesAsText(os.Stdout, changes)
+	case "json":
+		err = diff.WriteLayoutChangesAsJSON(os.Stdout, changes)
+	default:
+		panic("Unknown format \"" + *format + "\", use \"fwf schema-diff -h\" for help")
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	if len(changes) > 0 {
+		os.Exit(1)
+	}
+}
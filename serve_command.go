@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"net"
+
+	"github.com/pedroppinheiro/fwf/server"
+)
+
+// runServeCommand handles "fwf serve", which serves the visualization of a file on a local HTTP server
+// and reloads it whenever the yaml configuration or the file change
+func runServeCommand(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	serveYAMLLocation := flags.String("yaml", "", "the full path for the yaml configuration")
+	serveFileLocation := flags.String("file", "", "the full path for the file to generate the visualization")
+	address := flags.String("addr", "localhost:8080", "the address in which the server should listen to")
+	linesPerPage := flags.Int("lines", 1000, "the number of lines shown on each page")
+	addHTMLFlags(flags)
+	flags.Parse(args)
+
+	if *serveYAMLLocation == "" {
+		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf serve -h\" for help")
+	}
+	if *serveFileLocation == "" {
+		panic("Please provide a valid file location with the flag \"-file\", use \"fwf serve -h\" for help")
+	}
+
+	if *linesPerPage < 1 {
+		panic("Please provide a positive number of lines per page with the flag \"-lines\", use \"fwf serve -h\" for help")
+	}
+
+	listener, err := net.Listen("tcp", *address)
+	if err != nil {
+		panic(err)
+	}
+
+	fileExporter := getCurrentExporter(readConfigurationFromYAML(*serveYAMLLocation).Records)
+	fwfServer := server.NewServer(*serveYAMLLocation, *serveFileLocation, fileExporter, *linesPerPage)
+	OpenInBrowser("http://" + listener.Addr().String())
+	panic(fwfServer.Serve(listener))
+}
@@ -0,0 +1,271 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+const (
+	fieldsPath        = "/fields"
+	configurationPath = "/configuration.yaml"
+
+	// editorScript lets the user select a range of columns on a line of the visualization and save it as a field
+	// of the record of that line. The saved yaml changes its version, so the page is reloaded by the live reload script
+	editorScript = `
+		<style>
+			#fwf-editor {
+				position: fixed;
+				top: 0;
+				right: 0;
+				padding: 8px;
+				background-color: #eee;
+				border-bottom-left-radius: 6px;
+				font-family: sans-serif;
+			}
+			body.dark #fwf-editor {
+				background-color: #333;
+			}
+			#fwf-editor-error {
+				color: #b00;
+				white-space: pre-wrap;
+			}
+		</style>
+		<div id="fwf-editor">
+			<span id="fwf-editor-selection">Select a range of columns on a line to create or resize a field</span>
+			<input id="fwf-editor-name" type="text" placeholder="field name">
+			<button id="fwf-editor-save" disabled>Save field</button>
+			<a href="` + configurationPath + `" download>Download yaml</a>
+			<div id="fwf-editor-error"></div>
+		</div>
+		<script>
+			(function () {
+				var selection = null;
+				var pre = document.querySelector("pre");
+
+				function findLine(node) {
+					while (node && node.parentNode !== pre) {
+						node = node.parentNode;
+					}
+					return node;
+				}
+
+				function columnOf(line, container, offset) {
+					var walker = document.createTreeWalker(line, NodeFilter.SHOW_TEXT, null, false);
+					var column = 0;
+					while (walker.nextNode()) {
+						var node = walker.currentNode;
+						if (node.parentNode.closest(".tooltiptext")) {
+							continue;
+						}
+						if (node === container) {
+							return column + Array.from(node.textContent.substring(0, offset)).length;
+						}
+						column += Array.from(node.textContent).length;
+					}
+					return column;
+				}
+
+				document.addEventListener("mouseup", function () {
+					var range = window.getSelection().rangeCount > 0 ? window.getSelection().getRangeAt(0) : null;
+					if (!range || range.collapsed || document.getElementById("fwf-editor").contains(range.startContainer)) {
+						return;
+					}
+					var line = findLine(range.startContainer);
+					if (!line || line !== findLine(range.endContainer)) {
+						return;
+					}
+					selection = {
+						line: Number(pre.dataset.firstLine || 1) + Array.prototype.indexOf.call(pre.children, line),
+						initial: columnOf(line, range.startContainer, range.startOffset) + 1,
+						end: columnOf(line, range.endContainer, range.endOffset)
+					};
+					document.getElementById("fwf-editor-selection").textContent =
+						"Line " + selection.line + ", positions " + selection.initial + " to " + selection.end;
+					document.getElementById("fwf-editor-save").disabled = selection.end < selection.initial;
+				});
+
+				document.getElementById("fwf-editor-save").addEventListener("click", function () {
+					var body = new URLSearchParams();
+					body.set("line", selection.line);
+					body.set("initial", selection.initial);
+					body.set("end", selection.end);
+					body.set("name", document.getElementById("fwf-editor-name").value);
+					fetch("` + fieldsPath + `", {method: "POST", body: body})
+						.then(function (response) {
+							return response.text().then(function (text) {
+								document.getElementById("fwf-editor-error").textContent = response.ok ? "" : text;
+							});
+						});
+				});
+			})();
+		</script>`
+)
+
+// handleSetField creates or resizes a field of the record that matches a given line of the file,
+// and saves the resulting configuration on the yaml file
+func (server *Server) handleSetField(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isSameOrigin(r) {
+		http.Error(w, "only the pages of this server can save fields", http.StatusForbidden)
+		return
+	}
+
+	lineNumber, err := strconv.Atoi(r.FormValue("line"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid line: %v", err), http.StatusBadRequest)
+		return
+	}
+	initial, err := strconv.Atoi(r.FormValue("initial"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid initial position: %v", err), http.StatusBadRequest)
+		return
+	}
+	end, err := strconv.Atoi(r.FormValue("end"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid end position: %v", err), http.StatusBadRequest)
+		return
+	}
+	name := r.FormValue("name")
+	if name == "" {
+		http.Error(w, "the field must have a name", http.StatusBadRequest)
+		return
+	}
+
+	if err = server.setField(lineNumber, yamlconfig.Field{Name: name, Initial: initial, End: end}); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setField sets a given field on the record that matches the line of the given number and writes the configuration back to the yaml file
+func (server *Server) setField(lineNumber int, field yamlconfig.Field) error {
+	server.yamlMutex.Lock()
+	defer server.yamlMutex.Unlock()
+
+	yamlContent, err := ioutil.ReadFile(server.yamlLocation)
+	if err != nil {
+		return err
+	}
+
+	configuration, err := yamlconfig.ReadConfiguration(yamlContent)
+	if err != nil {
+		return err
+	}
+
+	line, err := readLine(server.fileLocation, lineNumber)
+	if err != nil {
+		return err
+	}
+
+	recordIndex, isRecordFound := yamlconfig.FindFirstRecordIndexThatMatchesString(configuration.Records, line)
+	if !isRecordFound {
+		return fmt.Errorf("no record matches line %v", lineNumber)
+	}
+
+	configuration, err = yamlconfig.SetFieldOnRecord(configuration, recordIndex, field)
+	if err != nil {
+		return err
+	}
+
+	yamlContent, err = yamlconfig.WriteConfiguration(configuration)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomically(server.yamlLocation, yamlContent)
+}
+
+// isSameOrigin returns true if the request was sent by a page of the server, according to its Origin header or, when
+// there is none, its Referer header. Requests without both are rejected, so that other sites open on the browser can not
+// change the yaml file
+func isSameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+	if origin == "" {
+		return false
+	}
+
+	originURL, err := url.Parse(origin)
+	return err == nil && originURL.Host == r.Host
+}
+
+// writeFileAtomically writes the content to a temporary file on the same directory of the given location, and then
+// renames it to the location, so that the file is never left partially written. The permissions of an existing file are kept
+func writeFileAtomically(location string, content []byte) error {
+	mode := os.FileMode(0666)
+	if info, err := os.Stat(location); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	temporaryFile, err := ioutil.TempFile(filepath.Dir(location), filepath.Base(location)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporaryFile.Name())
+
+	if _, err = temporaryFile.Write(content); err != nil {
+		temporaryFile.Close()
+		return err
+	}
+	if err = temporaryFile.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(temporaryFile.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(temporaryFile.Name(), location)
+}
+
+// handleConfigurationDownload writes the current content of the yaml file
+func (server *Server) handleConfigurationDownload(w http.ResponseWriter, r *http.Request) {
+	yamlContent, err := ioutil.ReadFile(server.yamlLocation)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-yaml")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"configuration.yaml\"")
+	w.Write(yamlContent)
+}
+
+// readLine returns the line of the given number (starting at 1) of a file, the same way it is given to the exporter
+func readLine(location string, lineNumber int) (string, error) {
+	file, err := os.Open(location)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for currentLineNumber := 1; ; currentLineNumber++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+
+		if currentLineNumber == lineNumber && line != "" {
+			return line, nil
+		}
+
+		if err == io.EOF {
+			return "", fmt.Errorf("the file does not have line %v", lineNumber)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/exporter"
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+func post(t *testing.T, handler http.Handler, path string, values url.Values, headers map[string]string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest("POST", path, strings.NewReader(values.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestServer_handleSetField(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     url.Values
+		headers    map[string]string
+		wantStatus int
+		wantFields []yamlconfig.Field
+	}{
+		{
+			"Should add a new field to the record of the line",
+			url.Values{"line": {"2"}, "initial": {"2"}, "end": {"3"}, "name": {"field 2"}},
+			map[string]string{"Origin": "http://example.com"},
+			http.StatusNoContent,
+			[]yamlconfig.Field{{Name: "field 1", Initial: 1, End: 1}, {Name: "field 2", Initial: 2, End: 3}},
+		},
+		{
+			"Should resize the field with the same name",
+			url.Values{"line": {"1"}, "initial": {"1"}, "end": {"2"}, "name": {"field 1"}},
+			map[string]string{"Origin": "http://example.com"},
+			http.StatusNoContent,
+			[]yamlconfig.Field{{Name: "field 1", Initial: 1, End: 2}},
+		},
+		{
+			"Should not save a field that conflicts with another",
+			url.Values{"line": {"1"}, "initial": {"1"}, "end": {"2"}, "name": {"field 2"}},
+			map[string]string{"Origin": "http://example.com"},
+			http.StatusConflict,
+			[]yamlconfig.Field{{Name: "field 1", Initial: 1, End: 1}},
+		},
+		{
+			"Should not save a field of a line without record",
+			url.Values{"line": {"3"}, "initial": {"1"}, "end": {"2"}, "name": {"field 2"}},
+			map[string]string{"Origin": "http://example.com"},
+			http.StatusConflict,
+			[]yamlconfig.Field{{Name: "field 1", Initial: 1, End: 1}},
+		},
+		{
+			"Should not save a field without name",
+			url.Values{"line": {"1"}, "initial": {"2"}, "end": {"3"}, "name": {""}},
+			map[string]string{"Origin": "http://example.com"},
+			http.StatusBadRequest,
+			[]yamlconfig.Field{{Name: "field 1", Initial: 1, End: 1}},
+		},
+		{
+			"Should accept the referer of a page of the server",
+			url.Values{"line": {"2"}, "initial": {"2"}, "end": {"3"}, "name": {"field 2"}},
+			map[string]string{"Referer": "http://example.com/?page=2"},
+			http.StatusNoContent,
+			[]yamlconfig.Field{{Name: "field 1", Initial: 1, End: 1}, {Name: "field 2", Initial: 2, End: 3}},
+		},
+		{
+			"Should not save a field sent by another site",
+			url.Values{"line": {"2"}, "initial": {"2"}, "end": {"3"}, "name": {"field 2"}},
+			map[string]string{"Origin": "http://attacker.example"},
+			http.StatusForbidden,
+			[]yamlconfig.Field{{Name: "field 1", Initial: 1, End: 1}},
+		},
+		{
+			"Should not save a field sent without origin",
+			url.Values{"line": {"2"}, "initial": {"2"}, "end": {"3"}, "name": {"field 2"}},
+			nil,
+			http.StatusForbidden,
+			[]yamlconfig.Field{{Name: "field 1", Initial: 1, End: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yamlLocation, fileLocation := createTestFiles(t, testYAML, "Abc\nAcd\nBcd")
+			server := NewServer(yamlLocation, fileLocation, exporter.GetHTMLExporter(), 1000)
+
+			response := post(t, server, fieldsPath, tt.values, tt.headers)
+			if response.Code != tt.wantStatus {
+				t.Errorf("Server.handleSetField() status = %v, want %v, body = %v", response.Code, tt.wantStatus, response.Body.String())
+			}
+
+			yamlContent, err := ioutil.ReadFile(yamlLocation)
+			if err != nil {
+				t.Fatal(err)
+			}
+			configuration, err := yamlconfig.ReadConfiguration(yamlContent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := configuration.Records[0].Fields; len(got) != len(tt.wantFields) || got[len(got)-1] != tt.wantFields[len(tt.wantFields)-1] {
+				t.Errorf("Server.handleSetField() fields = %v, want %v", got, tt.wantFields)
+			}
+
+			if files, _ := filepath.Glob(filepath.Join(filepath.Dir(yamlLocation), "*.tmp")); len(files) > 0 {
+				t.Errorf("Server.handleSetField() left the temporary files %v", files)
+			}
+		})
+	}
+}
+
+func TestServer_handleConfigurationDownload(t *testing.T) {
+	yamlLocation, fileLocation := createTestFiles(t, testYAML, "Abc")
+	server := NewServer(yamlLocation, fileLocation, exporter.GetHTMLExporter(), 1000)
+
+	response := get(t, server, configurationPath)
+	if response.Body.String() != testYAML {
+		t.Errorf("Server.handleConfigurationDownload() = %v, want %v", response.Body.String(), testYAML)
+	}
+}
+
+func Test_readLine(t *testing.T) {
+	_, fileLocation := createTestFiles(t, testYAML, "Abc\nBcd")
+
+	tests := []struct {
+		name       string
+		lineNumber int
+		want       string
+		wantErr    bool
+	}{
+		{"Should read the first line with its line break", 1, "Abc\n", false},
+		{"Should read the last line", 2, "Bcd", false},
+		{"Should get error for a line after the end of the file", 3, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readLine(fileLocation, tt.lineNumber)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("readLine() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("readLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
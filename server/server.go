@@ -0,0 +1,216 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pedroppinheiro/fwf/exporter"
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+const (
+	versionPath = "/version"
+
+	// liveReloadScript asks the server, from time to time, for the version of the watched files and
+	// reloads the page when it changes
+	liveReloadScript = `
+		<script>
+			(function () {
+				var currentVersion = null;
+				setInterval(function () {
+					fetch("` + versionPath + `", {cache: "no-store"})
+						.then(function (response) { return response.text(); })
+						.then(function (version) {
+							if (currentVersion !== null && currentVersion !== version) {
+								location.reload();
+							}
+							currentVersion = version;
+						})
+						.catch(function () {});
+				}, 1000);
+			})();
+		</script>`
+
+	errorTemplate = `<!DOCTYPE html>
+		<html>
+			<body>
+				<h3>fwf could not render the file</h3>
+				<pre>%v</pre>
+			</body>
+		</html>`
+)
+
+// Server renders a file with the layout of a yaml configuration and serves it over HTTP.
+// Both files are read again on each request, so that changes to them are shown on the next reload.
+// The file is rendered one page at a time, so that large files can be visualized.
+// Fields created or resized on the page are saved back to the yaml file
+type Server struct {
+	yamlLocation string
+	fileLocation string
+	exporter     exporter.Exporter
+	linesPerPage int
+	mux          *http.ServeMux
+
+	// yamlMutex avoids concurrent edits of the yaml file
+	yamlMutex sync.Mutex
+
+	// lineIndex holds the pages of the file, which are only indexed again when the file changes
+	lineIndex        exporter.LineIndex
+	lineIndexVersion string
+	lineIndexMutex   sync.Mutex
+}
+
+// NewServer returns a Server for the given yaml configuration and file, in which the given exporter is used to render
+// the given number of lines per page
+func NewServer(yamlLocation string, fileLocation string, exporter exporter.Exporter, linesPerPage int) *Server {
+	server := &Server{
+		yamlLocation: yamlLocation,
+		fileLocation: fileLocation,
+		exporter:     exporter,
+		linesPerPage: linesPerPage,
+		mux:          http.NewServeMux(),
+	}
+	server.mux.HandleFunc("/", server.handleVisualization)
+	server.mux.HandleFunc(versionPath, server.handleVersion)
+	server.mux.HandleFunc(fieldsPath, server.handleSetField)
+	server.mux.HandleFunc(configurationPath, server.handleConfigurationDownload)
+	return server
+}
+
+// ServeHTTP implements the http.Handler interface
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mux.ServeHTTP(w, r)
+}
+
+// handleVisualization renders a page of the file with the current yaml configuration. The page is given by the "page"
+// parameter or by the "line" parameter, in which case the page that holds the line is rendered and the line is highlighted
+func (server *Server) handleVisualization(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	pageNumber, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	targetLine, _ := strconv.Atoi(r.URL.Query().Get("line"))
+
+	status := http.StatusOK
+	page, err := server.render(pageNumber, targetLine)
+	if err != nil {
+		status = http.StatusInternalServerError
+		page = fmt.Sprintf(errorTemplate, html.EscapeString(err.Error()))
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	fmt.Fprint(w, injectIntoBody(page, editorScript+liveReloadScript))
+}
+
+// handleVersion writes the version of the watched files, which changes whenever one of them is modified
+func (server *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	fmt.Fprint(w, server.version())
+}
+
+// render reads the yaml configuration and a page of the file and returns the exported visualization.
+// If a target line is given, the page that holds it is rendered instead of the given page
+func (server *Server) render(pageNumber int, targetLine int) (string, error) {
+	yamlContent, err := ioutil.ReadFile(server.yamlLocation)
+	if err != nil {
+		return "", err
+	}
+
+	configuration, err := yamlconfig.ReadConfiguration(yamlContent)
+	if err != nil {
+		return "", err
+	}
+	if err = configuration.LoadValues(filepath.Dir(server.yamlLocation)); err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(server.fileLocation)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	index, err := server.indexLines(file)
+	if err != nil {
+		return "", err
+	}
+
+	if targetLine > 0 {
+		pageNumber = index.PageOfLine(targetLine)
+	}
+
+	visualization, err := exporter.MarkPageOnReader(server.exporter, configuration.Records, file, index, pageNumber)
+	if err != nil {
+		return "", err
+	}
+	if targetLine >= visualization.Page.FirstLine && targetLine <= visualization.Page.LastLine {
+		visualization.Page.TargetLine = targetLine
+	}
+	visualization.Header = exporter.NewHeader(server.fileLocation, configuration.Version)
+
+	return server.exporter.ExportVisualization(visualization)
+}
+
+// indexLines returns the pages of a given file, which is the file being served. The file is only indexed again when it changes
+func (server *Server) indexLines(file *os.File) (exporter.LineIndex, error) {
+	server.lineIndexMutex.Lock()
+	defer server.lineIndexMutex.Unlock()
+
+	fileVersion := locationVersion(server.fileLocation)
+	if server.lineIndexVersion == fileVersion {
+		return server.lineIndex, nil
+	}
+
+	index, err := exporter.BuildLineIndex(file, server.linesPerPage)
+	if err != nil {
+		return exporter.LineIndex{}, err
+	}
+
+	server.lineIndex = index
+	server.lineIndexVersion = fileVersion
+	return index, nil
+}
+
+// version returns a string that identifies the current state of the yaml configuration and the file,
+// based on their sizes and modification times
+func (server *Server) version() string {
+	return locationVersion(server.yamlLocation) + "/" + locationVersion(server.fileLocation)
+}
+
+// locationVersion returns a string that identifies the current state of the file of a given location, based on its size and modification time
+func locationVersion(location string) string {
+	info, err := os.Stat(location)
+	if err != nil {
+		return "missing"
+	}
+	return fmt.Sprintf("%v-%v", info.Size(), info.ModTime().UnixNano())
+}
+
+// injectIntoBody adds a given content to the end of the body of a given html page
+func injectIntoBody(page string, content string) string {
+	index := strings.LastIndex(page, "</body>")
+	if index == -1 {
+		return page + content
+	}
+	return page[:index] + content + page[index:]
+}
+
+// Serve starts serving the visualization on a given listener
+func (server *Server) Serve(listener net.Listener) error {
+	log.Printf("Serving %v on http://%v\n", server.fileLocation, listener.Addr())
+	return http.Serve(listener, server)
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedroppinheiro/fwf/exporter"
+)
+
+const testYAML = `
+records:
+  - name: "record A"
+    regex: "^A"
+    fields:
+      - name: "field 1"
+        initial: 1
+        end: 1`
+
+func createTestFiles(t *testing.T, yamlContent string, fileContent string) (yamlLocation string, fileLocation string) {
+	directory := t.TempDir()
+	yamlLocation = filepath.Join(directory, "configuration.yaml")
+	fileLocation = filepath.Join(directory, "file.txt")
+	if err := ioutil.WriteFile(yamlLocation, []byte(yamlContent), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(fileLocation, []byte(fileContent), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func get(t *testing.T, handler http.Handler, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+	return recorder
+}
+
+func TestServer_handleVisualization(t *testing.T) {
+	tests := []struct {
+		name        string
+		yamlContent string
+		path        string
+		wantStatus  int
+		wantContent []string
+	}{
+		{
+			"Should render the file with the live reload script",
+			testYAML,
+			"/",
+			http.StatusOK,
+			[]string{"<b>field 1</b> (record A)", versionPath, fieldsPath, "</body>"},
+		},
+		{
+			"Should render the error when the yaml is invalid",
+			"records: [",
+			"/",
+			http.StatusInternalServerError,
+			[]string{"fwf could not render the file", versionPath},
+		},
+		{
+			"Should not find other paths",
+			testYAML,
+			"/other",
+			http.StatusNotFound,
+			[]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yamlLocation, fileLocation := createTestFiles(t, tt.yamlContent, "Abc")
+			server := NewServer(yamlLocation, fileLocation, exporter.GetHTMLExporter(), 1000)
+
+			response := get(t, server, tt.path)
+			if response.Code != tt.wantStatus {
+				t.Errorf("Server.handleVisualization() status = %v, want %v", response.Code, tt.wantStatus)
+			}
+			for _, content := range tt.wantContent {
+				if !strings.Contains(response.Body.String(), content) {
+					t.Errorf("Server.handleVisualization() = %v, want it to contain %v", response.Body.String(), content)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_handleVisualization_TemplateError(t *testing.T) {
+	htmlExporter, err := exporter.GetCustomHTMLExporter("<body>{{if not .Page.TargetLine}}{{.Nope}}{{end}}</body>", exporter.LightTheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	yamlLocation, fileLocation := createTestFiles(t, testYAML, "Abc")
+	server := NewServer(yamlLocation, fileLocation, htmlExporter, 1000)
+
+	response := get(t, server, "/")
+	if response.Code != http.StatusInternalServerError {
+		t.Errorf("Server.handleVisualization() status = %v, want %v", response.Code, http.StatusInternalServerError)
+	}
+	if !strings.Contains(response.Body.String(), "fwf could not render the file") {
+		t.Errorf("Server.handleVisualization() = %v, want the error of the template", response.Body.String())
+	}
+}
+
+func TestServer_handleVisualization_Pages(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		wantContent    []string
+		notWantContent []string
+	}{
+		{
+			"Should render the first page by default",
+			"/",
+			[]string{"Page 1 of 3, lines 1-2 of 5", "'overflow'>01<", "'overflow'>02<", "?page=2"},
+			[]string{"'overflow'>03<", "?page=0", "class=\"target\""},
+		},
+		{
+			"Should render a given page",
+			"/?page=2",
+			[]string{"Page 2 of 3, lines 3-4 of 5", "'overflow'>03<", "'overflow'>04<", "?page=1", "?page=3", "counter-reset: line 2"},
+			[]string{"'overflow'>02<", "'overflow'>05<"},
+		},
+		{
+			"Should render the page of a given line and highlight it",
+			"/?line=5",
+			[]string{"Page 3 of 3, lines 5-5 of 5", "'overflow'>05<", "children[ 5  -  5 ]"},
+			[]string{"'overflow'>04<", "?page=4"},
+		},
+		{
+			"Should render the last page when the page is after it",
+			"/?page=9",
+			[]string{"Page 3 of 3"},
+			[]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yamlLocation, fileLocation := createTestFiles(t, testYAML, "A01\nA02\nA03\nA04\nA05\n")
+			server := NewServer(yamlLocation, fileLocation, exporter.GetHTMLExporter(), 2)
+
+			body := get(t, server, tt.path).Body.String()
+			for _, content := range tt.wantContent {
+				if !strings.Contains(body, content) {
+					t.Errorf("Server.handleVisualization() = %v, want it to contain %v", body, content)
+				}
+			}
+			for _, content := range tt.notWantContent {
+				if strings.Contains(body, content) {
+					t.Errorf("Server.handleVisualization() = %v, want it not to contain %v", body, content)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_handleVersion(t *testing.T) {
+	yamlLocation, fileLocation := createTestFiles(t, testYAML, "Abc")
+	server := NewServer(yamlLocation, fileLocation, exporter.GetHTMLExporter(), 1000)
+
+	firstVersion := get(t, server, versionPath).Body.String()
+	if secondVersion := get(t, server, versionPath).Body.String(); firstVersion != secondVersion {
+		t.Errorf("Server.handleVersion() = %v, want it to remain %v while files do not change", secondVersion, firstVersion)
+	}
+
+	modificationTime := time.Now().Add(time.Hour)
+	if err := os.Chtimes(fileLocation, modificationTime, modificationTime); err != nil {
+		t.Fatal(err)
+	}
+	if changedVersion := get(t, server, versionPath).Body.String(); firstVersion == changedVersion {
+		t.Errorf("Server.handleVersion() = %v, want it to change after the file is modified", changedVersion)
+	}
+}
+
+func Test_injectIntoBody(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want string
+	}{
+		{
+			"Should add the content before the end of the body",
+			"<html><body>content</body></html>",
+			"<html><body>content<script></script></body></html>",
+		},
+		{
+			"Should add the content to the end when there is no body",
+			"content",
+			"content<script></script>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := injectIntoBody(tt.page, "<script></script>"); got != tt.want {
+				t.Errorf("injectIntoBody() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/pedroppinheiro/fwf/query"
+	"github.com/pedroppinheiro/fwf/transform"
+)
+
+// runSetCommand handles "fwf set", which sets a field to a value on the lines that match an expression
+func runSetCommand(args []string) {
+	flags := flag.NewFlagSet("set", flag.ExitOnError)
+	setYAMLLocation := flags.String("yaml", "", "the full path for the yaml configuration")
+	setFileLocation := flags.String("file", "", "the full path for the file to be edited, which is not changed")
+	field := flags.String("field", "", "the name of the field to be set")
+	value := flags.String("value", "", "the value to be set, which is padded to the width of the field: numbers with zeros on the left and the other types with spaces on the right")
+	where := flags.String("where", "", "the expression that selects the lines to be changed, like on \"fwf query\", e.g. \"record = 'detail'\", all the lines whose record has the field are changed if none is given")
+	outputLocation := flags.String("o", "", "the full path for the edited file to be created")
+	auditLocation := flags.String("audit", "", "the full path for the audit log, a csv with the changes made on each line (default the edited file followed by \".audit.csv\")")
+	flags.Parse(args)
+
+	if *setYAMLLocation == "" {
+		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf set -h\" for help")
+	}
+	if *setFileLocation == "" {
+		panic("Please provide a valid file location with the flag \"-file\", use \"fwf set -h\" for help")
+	}
+	if *field == "" {
+		panic("Please provide the field to be set with the flag \"-field\", use \"fwf set -h\" for help")
+	}
+
+	configuration := readConfigurationFromYAML(*setYAMLLocation)
+
+	var expression query.Expression
+	if *where != "" {
+		var err error
+		if expression, err = query.Parse(*where, configuration.Records); err != nil {
+			panic(err)
+		}
+	}
+
+	var result transform.SetResult
+	writeEditedFile(*setFileLocation, *outputLocation, *auditLocation, "set", func(file io.Reader, w io.Writer, onChange func(transform.Change) error) error {
+		var err error
+		result, err = transform.Set(configuration.Records, file, w, *field, *value, expression, onChange)
+		return err
+	})
+
+	log.Printf("%v lines changed, %v lines already had the value\n", result.Changed, result.Unchanged)
+	if result.WithoutField > 0 {
+		log.Printf("%v selected lines skipped, as their records do not have the field %q\n", result.WithoutField, *field)
+	}
+}
+
+// writeEditedFile creates an edited copy of a file, along with the audit log of the changes made by the given function,
+// which reads the file and writes the edited file. The command name is used on the error messages
+func writeEditedFile(fileLocation string, outputLocation string, auditLocation string, commandName string, edit func(file io.Reader, w io.Writer, onChange func(transform.Change) error) error) {
+	if outputLocation == "" {
+		panic("Please provide the full path for the edited file with the flag \"-o\", use \"fwf " + commandName + " -h\" for help")
+	}
+	if absoluteFile, absoluteOutput := absolutePath(fileLocation), absolutePath(outputLocation); absoluteFile == absoluteOutput {
+		panic("The edited file can not be the file being edited, please provide another path with the flag \"-o\", use \"fwf " + commandName + " -h\" for help")
+	}
+	if auditLocation == "" {
+		auditLocation = outputLocation + ".audit.csv"
+	}
+	if absoluteAudit := absolutePath(auditLocation); absoluteAudit == absolutePath(fileLocation) || absoluteAudit == absolutePath(outputLocation) {
+		panic("The audit log can not be the file being edited nor the edited file, please provide another path with the flag \"-audit\", use \"fwf " + commandName + " -h\" for help")
+	}
+
+	file := getFile(fileLocation)
+	defer file.Close()
+
+	outputFile, err := os.Create(outputLocation)
+	if err != nil {
+		panic(err)
+	}
+	defer outputFile.Close()
+
+	auditFile, err := os.Create(auditLocation)
+	if err != nil {
+		panic(err)
+	}
+	defer auditFile.Close()
+
+	writer := bufio.NewWriter(outputFile)
+	auditLog := transform.NewAuditLog(auditFile)
+	if err = edit(file, writer, auditLog.Add); err != nil {
+		panic(err)
+	}
+	if err = writer.Flush(); err != nil {
+		panic(err)
+	}
+	if err = auditLog.Flush(); err != nil {
+		panic(err)
+	}
+
+	log.Printf("File created successfully on %v\n", outputLocation)
+	log.Printf("Audit log created successfully on %v\n", auditLocation)
+}
+
+// absolutePath returns the absolute path of a location, or the location itself if it can not be obtained
+func absolutePath(location string) string {
+	absoluteLocation, err := filepath.Abs(location)
+	if err != nil {
+		return location
+	}
+	return absoluteLocation
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/transform"
+)
+
+// runSortCommand handles "fwf sort", which sorts the lines of a file by the values of some fields, keeping the lines of the
+// other records in place. The file does not have to fit in memory
+func runSortCommand(args []string) {
+	flags := flag.NewFlagSet("sort", flag.ExitOnError)
+	sortYAMLLocation := flags.String("yaml", "", "the full path for the yaml configuration")
+	sortFileLocation := flags.String("file", "", "the full path for the file to be sorted, which is not changed")
+	by := flags.String("by", "", "the comma separated list of the fields used to sort the lines, each one optionally followed by \"asc\" or \"desc\", e.g. \"amount desc,name\"")
+	recordNames := flags.String("records", "", "the comma separated list of the records whose lines are sorted, the lines of all the records that have all the fields are sorted if none is given")
+	collationName := flags.String("collation", "binary", "how texts are compared: \"binary\" compares their bytes, \"nocase\" ignores their case and \"text\" ignores their case and accents")
+	withinBatches := flags.Bool("batches", false, "sorts each group of consecutive lines on its own, so that the lines of the other records, such as the headers and trailers of batches, stay in place between them")
+	outputLocation := flags.String("o", "", "the full path for the sorted file to be created, the lines are written to the standard output if none is given")
+	memory := flags.Int("memory", 256, "the maximum memory, in megabytes, used to sort the lines")
+	flags.Parse(args)
+
+	if *sortYAMLLocation == "" {
+		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf sort -h\" for help")
+	}
+	if *sortFileLocation == "" {
+		panic("Please provide a valid file location with the flag \"-file\", use \"fwf sort -h\" for help")
+	}
+	if *by == "" {
+		panic("Please provide the fields used to sort the lines with the flag \"-by\", use \"fwf sort -h\" for help")
+	}
+	if *memory < 1 {
+		panic("Please provide a positive memory with the flag \"-memory\", use \"fwf sort -h\" for help")
+	}
+	if *outputLocation != "" && absolutePath(*outputLocation) == absolutePath(*sortFileLocation) {
+		panic("The sorted file can not be the file being sorted, please provide another path with the flag \"-o\", use \"fwf sort -h\" for help")
+	}
+
+	keys, err := transform.ParseSortKeys(*by)
+	if err != nil {
+		panic(err)
+	}
+	collation, err := transform.ParseCollation(*collationName)
+	if err != nil {
+		panic(err)
+	}
+	options := transform.SortOptions{Keys: keys, Collation: collation, WithinBatches: *withinBatches, MaxBytesInMemory: *memory * 1024 * 1024}
+	if *recordNames != "" {
+		for _, recordName := range strings.Split(*recordNames, ",") {
+			options.Records = append(options.Records, strings.TrimSpace(recordName))
+		}
+	}
+
+	configuration := readConfigurationFromYAML(*sortYAMLLocation)
+	file := getFile(*sortFileLocation)
+	defer file.Close()
+
+	var output io.Writer = os.Stdout
+	if *outputLocation != "" {
+		outputFile, err := os.Create(*outputLocation)
+		if err != nil {
+			panic(err)
+		}
+		defer outputFile.Close()
+		output = outputFile
+	}
+	writer := bufio.NewWriter(output)
+
+	result, err := transform.Sort(configuration.Records, file, writer, options)
+	if flushErr := writer.Flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	if *outputLocation != "" {
+		log.Printf("File created successfully on %v\n", *outputLocation)
+		log.Printf("%v lines sorted, %v lines kept in place\n", result.SortedLines, result.AnchoredLines)
+	}
+}
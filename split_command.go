@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/transform"
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// unsafeFileNameCharacters matches the characters of a record name that are not used on the name of its file
+var unsafeFileNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// runSplitCommand handles "fwf split", which writes the lines of each record to its own file
+func runSplitCommand(args []string) {
+	flags := flag.NewFlagSet("split", flag.ExitOnError)
+	splitYAMLLocation := flags.String("yaml", "", "the full path for the yaml configuration")
+	splitFileLocation := flags.String("file", "", "the full path for the file to be split")
+	outputLocation := flags.String("o", "./", "the path to where the files should be created, each one named after the file and the record, e.g. \"file.detail.txt\"")
+	unmatchedOption := flags.String("unmatched", "file", "what to do with the lines that do not match any record: \"file\" writes them to a file named like \"file.unmatched.txt\", \"skip\" ignores them and \"error\" stops on the first one")
+	flags.Parse(args)
+
+	if *splitYAMLLocation == "" {
+		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf split -h\" for help")
+	}
+	if *splitFileLocation == "" {
+		panic("Please provide a valid file location with the flag \"-file\", use \"fwf split -h\" for help")
+	}
+	unmatched, err := transform.ParseUnmatched(*unmatchedOption)
+	if err != nil {
+		panic(err)
+	}
+
+	configuration := readConfigurationFromYAML(*splitYAMLLocation)
+	file := getFile(*splitFileLocation)
+	defer file.Close()
+
+	locations := splitFileLocations(configuration.Records, *splitFileLocation, *outputLocation)
+	var createdFiles []*os.File
+	var writers []*bufio.Writer
+	open := func(record *yamlconfig.Record) (io.Writer, error) {
+		location := locations[""]
+		if record != nil {
+			location = locations[record.Name]
+		}
+		createdFile, err := os.Create(location)
+		if err != nil {
+			return nil, err
+		}
+		createdFiles = append(createdFiles, createdFile)
+		writers = append(writers, bufio.NewWriter(createdFile))
+		return writers[len(writers)-1], nil
+	}
+
+	result, err := transform.Split(configuration.Records, file, open, unmatched)
+	for i, createdFile := range createdFiles {
+		if flushErr := writers[i].Flush(); flushErr != nil && err == nil {
+			err = flushErr
+		}
+		if closeErr := createdFile.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	for _, record := range configuration.Records {
+		if lines := result.LinesByRecord[record.Name]; lines > 0 {
+			log.Printf("%v lines of %v written on %v\n", lines, record.Name, locations[record.Name])
+		}
+	}
+	if result.UnmatchedLines > 0 {
+		if unmatched == transform.WriteUnmatched {
+			log.Printf("%v lines without record written on %v\n", result.UnmatchedLines, locations[""])
+		} else {
+			log.Printf("%v lines without record skipped\n", result.UnmatchedLines)
+		}
+	}
+}
+
+// splitFileLocations returns the location of the file of each record, by the name of the record, and the location of the file
+// of the lines without record, with an empty name. Files are named after the split file and their records, e.g. "file.detail.txt"
+func splitFileLocations(records []yamlconfig.Record, fileLocation string, outputLocation string) map[string]string {
+	fileName := filepath.Base(fileLocation)
+	extension := filepath.Ext(fileName)
+	baseName := strings.TrimSuffix(fileName, extension)
+
+	locations := map[string]string{"": filepath.Join(outputLocation, baseName+".unmatched"+extension)}
+	recordByLocation := map[string]string{locations[""]: "the lines without record"}
+	for _, record := range records {
+		location := filepath.Join(outputLocation, baseName+"."+unsafeFileNameCharacters.ReplaceAllString(record.Name, "_")+extension)
+		if otherRecord, isUsed := recordByLocation[location]; isUsed {
+			panic("The record \"" + record.Name + "\" and " + otherRecord + " would be written on the same file " + location + ", please rename one of them")
+		}
+		recordByLocation[location] = "the record \"" + record.Name + "\""
+		locations[record.Name] = location
+	}
+	return locations
+}
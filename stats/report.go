@@ -0,0 +1,230 @@
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+var htmlTemplate = `
+	<!DOCTYPE html>
+	<html>
+		<head>
+			<meta charset="utf-8">
+			<title>fwf stats: {{.FileName}}</title>
+			<style>
+				body {
+					font-family: sans-serif;
+				}
+
+				.summary > span {
+					display: inline-block;
+					padding: 2px 8px;
+					margin-right: 4px;
+					border-radius: 4px;
+				}
+
+				.summary > span.unmatched {
+					background-color: rgb(230,230,230);
+				}
+
+				table {
+					border-collapse: collapse;
+					width: 100%;
+				}
+
+				th, td {
+					border-bottom: 1px solid #ddd;
+					padding: 2px 6px;
+					text-align: left;
+					vertical-align: top;
+				}
+
+				td.number {
+					text-align: right;
+				}
+
+				td.values {
+					font-family: 'Courier New', Courier, monospace;
+					white-space: pre-wrap;
+				}
+
+				.warning {
+					background-color: rgb(255,225,225);
+				}
+			</style>
+		</head>
+		<body>
+			<h2>{{.FileName}}</h2>
+			<div class='summary'>
+				<span>{{.Report.Lines}} lines</span>
+				<span class='unmatched'>{{.Report.UnmatchedLines}} without record</span>
+			</div>
+			{{- range .Records}}
+			<section class='record'>
+				<h3>{{.Name}} ({{.Lines}} lines)</h3>
+				<table>
+					<tr><th>Field</th><th>Positions</th><th>Type</th><th>Blank</th><th>Invalid</th><th>Distinct</th><th>Top values</th><th>Min</th><th>Max</th><th>Sum</th><th>Lengths</th></tr>
+					{{- range .Fields}}
+					<tr>
+						<td>{{.Name}}</td>
+						<td>{{.Positions}}</td>
+						<td>{{.Type}}</td>
+						<td class='number'>{{.Blank}}</td>
+						<td class='number{{if .HasInvalid}} warning{{end}}'>{{.Invalid}}</td>
+						<td class='number'>{{.Distinct}}</td>
+						<td class='values'>{{.TopValues}}</td>
+						<td class='number'>{{.Min}}</td>
+						<td class='number'>{{.Max}}</td>
+						<td class='number'>{{.Sum}}</td>
+						<td class='values'>{{.Lengths}}</td>
+					</tr>
+					{{- end}}
+				</table>
+			</section>
+			{{- end}}
+		</body>
+	</html>`
+
+// htmlRecord is a record on the html report
+type htmlRecord struct {
+	Name   string
+	Lines  int
+	Fields []htmlField
+}
+
+// htmlField is a field on the html report, with its statistics formatted as they are shown
+type htmlField struct {
+	Name       string
+	Positions  string
+	Type       string
+	Blank      string
+	Invalid    int
+	HasInvalid bool
+	Distinct   string
+	TopValues  string
+	Min        string
+	Max        string
+	Sum        string
+	Lengths    string
+}
+
+// typeName returns the type of a field, which is "string" when none is given
+func typeName(fieldType yamlconfig.FieldType) string {
+	if fieldType == "" {
+		return string(yamlconfig.StringType)
+	}
+	return string(fieldType)
+}
+
+// formatNumber formats a number that may be nil
+func formatNumber(number *float64) string {
+	if number == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*number, 'f', -1, 64)
+}
+
+// formatBlank formats how many values are blank along with their ratio, e.g. "3 (12.5%)"
+func formatBlank(stats FieldStats) string {
+	return fmt.Sprintf("%v (%.1f%%)", stats.Blank, stats.BlankRatio*100)
+}
+
+// formatDistinct formats how many distinct values a field has, e.g. "10000+" when not all of them were counted
+func formatDistinct(stats FieldStats) string {
+	if stats.IsDistinctCapped {
+		return fmt.Sprintf("%v+", stats.Distinct)
+	}
+	return fmt.Sprint(stats.Distinct)
+}
+
+// formatTopValues formats the most common values, e.g. `"SP" (3), "RJ" (1)`
+func formatTopValues(stats FieldStats) string {
+	values := make([]string, len(stats.TopValues))
+	for i, valueCount := range stats.TopValues {
+		values[i] = fmt.Sprintf("%q (%v)", valueCount.Value, valueCount.Count)
+	}
+	return strings.Join(values, ", ")
+}
+
+// formatLengths formats the length distribution, e.g. "0 (1), 10 (3)"
+func formatLengths(stats FieldStats) string {
+	lengths := make([]string, len(stats.Lengths))
+	for i, lengthCount := range stats.Lengths {
+		lengths[i] = fmt.Sprintf("%v (%v)", lengthCount.Length, lengthCount.Count)
+	}
+	return strings.Join(lengths, ", ")
+}
+
+// ExportHTML returns a self-contained html page with the profile of the file with the given name, with a section for each record
+func ExportHTML(report Report, fileName string) string {
+	t := template.Must(template.New("statsTemplate").Parse(htmlTemplate))
+
+	var records []htmlRecord
+	for _, recordStats := range report.Records {
+		record := htmlRecord{Name: recordStats.Name, Lines: recordStats.Lines}
+		for _, stats := range recordStats.Fields {
+			record.Fields = append(record.Fields, htmlField{
+				Name:       stats.Name,
+				Positions:  fmt.Sprintf("%v-%v", stats.Initial, stats.End),
+				Type:       typeName(stats.Type),
+				Blank:      formatBlank(stats),
+				Invalid:    stats.Invalid,
+				HasInvalid: stats.Invalid > 0,
+				Distinct:   formatDistinct(stats),
+				TopValues:  formatTopValues(stats),
+				Min:        formatNumber(stats.Min),
+				Max:        formatNumber(stats.Max),
+				Sum:        formatNumber(stats.Sum),
+				Lengths:    formatLengths(stats),
+			})
+		}
+		records = append(records, record)
+	}
+
+	var buf bytes.Buffer
+	err := t.Execute(&buf, struct {
+		FileName string
+		Report   Report
+		Records  []htmlRecord
+	}{fileName, report, records})
+	if err != nil {
+		panic(err)
+	}
+	return buf.String()
+}
+
+// WriteText writes the profile as text, with a paragraph for each record and a few lines for each field
+func WriteText(w io.Writer, report Report) error {
+	writer := bufio.NewWriter(w)
+	fmt.Fprintf(writer, "%v lines, %v without record\n", report.Lines, report.UnmatchedLines)
+	for _, recordStats := range report.Records {
+		fmt.Fprintf(writer, "\n%v: %v lines\n", recordStats.Name, recordStats.Lines)
+		for _, stats := range recordStats.Fields {
+			fmt.Fprintf(writer, "  %v (%v-%v, %v): %v blank, %v invalid, %v distinct\n",
+				stats.Name, stats.Initial, stats.End, typeName(stats.Type), formatBlank(stats), stats.Invalid, formatDistinct(stats))
+			if len(stats.TopValues) > 0 {
+				fmt.Fprintf(writer, "      top values: %v\n", formatTopValues(stats))
+			}
+			if stats.Sum != nil {
+				fmt.Fprintf(writer, "      min %v, max %v, sum %v\n", formatNumber(stats.Min), formatNumber(stats.Max), formatNumber(stats.Sum))
+			}
+			fmt.Fprintf(writer, "      lengths: %v\n", formatLengths(stats))
+		}
+	}
+	return writer.Flush()
+}
+
+// WriteJSON writes the profile as json
+func WriteJSON(w io.Writer, report Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -0,0 +1,60 @@
+package stats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	report, err := Profile(testRecords, strings.NewReader(testFile), Options{TopValues: 2, MaxDistinctValues: 100})
+	if err != nil {
+		t.Fatalf("Profile() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err = WriteText(&buf, report); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	want := `6 lines, 1 without record
+
+header: 1 lines
+  date (2-9, date): 0 (0.0%) blank, 0 invalid, 1 distinct
+      top values: "20210131" (1)
+      lengths: 8 (1)
+
+detail: 4 lines
+  state (2-3, string): 1 (25.0%) blank, 0 invalid, 2 distinct
+      top values: "SP" (2), "RJ" (1)
+      lengths: 0 (1), 2 (3)
+  amount (4-8, decimal): 1 (25.0%) blank, 1 invalid, 3 distinct
+      top values: "00010" (1), "00020" (1)
+      min 0.1, max 0.2, sum 0.3
+      lengths: 0 (1), 1 (1), 5 (2)
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteText() = %v, want %v", got, want)
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	report, err := Profile(testRecords, strings.NewReader(testFile), Options{TopValues: 5, MaxDistinctValues: 1})
+	if err != nil {
+		t.Fatalf("Profile() error = %v", err)
+	}
+
+	html := ExportHTML(report, "file.txt")
+	for _, want := range []string{
+		"<title>fwf stats: file.txt</title>",
+		"<span class='unmatched'>1 without record</span>",
+		"<h3>detail (4 lines)</h3>",
+		"<td class='number warning'>1</td>",
+		"<td class='number'>1&#43;</td>",
+		"<td class='number'>0.3</td>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("ExportHTML() does not contain %q", want)
+		}
+	}
+}
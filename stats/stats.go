@@ -0,0 +1,228 @@
+package stats
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// Report is the profile of a file
+type Report struct {
+	Lines          int           `json:"lines"`
+	UnmatchedLines int           `json:"unmatchedLines"`
+	Records        []RecordStats `json:"records"`
+}
+
+// RecordStats is the profile of the lines of a record
+type RecordStats struct {
+	Name   string       `json:"name"`
+	Lines  int          `json:"lines"`
+	Fields []FieldStats `json:"fields"`
+}
+
+// ValueCount is a value of a field along with how many lines have it
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// LengthCount is a length of the values of a field, without the spaces around them, along with how many lines have it
+type LengthCount struct {
+	Length int `json:"length"`
+	Count  int `json:"count"`
+}
+
+// FieldStats is the profile of the values of a field on the lines of its record. Values are compared without the spaces
+// around them. Min, Max and Sum are only given for integer and decimal fields with valid values.
+// To bound the memory used, only a given number of distinct values are counted for each field; when a field has more
+// values than that, IsDistinctCapped is true, Distinct is the number of values counted and TopValues are the most
+// common of them
+type FieldStats struct {
+	Name    string               `json:"name"`
+	Initial int                  `json:"initial"`
+	End     int                  `json:"end"`
+	Type    yamlconfig.FieldType `json:"type,omitempty"`
+
+	Blank      int     `json:"blank"`
+	BlankRatio float64 `json:"blankRatio"`
+	Invalid    int     `json:"invalid"`
+
+	Distinct         int          `json:"distinct"`
+	IsDistinctCapped bool         `json:"isDistinctCapped"`
+	TopValues        []ValueCount `json:"topValues"`
+
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	Sum *float64 `json:"sum,omitempty"`
+
+	Lengths []LengthCount `json:"lengths"`
+}
+
+// Options are the limits of a profile
+type Options struct {
+	// TopValues is how many of the most common values are given for each field
+	TopValues int
+
+	// MaxDistinctValues is how many distinct values are counted for each field
+	MaxDistinctValues int
+}
+
+// fieldProfile accumulates the values of a field
+type fieldProfile struct {
+	field            yamlconfig.Field
+	blank            int
+	invalid          int
+	valueCounts      map[string]int
+	isDistinctCapped bool
+	min, max, sum    float64
+	numbers          int
+	lengthCounts     map[int]int
+}
+
+// recordProfile accumulates the lines of a record
+type recordProfile struct {
+	lines  int
+	fields []*fieldProfile
+}
+
+// Profile reads the lines of a file, one at a time, and returns its profile: how many lines each record has, how many lines
+// do not match any record and the profile of the values of each field
+func Profile(records []yamlconfig.Record, file io.Reader, options Options) (Report, error) {
+	profiles := make([]recordProfile, len(records))
+	for i, record := range records {
+		for _, field := range record.Fields {
+			profiles[i].fields = append(profiles[i].fields, &fieldProfile{
+				field:        field,
+				valueCounts:  map[string]int{},
+				lengthCounts: map[int]int{},
+			})
+		}
+	}
+
+	report := Report{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		report.Lines++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		recordIndex, isRecordFound := yamlconfig.FindFirstRecordIndexThatMatchesString(records, line)
+		if !isRecordFound {
+			report.UnmatchedLines++
+			continue
+		}
+
+		profiles[recordIndex].lines++
+		for _, profile := range profiles[recordIndex].fields {
+			profile.add(records[recordIndex], line, options.MaxDistinctValues)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Report{}, err
+	}
+
+	for i, record := range records {
+		recordStats := RecordStats{Name: record.Name, Lines: profiles[i].lines, Fields: []FieldStats{}}
+		for _, profile := range profiles[i].fields {
+			recordStats.Fields = append(recordStats.Fields, profile.stats(profiles[i].lines, options.TopValues))
+		}
+		report.Records = append(report.Records, recordStats)
+	}
+	return report, nil
+}
+
+// add adds the value of the field on a line of its record to the profile
+func (profile *fieldProfile) add(record yamlconfig.Record, line string, maxDistinctValues int) {
+	value := strings.TrimSpace(profile.field.RawValue(line))
+	profile.lengthCounts[len([]rune(value))]++
+	if value == "" {
+		profile.blank++
+		return
+	}
+
+	if _, isCounted := profile.valueCounts[value]; isCounted || len(profile.valueCounts) < maxDistinctValues {
+		profile.valueCounts[value]++
+	} else {
+		profile.isDistinctCapped = true
+	}
+
+	if _, err := record.ParseFieldValue(profile.field, line); err != nil {
+		profile.invalid++
+	}
+	// the numbers are decoded by their types only, so that unknown values and wrong check digits are still summed
+	parsedValue, err := profile.field.ParseTypedValue(profile.field.RawValue(line))
+	if err != nil {
+		return
+	}
+
+	var number float64
+	switch typedValue := parsedValue.(type) {
+	case int64:
+		number = float64(typedValue)
+	case float64:
+		number = typedValue
+	default:
+		return
+	}
+	if profile.numbers == 0 || number < profile.min {
+		profile.min = number
+	}
+	if profile.numbers == 0 || number > profile.max {
+		profile.max = number
+	}
+	profile.sum += number
+	profile.numbers++
+}
+
+// stats returns the profile of the field, given the number of lines of its record
+func (profile *fieldProfile) stats(lines int, topValues int) FieldStats {
+	stats := FieldStats{
+		Name:             profile.field.Name,
+		Initial:          profile.field.Initial,
+		End:              profile.field.End,
+		Type:             profile.field.Type,
+		Blank:            profile.blank,
+		Invalid:          profile.invalid,
+		Distinct:         len(profile.valueCounts),
+		IsDistinctCapped: profile.isDistinctCapped,
+		TopValues:        []ValueCount{},
+		Lengths:          []LengthCount{},
+	}
+	if lines > 0 {
+		stats.BlankRatio = float64(profile.blank) / float64(lines)
+	}
+	if profile.numbers > 0 {
+		min, max, sum := profile.min, profile.max, profile.sum
+		if profile.field.Type == yamlconfig.DecimalType {
+			// the sum is rounded to the decimal places of the field, so that it does not show the errors of float addition
+			precision := math.Pow10(profile.field.Decimals)
+			sum = math.Round(sum*precision) / precision
+		}
+		stats.Min, stats.Max, stats.Sum = &min, &max, &sum
+	}
+
+	for value, count := range profile.valueCounts {
+		stats.TopValues = append(stats.TopValues, ValueCount{Value: value, Count: count})
+	}
+	sort.Slice(stats.TopValues, func(i, j int) bool {
+		if stats.TopValues[i].Count != stats.TopValues[j].Count {
+			return stats.TopValues[i].Count > stats.TopValues[j].Count
+		}
+		return stats.TopValues[i].Value < stats.TopValues[j].Value
+	})
+	if len(stats.TopValues) > topValues {
+		stats.TopValues = stats.TopValues[:topValues]
+	}
+
+	for length, count := range profile.lengthCounts {
+		stats.Lengths = append(stats.Lengths, LengthCount{Length: length, Count: count})
+	}
+	sort.Slice(stats.Lengths, func(i, j int) bool {
+		return stats.Lengths[i].Length < stats.Lengths[j].Length
+	})
+	return stats
+}
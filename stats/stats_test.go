@@ -0,0 +1,116 @@
+package stats
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+var testRecords = []yamlconfig.Record{
+	{
+		Name:   "header",
+		Regex:  yamlconfig.MustCreateRegex("^H"),
+		Fields: []yamlconfig.Field{{Name: "date", Initial: 2, End: 9, Type: yamlconfig.DateType}},
+	},
+	{
+		Name:  "detail",
+		Regex: yamlconfig.MustCreateRegex("^D"),
+		Fields: []yamlconfig.Field{
+			{Name: "state", Initial: 2, End: 3},
+			{Name: "amount", Initial: 4, End: 8, Type: yamlconfig.DecimalType, Decimals: 2},
+		},
+	},
+}
+
+var testFile = "H20210131\nDSP00010\nDSP00020\nDRJ  x  \nD  \nXunknown\n"
+
+func floatPointer(value float64) *float64 {
+	return &value
+}
+
+func TestProfile(t *testing.T) {
+	report, err := Profile(testRecords, strings.NewReader(testFile), Options{TopValues: 5, MaxDistinctValues: 100})
+	if err != nil {
+		t.Fatalf("Profile() error = %v", err)
+	}
+
+	want := Report{
+		Lines:          6,
+		UnmatchedLines: 1,
+		Records: []RecordStats{
+			{
+				Name:  "header",
+				Lines: 1,
+				Fields: []FieldStats{{
+					Name: "date", Initial: 2, End: 9, Type: yamlconfig.DateType,
+					Distinct:  1,
+					TopValues: []ValueCount{{"20210131", 1}},
+					Lengths:   []LengthCount{{8, 1}},
+				}},
+			},
+			{
+				Name:  "detail",
+				Lines: 4,
+				Fields: []FieldStats{
+					{
+						Name: "state", Initial: 2, End: 3,
+						Blank: 1, BlankRatio: 0.25,
+						Distinct:  2,
+						TopValues: []ValueCount{{"SP", 2}, {"RJ", 1}},
+						Lengths:   []LengthCount{{0, 1}, {2, 3}},
+					},
+					{
+						Name: "amount", Initial: 4, End: 8, Type: yamlconfig.DecimalType,
+						Blank: 1, BlankRatio: 0.25, Invalid: 1,
+						Distinct:  3,
+						TopValues: []ValueCount{{"00010", 1}, {"00020", 1}, {"x", 1}},
+						Min:       floatPointer(0.1), Max: floatPointer(0.2), Sum: floatPointer(0.3),
+						Lengths: []LengthCount{{0, 1}, {1, 1}, {5, 2}},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("Profile() = %+v, want %+v", report, want)
+	}
+}
+
+func TestProfile_Limits(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       Options
+		wantDistinct  int
+		wantCapped    bool
+		wantTopValues []ValueCount
+	}{
+		{
+			name:          "Should give only the most common values",
+			options:       Options{TopValues: 1, MaxDistinctValues: 100},
+			wantDistinct:  2,
+			wantCapped:    false,
+			wantTopValues: []ValueCount{{"SP", 2}},
+		},
+		{
+			name:          "Should count only the given number of distinct values",
+			options:       Options{TopValues: 5, MaxDistinctValues: 1},
+			wantDistinct:  1,
+			wantCapped:    true,
+			wantTopValues: []ValueCount{{"SP", 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Profile(testRecords, strings.NewReader(testFile), tt.options)
+			if err != nil {
+				t.Fatalf("Profile() error = %v", err)
+			}
+			got := report.Records[1].Fields[0]
+			if got.Distinct != tt.wantDistinct || got.IsDistinctCapped != tt.wantCapped || !reflect.DeepEqual(got.TopValues, tt.wantTopValues) {
+				t.Errorf("Profile() = %v distinct, capped %v, top values %v, want %v, %v, %v", got.Distinct, got.IsDistinctCapped, got.TopValues, tt.wantDistinct, tt.wantCapped, tt.wantTopValues)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/pedroppinheiro/fwf/stats"
+)
+
+// runStatsCommand handles "fwf stats", which profiles the records and fields of a file
+func runStatsCommand(args []string) {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	statsYAMLLocation := flags.String("yaml", "", "the full path for the yaml configuration")
+	statsFileLocation := flags.String("file", "", "the full path for the file to be profiled")
+	format := flags.String("format", "text", "the format of the report: \"text\" and \"json\" write the report to the standard output, \"html\" creates a stats.html file and opens it in the browser")
+	outputLocation := flags.String("o", "./", "the path to where the html report should be created")
+	topValues := flags.Int("top", 5, "the number of the most common values shown for each field")
+	maxDistinctValues := flags.Int("distinct", 10000, "the maximum number of distinct values counted for each field, which bounds the memory used")
+	flags.Parse(args)
+
+	if *statsYAMLLocation == "" {
+		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf stats -h\" for help")
+	}
+	if *statsFileLocation == "" {
+		panic("Please provide a valid file location with the flag \"-file\", use \"fwf stats -h\" for help")
+	}
+
+	configuration := readConfigurationFromYAML(*statsYAMLLocation)
+	file := getFile(*statsFileLocation)
+	defer file.Close()
+
+	report, err := stats.Profile(configuration.Records, file, stats.Options{TopValues: *topValues, MaxDistinctValues: *maxDistinctValues})
+	if err != nil {
+		panic(err)
+	}
+
+	switch *format {
+	case "text":
+		err = stats.WriteText(os.Stdout, report)
+	case "json":
+		err = stats.WriteJSON(os.Stdout, report)
+	case "html":
+		generatedFilePath := *outputLocation + "stats.html"
+		if err = ioutil.WriteFile(generatedFilePath, []byte(stats.ExportHTML(report, filepath.Base(*statsFileLocation))), 0777); err != nil {
+			panic(err)
+		}
+		log.Printf("File created successfully on %v\n", generatedFilePath)
+		OpenInBrowser(generatedFilePath)
+	default:
+		panic("Unknown format \"" + *format + "\", use \"fwf stats -h\" for help")
+	}
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,155 @@
+package transform
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// Extraction selects some fields of the lines of a file and writes them, one after the other, as a new fixed-width layout
+type Extraction struct {
+	// Layout is the record of the extracted lines, whose fields are the selected fields, in the given order
+	Layout yamlconfig.Record
+
+	fieldNames []string
+
+	// sourceRecords holds the names of the records that have all the selected fields
+	sourceRecords map[string]bool
+}
+
+// NewExtraction returns the Extraction of the fields with the given names. Only the lines of the records that have all the
+// fields can be extracted. The length of each field on the new layout is its largest length on those records, and its
+// type, decimals, format, description, known values and check digit are those of the first of them. The check digit of a
+// field that checks other fields is left out if any of them is not extracted.
+// An error is returned if no record has one of the fields, or if no record has all of them
+func NewExtraction(records []yamlconfig.Record, fieldNames []string) (Extraction, error) {
+	extraction := Extraction{fieldNames: fieldNames, sourceRecords: map[string]bool{}}
+
+	var sources []yamlconfig.Record
+	for _, record := range records {
+		hasAllFields := true
+		for _, fieldName := range fieldNames {
+			if _, isFound := record.FieldByName(fieldName); !isFound {
+				hasAllFields = false
+			}
+		}
+		if hasAllFields {
+			sources = append(sources, record)
+			extraction.sourceRecords[record.Name] = true
+		}
+	}
+
+	for _, fieldName := range fieldNames {
+		if !hasField(records, fieldName) {
+			return Extraction{}, fmt.Errorf("NewExtraction(): error - no record has the field %q", fieldName)
+		}
+	}
+	if len(sources) == 0 {
+		return Extraction{}, fmt.Errorf("NewExtraction(): error - no record has all the fields %v", strings.Join(fieldNames, ", "))
+	}
+
+	extraction.Layout = yamlconfig.Record{Name: "extract", Regex: yamlconfig.MustCreateRegex(".*")}
+	if len(sources) == 1 {
+		extraction.Layout.Name = sources[0].Name
+	}
+
+	position := 1
+	for _, fieldName := range fieldNames {
+		field, _ := sources[0].FieldByName(fieldName)
+		length := field.Length()
+		for _, source := range sources[1:] {
+			if sourceField, _ := source.FieldByName(fieldName); sourceField.Length() > length {
+				length = sourceField.Length()
+			}
+		}
+
+		field.Initial, field.End = position, position+length-1
+		if field.ChecksGroupDigits() && !containsAll(fieldNames, field.CheckDigit.Of) {
+			field.CheckDigit = nil
+		}
+		extraction.Layout.Fields = append(extraction.Layout.Fields, field)
+		position += length
+	}
+	return extraction, nil
+}
+
+// hasField returns true if any of the records has a field with the given name
+func hasField(records []yamlconfig.Record, fieldName string) bool {
+	for _, record := range records {
+		if _, isFound := record.FieldByName(fieldName); isFound {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAll returns true if all the values are among the given names
+func containsAll(names []string, values []string) bool {
+	for _, value := range values {
+		isFound := false
+		for _, name := range names {
+			isFound = isFound || name == value
+		}
+		if !isFound {
+			return false
+		}
+	}
+	return true
+}
+
+// ExtractResult tells how many lines were extracted and how many could not be, because they do not match any record
+// or because their records do not have all the fields
+type ExtractResult struct {
+	Lines          int
+	UnmatchedLines int
+}
+
+// Extract writes the selected fields of each line of a file, one after the other, keeping the line break of the line.
+// Values shorter than their fields on the new layout, e.g. on short lines, are padded with spaces on the right.
+// The lines that can not be extracted are handled as told by unmatched; when they are written, the writer is obtained
+// with the given function the first time one is found
+func (extraction Extraction) Extract(records []yamlconfig.Record, file io.Reader, w io.Writer, openUnmatched func() (io.Writer, error), unmatched Unmatched) (ExtractResult, error) {
+	result := ExtractResult{}
+	var unmatchedWriter io.Writer
+
+	err := forEachLine(file, func(number int, line string, rawLine string) error {
+		record, isRecordFound := yamlconfig.FindFirstRecordThatMatchesString(records, line)
+		if !isRecordFound || !extraction.sourceRecords[record.Name] {
+			result.UnmatchedLines++
+			switch unmatched {
+			case SkipUnmatched:
+				return nil
+			case FailOnUnmatched:
+				if !isRecordFound {
+					return fmt.Errorf("Extract(): error - line %v does not match any record", number)
+				}
+				return fmt.Errorf("Extract(): error - line %v is of the record %q, which does not have all the fields", number, record.Name)
+			}
+
+			if unmatchedWriter == nil {
+				var err error
+				if unmatchedWriter, err = openUnmatched(); err != nil {
+					return err
+				}
+			}
+			_, err := io.WriteString(unmatchedWriter, rawLine)
+			return err
+		}
+
+		var extracted strings.Builder
+		for i, fieldName := range extraction.fieldNames {
+			field, _ := record.FieldByName(fieldName)
+			value := field.RawValue(line)
+			extracted.WriteString(value)
+			extracted.WriteString(strings.Repeat(" ", extraction.Layout.Fields[i].Length()-len([]rune(value))))
+		}
+		extracted.WriteString(rawLine[len(line):])
+
+		result.Lines++
+		_, err := io.WriteString(w, extracted.String())
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,173 @@
+package transform
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+func TestNewExtraction(t *testing.T) {
+	tests := []struct {
+		name       string
+		fieldNames []string
+		want       yamlconfig.Record
+		wantErr    bool
+	}{
+		{
+			name:       "Should place the fields one after the other",
+			fieldNames: []string{"amount", "id"},
+			want: yamlconfig.Record{Name: "detail", Regex: yamlconfig.MustCreateRegex(".*"), Fields: []yamlconfig.Field{
+				{Name: "amount", Initial: 1, End: 5, Type: yamlconfig.DecimalType, Decimals: 2},
+				{Name: "id", Initial: 6, End: 8},
+			}},
+		},
+		{
+			name:       "Should use the largest length of the fields on the records that have all of them",
+			fieldNames: []string{"type"},
+			want: yamlconfig.Record{Name: "extract", Regex: yamlconfig.MustCreateRegex(".*"), Fields: []yamlconfig.Field{
+				{Name: "type", Initial: 1, End: 1},
+			}},
+		},
+		{
+			name:       "Should not accept unknown fields",
+			fieldNames: []string{"id", "city"},
+			wantErr:    true,
+		},
+		{
+			name:       "Should not accept fields that are not on the same record",
+			fieldNames: []string{"id", "date"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewExtraction(testRecords, tt.fieldNames)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewExtraction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got.Layout, tt.want) {
+				t.Errorf("NewExtraction() = %v, want %v", got.Layout, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewExtraction_ReadLayout(t *testing.T) {
+	records := []yamlconfig.Record{{Name: "account", Regex: yamlconfig.MustCreateRegex(".*"), Fields: []yamlconfig.Field{
+		{Name: "agency", Initial: 1, End: 4},
+		{Name: "account", Initial: 5, End: 9, CheckDigit: &yamlconfig.CheckDigit{Algorithm: yamlconfig.LuhnCheckDigit}},
+		{Name: "digit", Initial: 10, End: 10, CheckDigit: &yamlconfig.CheckDigit{Algorithm: yamlconfig.Mod11CheckDigit, Of: []string{"agency", "account"}}},
+	}}}
+
+	tests := []struct {
+		name           string
+		fieldNames     []string
+		wantCheckDigit []bool
+	}{
+		{"Should keep the check digit of a group whose fields are all extracted", []string{"agency", "account", "digit"}, []bool{false, true, true}},
+		{"Should leave out the check digit of a group whose fields are not all extracted", []string{"account", "digit"}, []bool{true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extraction, err := NewExtraction(records, tt.fieldNames)
+			if err != nil {
+				t.Fatalf("NewExtraction() error = %v", err)
+			}
+			content, err := yamlconfig.WriteConfiguration(yamlconfig.Configuration{Records: []yamlconfig.Record{extraction.Layout}})
+			if err != nil {
+				t.Fatalf("WriteConfiguration() error = %v", err)
+			}
+			layout, err := yamlconfig.ReadConfiguration(content)
+			if err != nil {
+				t.Fatalf("ReadConfiguration() error = %v\n%s", err, content)
+			}
+			for i, field := range layout.Records[0].Fields {
+				if (field.CheckDigit != nil) != tt.wantCheckDigit[i] {
+					t.Errorf("NewExtraction() check digit of %q = %v, want %v", field.Name, field.CheckDigit, tt.wantCheckDigit[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExtraction_Extract(t *testing.T) {
+	tests := []struct {
+		name          string
+		unmatched     Unmatched
+		want          string
+		wantUnmatched string
+		wantResult    ExtractResult
+		wantErr       string
+	}{
+		{
+			name:          "Should extract the fields and write the other lines to a separate file",
+			unmatched:     WriteUnmatched,
+			want:          "00150John 001\r\n00010Mary 002",
+			wantUnmatched: "H20210131\r\nXunknown\r\n",
+			wantResult:    ExtractResult{Lines: 2, UnmatchedLines: 2},
+		},
+		{
+			name:       "Should skip the lines that can not be extracted",
+			unmatched:  SkipUnmatched,
+			want:       "00150John 001\r\n00010Mary 002",
+			wantResult: ExtractResult{Lines: 2, UnmatchedLines: 2},
+		},
+		{
+			name:      "Should fail on the lines of records without the fields",
+			unmatched: FailOnUnmatched,
+			wantErr:   `Extract(): error - line 1 is of the record "header", which does not have all the fields`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extraction, err := NewExtraction(testRecords, []string{"amount", "name", "id"})
+			if err != nil {
+				t.Fatalf("NewExtraction() error = %v", err)
+			}
+
+			var extracted, unmatched bytes.Buffer
+			openUnmatched := func() (io.Writer, error) {
+				return &unmatched, nil
+			}
+			result, err := extraction.Extract(testRecords, strings.NewReader(testFile), &extracted, openUnmatched, tt.unmatched)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Extract() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+
+			if got := extracted.String(); got != tt.want {
+				t.Errorf("Extract() wrote %q, want %q", got, tt.want)
+			}
+			if got := unmatched.String(); got != tt.wantUnmatched {
+				t.Errorf("Extract() wrote unmatched %q, want %q", got, tt.wantUnmatched)
+			}
+			if result != tt.wantResult {
+				t.Errorf("Extract() = %v, want %v", result, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestExtraction_Extract_ShortLines(t *testing.T) {
+	extraction, err := NewExtraction(testRecords, []string{"name", "amount"})
+	if err != nil {
+		t.Fatalf("NewExtraction() error = %v", err)
+	}
+
+	var extracted bytes.Buffer
+	if _, err = extraction.Extract(testRecords, strings.NewReader("D001Jo\n"), &extracted, nil, SkipUnmatched); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if got, want := extracted.String(), "Jo        \n"; got != want {
+		t.Errorf("Extract() wrote %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+var (
+	fakeFirstNames = []string{
+		"Ana", "Bruno", "Carla", "Daniel", "Eduarda", "Felipe", "Gabriela", "Henrique", "Isabela", "Joao",
+		"Larissa", "Marcos", "Natalia", "Otavio", "Paula", "Rafael", "Sofia", "Tiago", "Vitoria", "William",
+	}
+	fakeLastNames = []string{
+		"Almeida", "Barbosa", "Carvalho", "Dias", "Ferreira", "Gomes", "Lima", "Martins",
+		"Oliveira", "Pereira", "Ribeiro", "Santos", "Silva", "Souza", "Teixeira", "Vieira",
+	}
+	fakeCompanySuffixes = []string{
+		"Comercio Ltda", "Servicos Ltda", "Industria S.A.", "Tecnologia Ltda", "Participacoes S.A.", "Transportes Ltda",
+	}
+)
+
+// FakeValue returns the fake data created by a generator from the bytes of a hash, so that the same hash always creates
+// the same data. Names are written in upper case when the original value is, and CPFs and CNPJs are written with their
+// punctuation when the original value has any
+func FakeValue(generator yamlconfig.FakeGenerator, value string, hashed []byte) string {
+	switch generator {
+	case yamlconfig.NameGenerator, yamlconfig.CompanyGenerator:
+		fake := fakeFirstNames[int(hashed[0])%len(fakeFirstNames)] + " " + fakeLastNames[int(hashed[1])%len(fakeLastNames)]
+		if generator == yamlconfig.CompanyGenerator {
+			fake = fakeLastNames[int(hashed[0])%len(fakeLastNames)] + " " + fakeCompanySuffixes[int(hashed[1])%len(fakeCompanySuffixes)]
+		}
+		if strings.ToUpper(value) == value {
+			return strings.ToUpper(fake)
+		}
+		return fake
+
+	case yamlconfig.CPFGenerator, yamlconfig.CNPJGenerator:
+		isFormatted := strings.IndexFunc(value, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0
+		if generator == yamlconfig.CPFGenerator {
+			digits := withCheckDigitsMod11(hashedDigits(hashed, 9), 11)
+			if isFormatted {
+				return fmt.Sprintf("%v.%v.%v-%v", digits[:3], digits[3:6], digits[6:9], digits[9:])
+			}
+			return digits
+		}
+		digits := withCheckDigitsMod11(hashedDigits(hashed, 12), 9)
+		if isFormatted {
+			return fmt.Sprintf("%v.%v.%v/%v-%v", digits[:2], digits[2:5], digits[5:8], digits[8:12], digits[12:])
+		}
+		return digits
+	}
+	return value
+}
+
+// hashedDigits returns the given number of digits created from the bytes of a hash
+func hashedDigits(hashed []byte, size int) string {
+	digits := make([]byte, size)
+	for i := range digits {
+		digits[i] = byte('0' + hashed[i]%10)
+	}
+	return string(digits)
+}
+
+// withCheckDigitsMod11 returns the digits followed by the two check digits used by CPFs and CNPJs. Each check digit is
+// 11 minus the remainder by 11 of the sum of the digits multiplied by weights that go from 2, on the rightmost digit, up to
+// the maximum weight, and then restart from 2. It is 0 when that would be 10 or 11
+func withCheckDigitsMod11(digits string, maxWeight int) string {
+	for i := 0; i < 2; i++ {
+		sum := 0
+		for position := 0; position < len(digits); position++ {
+			sum += int(digits[len(digits)-1-position]-'0') * (2 + position%(maxWeight-1))
+		}
+		checkDigit := 11 - sum%11
+		if checkDigit >= 10 {
+			checkDigit = 0
+		}
+		digits += string(rune('0' + checkDigit))
+	}
+	return digits
+}
@@ -0,0 +1,203 @@
+package transform
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// tokenCharacters are the characters of the tokens of fields that are not numbers
+const tokenCharacters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// Masker masks the content of the sensitive fields of lines, keeping the width of the fields. The masked content is
+// created from a hash of the content with the masker's key, so that it can not be guessed without the key
+type Masker struct {
+	key []byte
+}
+
+// NewMasker returns a Masker that uses the given key, which may be empty only if the fields are masked with FixedMask
+func NewMasker(key string) Masker {
+	return Masker{key: []byte(key)}
+}
+
+// Validate returns an error if any sensitive field of the records is not masked with FixedMask and the masker does not
+// have a key. Without a key, the masked values could be found by masking every possible value, e.g. every CPF
+func (masker Masker) Validate(records []yamlconfig.Record) error {
+	if len(masker.key) > 0 {
+		return nil
+	}
+	for _, record := range records {
+		for _, field := range record.Fields {
+			if strategy := field.MaskStrategyOrDefault(); field.Sensitive && strategy != yamlconfig.FixedMask {
+				return fmt.Errorf("Validate(): error - the field %q of the record %q is masked with %v, which needs a key", field.Name, record.Name, strategy)
+			}
+		}
+	}
+	return nil
+}
+
+// MaskLine returns a line, without its line break, with the content of the sensitive fields of its record masked.
+// Blank contents are kept, and every field is masked with FixedMask when the masker does not have a key
+func (masker Masker) MaskLine(record yamlconfig.Record, line string) string {
+	maskedLine, _ := masker.maskLine(record, line)
+	return maskedLine
+}
+
+// maskLine returns the masked line and how many fields were masked on it
+func (masker Masker) maskLine(record yamlconfig.Record, line string) (string, int) {
+	maskedFields := 0
+	for _, field := range record.Fields {
+		rawValue := field.RawValue(line)
+		if !field.Sensitive || strings.TrimSpace(rawValue) == "" {
+			continue
+		}
+		line = field.ReplaceValue(line, masker.maskValue(field, rawValue))
+		maskedFields++
+	}
+	return line, maskedFields
+}
+
+// maskValue returns the masked content of a field, which has as many characters as the given content
+func (masker Masker) maskValue(field yamlconfig.Field, rawValue string) string {
+	width := len([]rune(rawValue))
+	value := strings.TrimSpace(rawValue)
+
+	strategy := field.MaskStrategyOrDefault()
+	if len(masker.key) == 0 {
+		strategy = yamlconfig.FixedMask
+	}
+
+	switch strategy {
+	case yamlconfig.FormatMask:
+		if field.Type == yamlconfig.DateType {
+			if date, err := field.ParseTypedValue(rawValue); err == nil && date != nil {
+				hashed := masker.hash(value, 2)
+				days := int(hashed[0])%365 + 1
+				if hashed[1]%2 == 0 {
+					days = -days
+				}
+				maskedDate := field.FormatDate(date.(time.Time).AddDate(0, 0, days))
+				return strings.Replace(rawValue, value, maskedDate, 1)
+			}
+		}
+
+		hashed := masker.hash(value, width)
+		runes := []rune(rawValue)
+		for i, r := range runes {
+			switch {
+			case unicode.IsDigit(r):
+				runes[i] = rune('0' + hashed[i]%10)
+			case unicode.IsUpper(r):
+				runes[i] = rune('A' + hashed[i]%26)
+			case unicode.IsLetter(r):
+				runes[i] = rune('a' + hashed[i]%26)
+			}
+		}
+		return string(runes)
+
+	case yamlconfig.FakeMask:
+		fake := FakeValue(field.Generator, value, masker.hash(value, 16))
+		if field.Type == yamlconfig.IntegerType {
+			return fitValue(fake, width, '0', false)
+		}
+		return fitValue(fake, width, ' ', true)
+
+	case yamlconfig.TokenMask:
+		characters := tokenCharacters
+		if field.Type == yamlconfig.IntegerType || field.Type == yamlconfig.DecimalType {
+			characters = tokenCharacters[:10]
+		}
+		token := make([]byte, width)
+		for i, b := range masker.hash(value, width) {
+			token[i] = characters[int(b)%len(characters)]
+		}
+		return string(token)
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r == ' ' {
+			return r
+		}
+		return '*'
+	}, rawValue)
+}
+
+// hash returns the given number of bytes of the hash of a value with the masker's key
+func (masker Masker) hash(value string, size int) []byte {
+	var hashed []byte
+	for counter := 0; len(hashed) < size; counter++ {
+		mac := hmac.New(sha256.New, masker.key)
+		fmt.Fprintf(mac, "%v:%v", counter, value)
+		hashed = mac.Sum(hashed)
+	}
+	return hashed[:size]
+}
+
+// fitValue returns a value with the given number of characters, padded with a character on the left or on the right, or
+// truncated when it is longer
+func fitValue(value string, width int, padding rune, isPaddedOnTheRight bool) string {
+	runes := []rune(value)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	pad := strings.Repeat(string(padding), width-len(runes))
+	if isPaddedOnTheRight {
+		return value + pad
+	}
+	return pad + value
+}
+
+// MaskResult tells how many lines were masked, how many fields were masked on them and how many lines could not be masked,
+// since they do not match any record
+type MaskResult struct {
+	Lines          int
+	MaskedFields   int
+	UnmatchedLines int
+}
+
+// Mask writes the lines of a file with the content of the sensitive fields masked, keeping the width of the fields and
+// the line breaks. Lines that do not match any record are handled as told by unmatched, and the writer of the ones that
+// are written as they are is obtained with the given function when the first one is found
+func Mask(records []yamlconfig.Record, file io.Reader, w io.Writer, masker Masker, openUnmatched func() (io.Writer, error), unmatched Unmatched) (MaskResult, error) {
+	if err := masker.Validate(records); err != nil {
+		return MaskResult{}, err
+	}
+
+	result := MaskResult{}
+	var unmatchedWriter io.Writer
+
+	err := forEachLine(file, func(number int, line string, rawLine string) error {
+		record, isRecordFound := yamlconfig.FindFirstRecordThatMatchesString(records, line)
+		if !isRecordFound {
+			result.UnmatchedLines++
+			switch unmatched {
+			case SkipUnmatched:
+				return nil
+			case FailOnUnmatched:
+				return fmt.Errorf("Mask(): error - line %v does not match any record", number)
+			}
+
+			if unmatchedWriter == nil {
+				var err error
+				if unmatchedWriter, err = openUnmatched(); err != nil {
+					return err
+				}
+			}
+			_, err := io.WriteString(unmatchedWriter, rawLine)
+			return err
+		}
+
+		maskedLine, maskedFields := masker.maskLine(record, line)
+		result.Lines++
+		result.MaskedFields += maskedFields
+		_, err := io.WriteString(w, maskedLine+rawLine[len(line):])
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,258 @@
+package transform
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+var testSensitiveRecords = []yamlconfig.Record{
+	{
+		Name:  "customer",
+		Regex: yamlconfig.MustCreateRegex("^C"),
+		Fields: []yamlconfig.Field{
+			{Name: "type", Initial: 1, End: 1},
+			{Name: "name", Initial: 2, End: 11, Sensitive: true},
+			{Name: "account", Initial: 12, End: 17, Sensitive: true, Mask: yamlconfig.FormatMask},
+			{Name: "birth", Initial: 18, End: 25, Type: yamlconfig.DateType, Sensitive: true, Mask: yamlconfig.FormatMask},
+			{Name: "cpf", Initial: 26, End: 36, Type: yamlconfig.IntegerType, Sensitive: true, Mask: yamlconfig.FakeMask, Generator: yamlconfig.CPFGenerator},
+			{Name: "token", Initial: 37, End: 42, Sensitive: true, Mask: yamlconfig.TokenMask},
+		},
+	},
+}
+
+func TestMasker_MaskLine(t *testing.T) {
+	masker := NewMasker("secret")
+	record := testSensitiveRecords[0]
+	line := "CJohn Smith12-34X19800131529982247251234AB"
+
+	got := masker.MaskLine(record, line)
+	if len(got) != len(line) {
+		t.Fatalf("Masker.MaskLine() = %q, which does not have the width of %q", got, line)
+	}
+	if got != masker.MaskLine(record, line) {
+		t.Errorf("Masker.MaskLine() should always mask a line the same way")
+	}
+
+	values := map[string]string{}
+	for _, field := range record.Fields {
+		values[field.Name] = field.RawValue(got)
+		if field.Sensitive && values[field.Name] == field.RawValue(line) {
+			t.Errorf("Masker.MaskLine() did not mask the field %q: %q", field.Name, values[field.Name])
+		}
+	}
+
+	if values["type"] != "C" {
+		t.Errorf("Masker.MaskLine() changed the field type, which is not sensitive: %q", values["type"])
+	}
+	if values["name"] != "**** *****" {
+		t.Errorf("Masker.MaskLine() name = %q, want %q", values["name"], "**** *****")
+	}
+	if account := values["account"]; account[2] != '-' || !strings.ContainsAny(account[:1], "0123456789") || !strings.ContainsAny(account[5:], "ABCDEFGHIJKLMNOPQRSTUVWXYZ") {
+		t.Errorf("Masker.MaskLine() account = %q, which does not keep the format of %q", account, "12-34X")
+	}
+	if _, err := record.Fields[3].ParseValue(values["birth"]); err != nil {
+		t.Errorf("Masker.MaskLine() birth = %q, which is not a valid date: %v", values["birth"], err)
+	}
+	if cpf := values["cpf"]; withCheckDigitsMod11(cpf[:9], 11) != cpf {
+		t.Errorf("Masker.MaskLine() cpf = %q, which is not a valid cpf", cpf)
+	}
+}
+
+func TestMasker_maskValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		masker   Masker
+		field    yamlconfig.Field
+		rawValue string
+		isValid  func(masked string) bool
+	}{
+		{
+			name:     "Should mask each character that is not a space with the fixed mask",
+			masker:   NewMasker(""),
+			field:    yamlconfig.Field{Name: "name", Sensitive: true},
+			rawValue: "Ana Lu  ",
+			isValid:  func(masked string) bool { return masked == "*** **  " },
+		},
+		{
+			name:     "Should mask with the fixed mask when there is no key",
+			masker:   NewMasker(""),
+			field:    yamlconfig.Field{Name: "id", Sensitive: true, Mask: yamlconfig.TokenMask},
+			rawValue: "AB12",
+			isValid:  func(masked string) bool { return masked == "****" },
+		},
+		{
+			name:     "Should create tokens of digits for numbers",
+			masker:   NewMasker("secret"),
+			field:    yamlconfig.Field{Name: "id", Type: yamlconfig.IntegerType, Sensitive: true, Mask: yamlconfig.TokenMask},
+			rawValue: "  1234",
+			isValid:  func(masked string) bool { return strings.Trim(masked, "0123456789") == "" },
+		},
+		{
+			name:     "Should keep the upper case of the names",
+			masker:   NewMasker("secret"),
+			field:    yamlconfig.Field{Name: "name", Sensitive: true, Mask: yamlconfig.FakeMask, Generator: yamlconfig.NameGenerator},
+			rawValue: "JOHN SMITH          ",
+			isValid:  func(masked string) bool { return masked == strings.ToUpper(masked) && masked != "JOHN SMITH          " },
+		},
+		{
+			name:     "Should write the cpf with its punctuation when the value has it",
+			masker:   NewMasker("secret"),
+			field:    yamlconfig.Field{Name: "cpf", Sensitive: true, Mask: yamlconfig.FakeMask, Generator: yamlconfig.CPFGenerator},
+			rawValue: "529.982.247-25",
+			isValid: func(masked string) bool {
+				digits := strings.NewReplacer(".", "", "-", "").Replace(masked)
+				return masked[3] == '.' && masked[7] == '.' && masked[11] == '-' && withCheckDigitsMod11(digits[:9], 11) == digits
+			},
+		},
+		{
+			name:     "Should write the cnpj with zeros on the left of integer fields",
+			masker:   NewMasker("secret"),
+			field:    yamlconfig.Field{Name: "cnpj", Type: yamlconfig.IntegerType, Sensitive: true, Mask: yamlconfig.FakeMask, Generator: yamlconfig.CNPJGenerator},
+			rawValue: "011222333000181",
+			isValid: func(masked string) bool {
+				return masked[0] == '0' && withCheckDigitsMod11(masked[1:13], 9) == masked[1:]
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.masker.maskValue(tt.field, tt.rawValue)
+			if len([]rune(got)) != len([]rune(tt.rawValue)) {
+				t.Errorf("maskValue() = %q, which does not have the width of %q", got, tt.rawValue)
+			}
+			if !tt.isValid(got) {
+				t.Errorf("maskValue() = %q, which is not the expected mask of %q", got, tt.rawValue)
+			}
+			if got != tt.masker.maskValue(tt.field, tt.rawValue) {
+				t.Errorf("maskValue() should always mask a value the same way")
+			}
+		})
+	}
+}
+
+func TestMasker_tokensWithDifferentKeys(t *testing.T) {
+	field := yamlconfig.Field{Name: "id", Sensitive: true, Mask: yamlconfig.TokenMask}
+	if NewMasker("secret").maskValue(field, "1234") == NewMasker("another").maskValue(field, "1234") {
+		t.Errorf("maskValue() should create different tokens with different keys")
+	}
+	if NewMasker("secret").maskValue(field, "1234  ") != NewMasker("secret").maskValue(field, "1234  ") {
+		t.Errorf("maskValue() should create the same tokens for the same values")
+	}
+}
+
+func TestWithCheckDigitsMod11(t *testing.T) {
+	if got := withCheckDigitsMod11("529982247", 11); got != "52998224725" {
+		t.Errorf("withCheckDigitsMod11() = %v, want the cpf 52998224725", got)
+	}
+	if got := withCheckDigitsMod11("112223330001", 9); got != "11222333000181" {
+		t.Errorf("withCheckDigitsMod11() = %v, want the cnpj 11222333000181", got)
+	}
+}
+
+func TestMasker_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		masker  Masker
+		mask    yamlconfig.MaskStrategy
+		wantErr bool
+	}{
+		{name: "Should accept the fixed mask without a key", masker: NewMasker(""), mask: ""},
+		{name: "Should not accept the format mask without a key", masker: NewMasker(""), mask: yamlconfig.FormatMask, wantErr: true},
+		{name: "Should not accept fake data without a key", masker: NewMasker(""), mask: yamlconfig.FakeMask, wantErr: true},
+		{name: "Should not accept tokens without a key", masker: NewMasker(""), mask: yamlconfig.TokenMask, wantErr: true},
+		{name: "Should accept any mask with a key", masker: NewMasker("secret"), mask: yamlconfig.FormatMask},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := []yamlconfig.Record{{Name: "detail", Fields: []yamlconfig.Field{{Name: "id", Initial: 1, End: 3, Sensitive: true, Mask: tt.mask}}}}
+			if err := tt.masker.Validate(records); (err != nil) != tt.wantErr {
+				t.Errorf("Masker.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	records := []yamlconfig.Record{
+		{
+			Name:   "detail",
+			Regex:  yamlconfig.MustCreateRegex("^D"),
+			Fields: []yamlconfig.Field{{Name: "type", Initial: 1, End: 1}, {Name: "name", Initial: 2, End: 6, Sensitive: true}},
+		},
+	}
+	tokenRecords := []yamlconfig.Record{
+		{
+			Name:   "detail",
+			Regex:  yamlconfig.MustCreateRegex("^D"),
+			Fields: []yamlconfig.Field{{Name: "id", Initial: 2, End: 4, Sensitive: true, Mask: yamlconfig.TokenMask}},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		records       []yamlconfig.Record
+		unmatched     Unmatched
+		want          string
+		wantUnmatched string
+		wantResult    MaskResult
+		wantErr       bool
+	}{
+		{
+			name:          "Should mask the sensitive fields keeping the line breaks",
+			records:       records,
+			unmatched:     WriteUnmatched,
+			want:          "D*****\r\nD***  x\r\n",
+			wantUnmatched: "Xunknown\r\n",
+			wantResult:    MaskResult{Lines: 2, MaskedFields: 2, UnmatchedLines: 1},
+		},
+		{
+			name:       "Should skip the lines that do not match any record",
+			records:    records,
+			unmatched:  SkipUnmatched,
+			want:       "D*****\r\nD***  x\r\n",
+			wantResult: MaskResult{Lines: 2, MaskedFields: 2, UnmatchedLines: 1},
+		},
+		{
+			name:      "Should return an error on the lines that do not match any record",
+			records:   records,
+			unmatched: FailOnUnmatched,
+			wantErr:   true,
+		},
+		{
+			name:      "Should return an error when the tokens do not have a key",
+			records:   tokenRecords,
+			unmatched: SkipUnmatched,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			unmatchedOutput := &bytes.Buffer{}
+			openUnmatched := func() (io.Writer, error) { return unmatchedOutput, nil }
+
+			got, err := Mask(tt.records, strings.NewReader("DJohns\r\nXunknown\r\nDAna  x\r\n"), output, NewMasker(""), openUnmatched, tt.unmatched)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Mask() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if output.String() != tt.want {
+				t.Errorf("Mask() wrote %q, want %q", output.String(), tt.want)
+			}
+			if unmatchedOutput.String() != tt.wantUnmatched {
+				t.Errorf("Mask() wrote the unmatched lines %q, want %q", unmatchedOutput.String(), tt.wantUnmatched)
+			}
+			if !reflect.DeepEqual(got, tt.wantResult) {
+				t.Errorf("Mask() = %v, want %v", got, tt.wantResult)
+			}
+		})
+	}
+}
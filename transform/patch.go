@@ -0,0 +1,238 @@
+package transform
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/query"
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// Change is a field whose value was changed on a line, as it is written on the audit log. The values are the contents of
+// the field, with the length of the field
+type Change struct {
+	Line     int
+	Record   string
+	Field    string
+	OldValue string
+	NewValue string
+}
+
+// setField returns the line with the field set to a given value, which is encoded as told by yamlconfig.Field.EncodeValue,
+// along with the change made on the line
+func setField(record yamlconfig.Record, lineNumber int, line string, field yamlconfig.Field, value string) (string, Change, error) {
+	content, err := field.EncodeValue(value)
+	if err != nil {
+		return "", Change{}, fmt.Errorf("line %v: %v", lineNumber, err)
+	}
+
+	oldContent := field.RawValue(line)
+	change := Change{Line: lineNumber, Record: record.Name, Field: field.Name, OldValue: oldContent, NewValue: content}
+	return field.ReplaceValue(line, content), change, nil
+}
+
+// SetResult tells how many of the selected lines were changed, how many already had the value and how many do not
+// have the field on their records
+type SetResult struct {
+	Changed      int
+	Unchanged    int
+	WithoutField int
+}
+
+// Set writes a file in which the field with the given name is set to a value on the lines that match an expression, which may
+// be nil to select all the lines that match a record. The value is encoded as told by yamlconfig.Field.EncodeValue, so the
+// width of the field is kept. The other lines, and the selected lines whose records do not have the field, are written as they
+// are. The given function is called for each changed line
+func Set(records []yamlconfig.Record, file io.Reader, w io.Writer, fieldName string, value string, where query.Expression, onChange func(Change) error) (SetResult, error) {
+	result := SetResult{}
+	err := forEachLine(file, func(number int, line string, rawLine string) error {
+		recordIndex, isRecordFound := yamlconfig.FindFirstRecordIndexThatMatchesString(records, line)
+		if !isRecordFound || (where != nil && !where.Matches(query.Row{Number: number, Line: line, Record: &records[recordIndex]})) {
+			_, err := io.WriteString(w, rawLine)
+			return err
+		}
+
+		record := records[recordIndex]
+		field, isFieldFound := record.FieldByName(fieldName)
+		if !isFieldFound {
+			result.WithoutField++
+			_, err := io.WriteString(w, rawLine)
+			return err
+		}
+
+		newLine, change, err := setField(record, number, line, field, value)
+		if err != nil {
+			return fmt.Errorf("Set(): error - %v", err)
+		}
+		if newLine == line {
+			result.Unchanged++
+		} else {
+			result.Changed++
+			if err = onChange(change); err != nil {
+				return err
+			}
+		}
+		_, err = io.WriteString(w, newLine+rawLine[len(line):])
+		return err
+	})
+	return result, err
+}
+
+// Edit is a change to be made on a file: the field with the given name is set to a value on the line with the given number,
+// starting at 1
+type Edit struct {
+	Line  int
+	Field string
+	Value string
+}
+
+// patchHeader is the header of a patch file
+var patchHeader = []string{"line", "field", "value"}
+
+// ReadPatch reads the edits of a patch file, which is a csv with the columns "line", "field" and "value", in this order,
+// whose first row is the header, e.g.
+//
+//	line,field,value
+//	12,agency,1234
+func ReadPatch(r io.Reader) ([]Edit, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(patchHeader)
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("ReadPatch(): error - the patch is empty, its first row should be the header %q", strings.Join(patchHeader, ","))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ReadPatch(): error - %v", err)
+	}
+	for i, column := range header {
+		if !strings.EqualFold(strings.TrimSpace(column), patchHeader[i]) {
+			return nil, fmt.Errorf("ReadPatch(): error - the first row of the patch should be the header %q", strings.Join(patchHeader, ","))
+		}
+	}
+
+	var edits []Edit
+	for row := 2; ; row++ {
+		columns, err := reader.Read()
+		if err == io.EOF {
+			return edits, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ReadPatch(): error - %v", err)
+		}
+
+		line, err := strconv.Atoi(strings.TrimSpace(columns[0]))
+		if err != nil || line < 1 {
+			return nil, fmt.Errorf("ReadPatch(): error - row %v of the patch: %q is not a valid line number", row, columns[0])
+		}
+		edits = append(edits, Edit{Line: line, Field: strings.TrimSpace(columns[1]), Value: columns[2]})
+	}
+}
+
+// PatchResult tells how many edits changed their lines and how many did not, as the fields already had the values
+type PatchResult struct {
+	Changed   int
+	Unchanged int
+}
+
+// ApplyPatch writes a file with the given edits applied, in their order. The values are encoded as told by
+// yamlconfig.Field.EncodeValue, so the width of the fields is kept. The lines without edits are written as they are.
+// The given function is called for each edit that changed its line.
+// An error is returned if an edit is on a line that does not exist, that does not match any record or whose record does not
+// have the field
+func ApplyPatch(records []yamlconfig.Record, file io.Reader, w io.Writer, edits []Edit, onChange func(Change) error) (PatchResult, error) {
+	editsByLine := map[int][]Edit{}
+	for _, edit := range edits {
+		editsByLine[edit.Line] = append(editsByLine[edit.Line], edit)
+	}
+
+	result := PatchResult{}
+	lines := 0
+	err := forEachLine(file, func(number int, line string, rawLine string) error {
+		lines = number
+		lineEdits, hasEdits := editsByLine[number]
+		if !hasEdits {
+			_, err := io.WriteString(w, rawLine)
+			return err
+		}
+
+		record, isRecordFound := yamlconfig.FindFirstRecordThatMatchesString(records, line)
+		if !isRecordFound {
+			return fmt.Errorf("ApplyPatch(): error - line %v does not match any record", number)
+		}
+
+		newLine := line
+		for _, edit := range lineEdits {
+			field, isFieldFound := record.FieldByName(edit.Field)
+			if !isFieldFound {
+				return fmt.Errorf("ApplyPatch(): error - line %v is of the record %q, which does not have the field %q", number, record.Name, edit.Field)
+			}
+
+			editedLine, change, err := setField(record, number, newLine, field, edit.Value)
+			if err != nil {
+				return fmt.Errorf("ApplyPatch(): error - %v", err)
+			}
+			if editedLine == newLine {
+				result.Unchanged++
+				continue
+			}
+			result.Changed++
+			if err = onChange(change); err != nil {
+				return err
+			}
+			newLine = editedLine
+		}
+		_, err := io.WriteString(w, newLine+rawLine[len(line):])
+		return err
+	})
+	if err != nil {
+		return PatchResult{}, err
+	}
+
+	for _, edit := range edits {
+		if edit.Line > lines {
+			return PatchResult{}, fmt.Errorf("ApplyPatch(): error - there is an edit on line %v, but the file has %v lines", edit.Line, lines)
+		}
+	}
+	return result, nil
+}
+
+// auditLogHeader is the header of the audit log
+var auditLogHeader = []string{"line", "record", "field", "old value", "new value"}
+
+// AuditLog writes the changes made on a file as a csv, whose first row is the header
+type AuditLog struct {
+	writer          *csv.Writer
+	isHeaderWritten bool
+}
+
+// NewAuditLog returns an AuditLog that writes to a given writer. Flush must be called after the last change
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{writer: csv.NewWriter(w)}
+}
+
+// Add writes a change to the audit log
+func (log *AuditLog) Add(change Change) error {
+	if !log.isHeaderWritten {
+		log.isHeaderWritten = true
+		if err := log.writer.Write(auditLogHeader); err != nil {
+			return err
+		}
+	}
+	return log.writer.Write([]string{strconv.Itoa(change.Line), change.Record, change.Field, change.OldValue, change.NewValue})
+}
+
+// Flush writes the header, if there were no changes, and the buffered changes
+func (log *AuditLog) Flush() error {
+	if !log.isHeaderWritten {
+		log.isHeaderWritten = true
+		if err := log.writer.Write(auditLogHeader); err != nil {
+			return err
+		}
+	}
+	log.writer.Flush()
+	return log.writer.Error()
+}
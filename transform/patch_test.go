@@ -0,0 +1,214 @@
+package transform
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/query"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name        string
+		field       string
+		value       string
+		where       string
+		want        string
+		wantResult  SetResult
+		wantChanges []Change
+		wantErr     bool
+	}{
+		{
+			name:       "Should set the field on the selected lines, keeping their width",
+			field:      "amount",
+			value:      "2.5",
+			where:      `id = "001"`,
+			want:       "H20210131\r\nD001John 00250\r\nXunknown\r\nD002Mary 00010",
+			wantResult: SetResult{Changed: 1},
+			wantChanges: []Change{
+				{Line: 2, Record: "detail", Field: "amount", OldValue: "00150", NewValue: "00250"},
+			},
+		},
+		{
+			name:       "Should set the field on all the lines whose records have it",
+			field:      "name",
+			value:      "Mary",
+			want:       "H20210131\r\nD001Mary 00150\r\nXunknown\r\nD002Mary 00010",
+			wantResult: SetResult{Changed: 1, Unchanged: 1, WithoutField: 1},
+			wantChanges: []Change{
+				{Line: 2, Record: "detail", Field: "name", OldValue: "John ", NewValue: "Mary "},
+			},
+		},
+		{
+			name:    "Should get error on values that do not fit on the field",
+			field:   "name",
+			value:   "Johnny",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var where query.Expression
+			if tt.where != "" {
+				var err error
+				if where, err = query.Parse(tt.where, testRecords); err != nil {
+					t.Fatalf("query.Parse() error = %v", err)
+				}
+			}
+
+			var buf bytes.Buffer
+			var changes []Change
+			result, err := Set(testRecords, strings.NewReader(testFile), &buf, tt.field, tt.value, where, func(change Change) error {
+				changes = append(changes, change)
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Set() wrote %q, want %q", got, tt.want)
+			}
+			if result != tt.wantResult {
+				t.Errorf("Set() = %v, want %v", result, tt.wantResult)
+			}
+			if !reflect.DeepEqual(changes, tt.wantChanges) {
+				t.Errorf("Set() changes = %v, want %v", changes, tt.wantChanges)
+			}
+		})
+	}
+}
+
+func TestReadPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		want    []Edit
+		wantErr bool
+	}{
+		{
+			name:  "Should read the edits",
+			patch: "line,field,value\n2,name,Jo\n4, amount ,\"1,5\"\n",
+			want:  []Edit{{Line: 2, Field: "name", Value: "Jo"}, {Line: 4, Field: "amount", Value: "1,5"}},
+		},
+		{
+			name:    "Should get error without the header",
+			patch:   "2,name,Jo\n",
+			wantErr: true,
+		},
+		{
+			name:    "Should get error on invalid line numbers",
+			patch:   "line,field,value\nx,name,Jo\n",
+			wantErr: true,
+		},
+		{
+			name:    "Should get error on missing columns",
+			patch:   "line,field,value\n2,name\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadPatch(strings.NewReader(tt.patch))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadPatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadPatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		edits      []Edit
+		want       string
+		wantResult PatchResult
+		wantErr    string
+	}{
+		{
+			name:       "Should apply the edits in order",
+			edits:      []Edit{{Line: 4, Field: "name", Value: "Ann"}, {Line: 2, Field: "amount", Value: "1"}, {Line: 4, Field: "name", Value: "Anna"}, {Line: 4, Field: "id", Value: "002"}},
+			want:       "H20210131\r\nD001John 00001\r\nXunknown\r\nD002Anna 00010",
+			wantResult: PatchResult{Changed: 3, Unchanged: 1},
+		},
+		{
+			name:    "Should get error on lines without record",
+			edits:   []Edit{{Line: 3, Field: "name", Value: "Ann"}},
+			wantErr: "ApplyPatch(): error - line 3 does not match any record",
+		},
+		{
+			name:    "Should get error on fields that the record does not have",
+			edits:   []Edit{{Line: 1, Field: "name", Value: "Ann"}},
+			wantErr: `ApplyPatch(): error - line 1 is of the record "header", which does not have the field "name"`,
+		},
+		{
+			name:    "Should get error on lines that do not exist",
+			edits:   []Edit{{Line: 9, Field: "name", Value: "Ann"}},
+			wantErr: "ApplyPatch(): error - there is an edit on line 9, but the file has 4 lines",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			result, err := ApplyPatch(testRecords, strings.NewReader(testFile), &buf, tt.edits, func(Change) error { return nil })
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ApplyPatch() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyPatch() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("ApplyPatch() wrote %q, want %q", got, tt.want)
+			}
+			if result != tt.wantResult {
+				t.Errorf("ApplyPatch() = %v, want %v", result, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []Change
+		want    string
+	}{
+		{
+			name:    "Should write the changes after the header",
+			changes: []Change{{Line: 2, Record: "detail", Field: "name", OldValue: "John ", NewValue: "Mary "}},
+			want:    "line,record,field,old value,new value\n2,detail,name,John ,Mary \n",
+		},
+		{
+			name: "Should write the header without changes",
+			want: "line,record,field,old value,new value\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := NewAuditLog(&buf)
+			for _, change := range tt.changes {
+				if err := log.Add(change); err != nil {
+					t.Fatalf("Add() error = %v", err)
+				}
+			}
+			if err := log.Flush(); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("AuditLog wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
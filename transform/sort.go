@@ -0,0 +1,381 @@
+package transform
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pedroppinheiro/fwf/extsort"
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// SortKey is a field used to sort the lines
+type SortKey struct {
+	Field        string
+	IsDescending bool
+}
+
+// ParseSortKeys parses a comma separated list of fields, each one optionally followed by "asc" or "desc",
+// e.g. "amount desc, name"
+func ParseSortKeys(list string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(list, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 {
+			return nil, fmt.Errorf("ParseSortKeys(): error - there is an empty field on %q", list)
+		}
+
+		key := SortKey{}
+		if order := strings.ToLower(words[len(words)-1]); len(words) > 1 && (order == "asc" || order == "desc") {
+			key.IsDescending = order == "desc"
+			words = words[:len(words)-1]
+		}
+		key.Field = strings.Join(words, " ")
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Collation tells how texts are compared
+type Collation string
+
+const (
+	// BinaryCollation compares texts by their bytes, so "B" comes before "a"
+	BinaryCollation Collation = "binary"
+
+	// NoCaseCollation compares texts ignoring their case
+	NoCaseCollation Collation = "nocase"
+
+	// TextCollation compares texts ignoring their case and the accents of latin letters, so "Ávila" comes before "Azevedo"
+	TextCollation Collation = "text"
+)
+
+// ParseCollation returns the Collation with the given name, or an error if there is none
+func ParseCollation(name string) (Collation, error) {
+	switch collation := Collation(name); collation {
+	case BinaryCollation, NoCaseCollation, TextCollation:
+		return collation, nil
+	}
+	return "", fmt.Errorf("ParseCollation(): error - unknown collation %q, it must be one of: binary, nocase, text", name)
+}
+
+// unaccentedLetters maps the accented latin letters to the letters without accents
+var unaccentedLetters = map[rune]rune{}
+
+func init() {
+	for letter, accentedLetters := range map[rune]string{
+		'a': "àáâãäå", 'c': "ç", 'e': "èéêë", 'i': "ìíîï", 'n': "ñ", 'o': "òóôõö", 'u': "ùúûü", 'y': "ýÿ",
+	} {
+		for _, accentedLetter := range accentedLetters {
+			unaccentedLetters[accentedLetter] = letter
+		}
+	}
+}
+
+// collate returns a text that is compared by its bytes as the given text is compared by the collation
+func collate(text string, collation Collation) string {
+	switch collation {
+	case NoCaseCollation:
+		return strings.ToLower(text)
+	case TextCollation:
+		return strings.Map(func(r rune) rune {
+			if letter, isAccented := unaccentedLetters[r]; isAccented {
+				return letter
+			}
+			return r
+		}, strings.ToLower(text))
+	}
+	return text
+}
+
+// SortOptions tells how the lines of a file are sorted
+type SortOptions struct {
+	// Keys are the fields used to sort the lines, in order
+	Keys []SortKey
+
+	// Records are the names of the records whose lines are sorted. When empty, the lines of all the records that have all the
+	// key fields are sorted
+	Records []string
+
+	// Collation tells how the values of string fields are compared
+	Collation Collation
+
+	// WithinBatches sorts each group of consecutive lines to be sorted on its own, so the lines of other records, such as the
+	// headers and trailers of batches, separate the groups
+	WithinBatches bool
+
+	// MaxBytesInMemory is the maximum number of bytes of lines kept in memory, see extsort.NewSorter
+	MaxBytesInMemory int
+}
+
+// SortResult tells how many lines were sorted and how many were kept in place
+type SortResult struct {
+	SortedLines   int
+	AnchoredLines int
+}
+
+// Sort writes the lines of a file sorted by the values of the key fields, with an external sort so that the file does not
+// have to fit in memory. Lines with the same values keep their order. Integers and decimals are compared as numbers,
+// dates as dates and the other fields as texts, without the spaces around them and as told by the collation.
+// Blank values, and values that are not valid for their types, come before the other values, or after them when descending.
+// The lines of the other records, and the lines without record, are kept in place: when sorting within batches, they separate
+// the groups of lines that are sorted, otherwise the lines before and after the sorted lines are kept at the start and at
+// the end of the file, and an error is returned if there is any of them between the lines to be sorted.
+// Lines are written with the line break of the first line of the file, and the last line keeps its line break, if any
+func Sort(records []yamlconfig.Record, file io.Reader, w io.Writer, options SortOptions) (SortResult, error) {
+	isSorted, err := recordsToSort(records, options)
+	if err != nil {
+		return SortResult{}, err
+	}
+
+	output := &lineBreakWriter{writer: w}
+	result := SortResult{}
+	var sorter *extsort.Sorter
+	defer func() {
+		if sorter != nil {
+			sorter.Close()
+		}
+	}()
+	var lastSortedLine int
+	var lineAfterSortedLines int
+
+	sortLines := func() error {
+		if sorter == nil {
+			return nil
+		}
+		iterator, err := sorter.Sort()
+		if err != nil {
+			return err
+		}
+		defer iterator.Close()
+		for iterator.Next() {
+			if err = output.writeLine(iterator.Entry().Line); err != nil {
+				return err
+			}
+		}
+		sorter = nil
+		return iterator.Err()
+	}
+
+	var anchoredLines []string
+	err = forEachLine(file, func(number int, line string, rawLine string) error {
+		output.setLineBreaks(rawLine[len(line):])
+
+		record, isRecordFound := yamlconfig.FindFirstRecordThatMatchesString(records, line)
+		if !isRecordFound || !isSorted[record.Name] {
+			result.AnchoredLines++
+			switch {
+			case options.WithinBatches:
+				if err := sortLines(); err != nil {
+					return err
+				}
+				return output.writeLine(line)
+			case sorter == nil:
+				return output.writeLine(line)
+			}
+			if lineAfterSortedLines == 0 {
+				lineAfterSortedLines = number
+			}
+			anchoredLines = append(anchoredLines, line)
+			return nil
+		}
+
+		if lineAfterSortedLines != 0 {
+			return fmt.Errorf("Sort(): error - line %v, which is not sorted, is between the lines to be sorted %v and %v, sort within batches to keep it in place", lineAfterSortedLines, lastSortedLine, number)
+		}
+		if sorter == nil {
+			sorter = extsort.NewSorter(options.MaxBytesInMemory)
+		}
+		result.SortedLines++
+		lastSortedLine = number
+		return sorter.Add(extsort.Entry{Key: sortKey(record, line, options), Number: number, Line: line})
+	})
+	if err != nil {
+		return SortResult{}, err
+	}
+
+	if err = sortLines(); err != nil {
+		return SortResult{}, err
+	}
+	for _, line := range anchoredLines {
+		if err = output.writeLine(line); err != nil {
+			return SortResult{}, err
+		}
+	}
+	return result, output.close()
+}
+
+// recordsToSort returns the names of the records whose lines are sorted. An error is returned if any of them does not have a
+// key field, or if a key field has different types on them
+func recordsToSort(records []yamlconfig.Record, options SortOptions) (map[string]bool, error) {
+	if len(options.Keys) == 0 {
+		return nil, fmt.Errorf("Sort(): error - no field was given to sort the lines")
+	}
+
+	isSorted := map[string]bool{}
+	for _, recordName := range options.Records {
+		isSorted[recordName] = true
+	}
+	for recordName := range isSorted {
+		if _, isFound := findRecordByName(records, recordName); !isFound {
+			return nil, fmt.Errorf("Sort(): error - there is no record %q", recordName)
+		}
+	}
+
+	typeByField := map[string]yamlconfig.FieldType{}
+	for _, record := range records {
+		hasAllKeys := true
+		for _, key := range options.Keys {
+			if _, isFound := record.FieldByName(key.Field); !isFound {
+				hasAllKeys = false
+			}
+		}
+
+		if len(options.Records) == 0 && hasAllKeys {
+			isSorted[record.Name] = true
+		}
+		if !isSorted[record.Name] {
+			continue
+		}
+		if !hasAllKeys {
+			return nil, fmt.Errorf("Sort(): error - the record %q does not have all the fields used to sort the lines", record.Name)
+		}
+
+		for _, key := range options.Keys {
+			field, _ := record.FieldByName(key.Field)
+			if fieldType, isTyped := typeByField[key.Field]; isTyped && fieldType != sortType(field) {
+				return nil, fmt.Errorf("Sort(): error - the field %q has different types on the records to be sorted", key.Field)
+			}
+			typeByField[key.Field] = sortType(field)
+		}
+	}
+
+	if len(isSorted) == 0 {
+		return nil, fmt.Errorf("Sort(): error - no record has all the fields used to sort the lines")
+	}
+	return isSorted, nil
+}
+
+// findRecordByName returns the record with the given name
+func findRecordByName(records []yamlconfig.Record, name string) (yamlconfig.Record, bool) {
+	for _, record := range records {
+		if record.Name == name {
+			return record, true
+		}
+	}
+	return yamlconfig.Record{}, false
+}
+
+// sortType returns the type used to compare the values of a field
+func sortType(field yamlconfig.Field) yamlconfig.FieldType {
+	if field.Type == "" {
+		return yamlconfig.StringType
+	}
+	return field.Type
+}
+
+// sortKey returns a key whose bytes are ordered as the values of the key fields of a line should be sorted
+func sortKey(record yamlconfig.Record, line string, options SortOptions) string {
+	var key []byte
+	for _, sortKey := range options.Keys {
+		field, _ := record.FieldByName(sortKey.Field)
+		part := encodeSortValue(field, field.RawValue(line), options.Collation)
+		if sortKey.IsDescending {
+			for i := range part {
+				part[i] = ^part[i]
+			}
+		}
+		key = append(key, part...)
+	}
+	return string(key)
+}
+
+// encodeSortValue encodes a value so that its bytes are ordered as the values are. Blank and invalid values are a single
+// 0 byte, and the other values start with 1. Texts end with 0 followed by 1, and their 0 bytes are followed by 255, so that
+// a text comes before the texts that start with it. Values are decoded by their types only, so that unknown values and
+// values with wrong check digits are sorted like the others
+func encodeSortValue(field yamlconfig.Field, rawValue string, collation Collation) []byte {
+	value, err := field.ParseTypedValue(rawValue)
+	if err != nil || value == nil {
+		return []byte{0}
+	}
+
+	encoded := []byte{1}
+	switch typedValue := value.(type) {
+	case int64:
+		return appendUint64(encoded, uint64(typedValue)^(1<<63))
+	case float64:
+		return appendUint64(encoded, orderedFloatBits(typedValue))
+	case time.Time:
+		encoded = appendUint64(encoded, uint64(typedValue.Unix())^(1<<63))
+		return appendUint64(encoded, uint64(typedValue.Nanosecond()))
+	default:
+		text := strings.TrimSpace(rawValue)
+		if text == "" {
+			return []byte{0}
+		}
+		for _, b := range []byte(collate(text, collation)) {
+			encoded = append(encoded, b)
+			if b == 0 {
+				encoded = append(encoded, 255)
+			}
+		}
+		return append(encoded, 0, 1)
+	}
+}
+
+// appendUint64 appends the bytes of a number, from the most significant one
+func appendUint64(encoded []byte, number uint64) []byte {
+	bytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(bytes, number)
+	return append(encoded, bytes...)
+}
+
+// orderedFloatBits returns the bits of a number in an order that is the same as the order of the numbers
+func orderedFloatBits(number float64) uint64 {
+	bits := math.Float64bits(number)
+	if number < 0 {
+		return ^bits
+	}
+	return bits | (1 << 63)
+}
+
+// lineBreakWriter writes lines with the line break of the file between them, and the line break of the last line of the file
+// after the last line
+type lineBreakWriter struct {
+	writer        io.Writer
+	lineBreak     string
+	lastLineBreak string
+	hasLines      bool
+}
+
+// setLineBreaks takes the line break of a line of the file, in order
+func (w *lineBreakWriter) setLineBreaks(lineBreak string) {
+	if w.lineBreak == "" {
+		w.lineBreak = lineBreak
+	}
+	w.lastLineBreak = lineBreak
+}
+
+func (w *lineBreakWriter) writeLine(line string) error {
+	if w.hasLines {
+		if _, err := io.WriteString(w.writer, w.lineBreak); err != nil {
+			return err
+		}
+	}
+	w.hasLines = true
+	_, err := io.WriteString(w.writer, line)
+	return err
+}
+
+// close writes the line break of the last line
+func (w *lineBreakWriter) close() error {
+	if !w.hasLines {
+		return nil
+	}
+	_, err := io.WriteString(w.writer, w.lastLineBreak)
+	return err
+}
@@ -0,0 +1,158 @@
+package transform
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSortKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []SortKey
+		wantErr bool
+	}{
+		{
+			name: "Should parse the fields and their orders",
+			list: "amount desc, name,id ASC",
+			want: []SortKey{{Field: "amount", IsDescending: true}, {Field: "name"}, {Field: "id"}},
+		},
+		{
+			name: "Should keep the spaces inside the names of the fields",
+			list: "due date desc",
+			want: []SortKey{{Field: "due date", IsDescending: true}},
+		},
+		{
+			name: "Should take a single word as the name of the field",
+			list: "desc",
+			want: []SortKey{{Field: "desc"}},
+		},
+		{
+			name:    "Should return an error for an empty field",
+			list:    "amount,,name",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSortKeys(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSortKeys() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSortKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		options    SortOptions
+		want       string
+		wantResult SortResult
+		wantErr    bool
+	}{
+		{
+			name:       "Should sort the details by a number keeping the header and the trailer in place",
+			file:       "H20210131\r\nD001John 00150\r\nD002Mary 00010\r\nD003Ana  00150\r\nT3\r\n",
+			options:    SortOptions{Keys: []SortKey{{Field: "amount", IsDescending: true}}, Collation: BinaryCollation},
+			want:       "H20210131\r\nD001John 00150\r\nD003Ana  00150\r\nD002Mary 00010\r\nT3\r\n",
+			wantResult: SortResult{SortedLines: 3, AnchoredLines: 2},
+		},
+		{
+			name:       "Should sort negative numbers before positive ones and blank values first",
+			file:       "D001John 00005\nD002Mary -0010\nD003Ana       \nD004Bob  00100",
+			options:    SortOptions{Keys: []SortKey{{Field: "amount"}}, Collation: BinaryCollation},
+			want:       "D003Ana       \nD002Mary -0010\nD001John 00005\nD004Bob  00100",
+			wantResult: SortResult{SortedLines: 4},
+		},
+		{
+			name:       "Should sort by the next keys when the values of the first ones are the same",
+			file:       "D002Mary 00010\nD001John 00010\nD003Ana  00020\n",
+			options:    SortOptions{Keys: []SortKey{{Field: "amount", IsDescending: true}, {Field: "id"}}, Collation: BinaryCollation},
+			want:       "D003Ana  00020\nD001John 00010\nD002Mary 00010\n",
+			wantResult: SortResult{SortedLines: 3},
+		},
+		{
+			name:       "Should sort texts by their bytes with the binary collation",
+			file:       "D001bruno00010\nD002Ávila00010\nD003Alice00010",
+			options:    SortOptions{Keys: []SortKey{{Field: "name"}}, Collation: BinaryCollation},
+			want:       "D003Alice00010\nD001bruno00010\nD002Ávila00010",
+			wantResult: SortResult{SortedLines: 3},
+		},
+		{
+			name:       "Should sort texts ignoring their case with the nocase collation",
+			file:       "D001bruno00010\nD002Ávila00010\nD003Alice00010",
+			options:    SortOptions{Keys: []SortKey{{Field: "name"}}, Collation: NoCaseCollation},
+			want:       "D003Alice00010\nD001bruno00010\nD002Ávila00010",
+			wantResult: SortResult{SortedLines: 3},
+		},
+		{
+			name:       "Should sort texts ignoring their case and accents with the text collation",
+			file:       "D001bruno00010\nD002Ávila00010\nD003Alice00010",
+			options:    SortOptions{Keys: []SortKey{{Field: "name"}}, Collation: TextCollation},
+			want:       "D003Alice00010\nD002Ávila00010\nD001bruno00010",
+			wantResult: SortResult{SortedLines: 3},
+		},
+		{
+			name:       "Should sort within batches keeping the headers in place",
+			file:       "H20210131\nD002Mary 00010\nD001John 00010\nH20210201\nD004Bob  00010\nD003Ana  00010\n",
+			options:    SortOptions{Keys: []SortKey{{Field: "name"}}, Collation: BinaryCollation, WithinBatches: true},
+			want:       "H20210131\nD001John 00010\nD002Mary 00010\nH20210201\nD003Ana  00010\nD004Bob  00010\n",
+			wantResult: SortResult{SortedLines: 4, AnchoredLines: 2},
+		},
+		{
+			name:       "Should sort the lines of the given records",
+			file:       "H20210201\nD002Mary 00010\nH20210131\n",
+			options:    SortOptions{Keys: []SortKey{{Field: "type"}}, Records: []string{"header"}, Collation: BinaryCollation, WithinBatches: true},
+			want:       "H20210201\nD002Mary 00010\nH20210131\n",
+			wantResult: SortResult{SortedLines: 2, AnchoredLines: 1},
+		},
+		{
+			name:    "Should return an error when a line that is not sorted is between the lines to be sorted",
+			file:    "H20210131\nD002Mary 00010\nH20210201\nD001John 00010\n",
+			options: SortOptions{Keys: []SortKey{{Field: "name"}}, Collation: BinaryCollation},
+			wantErr: true,
+		},
+		{
+			name:    "Should return an error when a record to be sorted does not have the fields",
+			file:    "H20210131\n",
+			options: SortOptions{Keys: []SortKey{{Field: "name"}}, Records: []string{"header"}, Collation: BinaryCollation},
+			wantErr: true,
+		},
+		{
+			name:    "Should return an error when no record has the fields",
+			file:    "H20210131\n",
+			options: SortOptions{Keys: []SortKey{{Field: "unknown"}}, Collation: BinaryCollation},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, maxBytesInMemory := range []int{1024 * 1024, 1} {
+				tt.options.MaxBytesInMemory = maxBytesInMemory
+				output := &bytes.Buffer{}
+				got, err := Sort(testRecords, strings.NewReader(tt.file), output, tt.options)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("Sort() error = %v, wantErr %v", err, tt.wantErr)
+					return
+				}
+				if tt.wantErr {
+					return
+				}
+				if output.String() != tt.want {
+					t.Errorf("Sort() with %v bytes in memory wrote %q, want %q", maxBytesInMemory, output.String(), tt.want)
+				}
+				if !reflect.DeepEqual(got, tt.wantResult) {
+					t.Errorf("Sort() = %v, want %v", got, tt.wantResult)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package transform
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// SplitResult tells how many lines were written for each record, by the name of the record, and how many lines did not
+// match any record
+type SplitResult struct {
+	LinesByRecord  map[string]int
+	UnmatchedLines int
+}
+
+// Split writes the lines of each record to its own writer, exactly as they are on the file, line breaks included.
+// The writer of a record is obtained with the given function the first time a line of the record is found, so that only
+// the records that are on the file have writers. The writer of the lines that do not match any record, when they are
+// written, is obtained with a nil record
+func Split(records []yamlconfig.Record, file io.Reader, open func(record *yamlconfig.Record) (io.Writer, error), unmatched Unmatched) (SplitResult, error) {
+	result := SplitResult{LinesByRecord: map[string]int{}}
+	writers := map[int]io.Writer{}
+
+	err := forEachLine(file, func(number int, line string, rawLine string) error {
+		recordIndex, isRecordFound := yamlconfig.FindFirstRecordIndexThatMatchesString(records, line)
+		if !isRecordFound {
+			result.UnmatchedLines++
+			switch unmatched {
+			case SkipUnmatched:
+				return nil
+			case FailOnUnmatched:
+				return fmt.Errorf("Split(): error - line %v does not match any record", number)
+			}
+			recordIndex = -1
+		}
+
+		writer, isOpen := writers[recordIndex]
+		if !isOpen {
+			var record *yamlconfig.Record
+			if recordIndex >= 0 {
+				record = &records[recordIndex]
+			}
+
+			var err error
+			if writer, err = open(record); err != nil {
+				return err
+			}
+			writers[recordIndex] = writer
+		}
+
+		if isRecordFound {
+			result.LinesByRecord[records[recordIndex].Name]++
+		}
+		_, err := io.WriteString(writer, rawLine)
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,98 @@
+package transform
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+var testRecords = []yamlconfig.Record{
+	{
+		Name:   "header",
+		Regex:  yamlconfig.MustCreateRegex("^H"),
+		Fields: []yamlconfig.Field{{Name: "type", Initial: 1, End: 1}, {Name: "date", Initial: 2, End: 9}},
+	},
+	{
+		Name:  "detail",
+		Regex: yamlconfig.MustCreateRegex("^D"),
+		Fields: []yamlconfig.Field{
+			{Name: "type", Initial: 1, End: 1},
+			{Name: "id", Initial: 2, End: 4},
+			{Name: "name", Initial: 5, End: 9},
+			{Name: "amount", Initial: 10, End: 14, Type: yamlconfig.DecimalType, Decimals: 2},
+		},
+	},
+}
+
+var testFile = "H20210131\r\nD001John 00150\r\nXunknown\r\nD002Mary 00010"
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name      string
+		unmatched Unmatched
+		want      map[string]string
+		wantLines SplitResult
+		wantErr   bool
+	}{
+		{
+			name:      "Should write the lines of each record as they are",
+			unmatched: WriteUnmatched,
+			want: map[string]string{
+				"header":    "H20210131\r\n",
+				"detail":    "D001John 00150\r\nD002Mary 00010",
+				"unmatched": "Xunknown\r\n",
+			},
+			wantLines: SplitResult{LinesByRecord: map[string]int{"header": 1, "detail": 2}, UnmatchedLines: 1},
+		},
+		{
+			name:      "Should skip the lines that do not match any record",
+			unmatched: SkipUnmatched,
+			want: map[string]string{
+				"header": "H20210131\r\n",
+				"detail": "D001John 00150\r\nD002Mary 00010",
+			},
+			wantLines: SplitResult{LinesByRecord: map[string]int{"header": 1, "detail": 2}, UnmatchedLines: 1},
+		},
+		{
+			name:      "Should fail on the lines that do not match any record",
+			unmatched: FailOnUnmatched,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffers := map[string]*bytes.Buffer{}
+			open := func(record *yamlconfig.Record) (io.Writer, error) {
+				name := "unmatched"
+				if record != nil {
+					name = record.Name
+				}
+				buffers[name] = &bytes.Buffer{}
+				return buffers[name], nil
+			}
+
+			result, err := Split(testRecords, strings.NewReader(testFile), open, tt.unmatched)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Split() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got := map[string]string{}
+			for name, buffer := range buffers {
+				got[name] = buffer.String()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split() wrote %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(result, tt.wantLines) {
+				t.Errorf("Split() = %v, want %v", result, tt.wantLines)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package transform
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Unmatched tells what to do with the lines that can not be transformed, such as the lines that do not match any record
+type Unmatched string
+
+const (
+	// WriteUnmatched writes the lines that can not be transformed, as they are, to a separate file
+	WriteUnmatched Unmatched = "file"
+
+	// SkipUnmatched ignores the lines that can not be transformed, which are only counted
+	SkipUnmatched Unmatched = "skip"
+
+	// FailOnUnmatched stops with an error on the first line that can not be transformed
+	FailOnUnmatched Unmatched = "error"
+)
+
+// ParseUnmatched returns the Unmatched with the given name, or an error if there is none
+func ParseUnmatched(name string) (Unmatched, error) {
+	switch unmatched := Unmatched(name); unmatched {
+	case WriteUnmatched, SkipUnmatched, FailOnUnmatched:
+		return unmatched, nil
+	}
+	return "", fmt.Errorf("ParseUnmatched(): error - unknown option %q, it must be one of: file, skip, error", name)
+}
+
+// forEachLine calls the given function for each line of a file, with its number, starting at 1, its content, without the
+// line break, and the line as it is on the file, with its line break if it has one
+func forEachLine(file io.Reader, handle func(number int, line string, rawLine string) error) error {
+	reader := bufio.NewReader(file)
+	for number := 1; ; number++ {
+		rawLine, err := reader.ReadString('\n')
+		if rawLine != "" {
+			if handleErr := handle(number, strings.TrimRight(rawLine, "\r\n"), rawLine); handleErr != nil {
+				return handleErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// Run shows the given lines on an interactive terminal visualization until the user quits it.
+// The cursor is moved with the arrow keys (or h, j, k and l), page up and page down, home and end, and "q" quits.
+// The terminal is set to raw mode with "stty", so it must be run on a unix like terminal
+func Run(records []yamlconfig.Record, lines []string, terminal *os.File) error {
+	width, height, err := terminalSize(terminal)
+	if err != nil {
+		return err
+	}
+
+	previousState, err := stty(terminal, "-g")
+	if err != nil {
+		return err
+	}
+	if _, err = stty(terminal, "raw", "-echo"); err != nil {
+		return err
+	}
+	defer func() {
+		stty(terminal, strings.TrimSpace(previousState))
+		fmt.Fprint(terminal, "\x1b[?25h\x1b[2J\x1b[H")
+	}()
+
+	fmt.Fprint(terminal, "\x1b[?25l\x1b[2J")
+	viewer := newViewer(records, lines, width, height)
+	reader := bufio.NewReader(terminal)
+	for {
+		fmt.Fprint(terminal, viewer.render())
+
+		pressedKey, err := readKey(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !viewer.handleKey(pressedKey) {
+			return nil
+		}
+	}
+}
+
+// stty runs the "stty" command with the given arguments on a given terminal and returns its output
+func stty(terminal *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = terminal
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("stty %v: %v", strings.Join(args, " "), err)
+	}
+	return string(output), nil
+}
+
+// terminalSize returns the number of columns and rows of a given terminal
+func terminalSize(terminal *os.File) (width int, height int, err error) {
+	output, err := stty(terminal, "size")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if _, err = fmt.Sscan(output, &height, &width); err != nil {
+		return 0, 0, fmt.Errorf("could not read the terminal size %q: %v", output, err)
+	}
+	return width, height, nil
+}
+
+// readKey reads the next key pressed by the user, including the escape sequences of the arrow and page keys
+func readKey(reader *bufio.Reader) (key, error) {
+	r, _, err := reader.ReadRune()
+	if err != nil {
+		return keyUnknown, err
+	}
+
+	switch r {
+	case 'q', 'Q', 3: // 3 is ctrl+c, which does not send a signal in raw mode
+		return keyQuit, nil
+	case 'k':
+		return keyUp, nil
+	case 'j':
+		return keyDown, nil
+	case 'h':
+		return keyLeft, nil
+	case 'l':
+		return keyRight, nil
+	case '\x1b':
+		return readEscapeSequence(reader)
+	}
+	return keyUnknown, nil
+}
+
+// readEscapeSequence reads the rest of an escape sequence, after the escape character was read
+func readEscapeSequence(reader *bufio.Reader) (key, error) {
+	if reader.Buffered() == 0 {
+		return keyQuit, nil
+	}
+
+	sequence := ""
+	for reader.Buffered() > 0 {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			return keyUnknown, err
+		}
+		sequence += string(r)
+		if len(sequence) > 1 && (r >= 'A' && r <= 'Z' || r == '~') {
+			break
+		}
+	}
+
+	switch sequence {
+	case "[A", "OA":
+		return keyUp, nil
+	case "[B", "OB":
+		return keyDown, nil
+	case "[C", "OC":
+		return keyRight, nil
+	case "[D", "OD":
+		return keyLeft, nil
+	case "[5~":
+		return keyPageUp, nil
+	case "[6~":
+		return keyPageDown, nil
+	case "[H", "[1~", "OH":
+		return keyHome, nil
+	case "[F", "[4~", "OF":
+		return keyEnd, nil
+	}
+	return keyUnknown, nil
+}
@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func Test_readKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  key
+	}{
+		{"Should read the up arrow", "\x1b[A", keyUp},
+		{"Should read the down arrow", "\x1b[B", keyDown},
+		{"Should read the right arrow", "\x1b[C", keyRight},
+		{"Should read the left arrow", "\x1b[D", keyLeft},
+		{"Should read page up", "\x1b[5~", keyPageUp},
+		{"Should read page down", "\x1b[6~", keyPageDown},
+		{"Should read home", "\x1b[H", keyHome},
+		{"Should read end", "\x1b[F", keyEnd},
+		{"Should read vi keys", "j", keyDown},
+		{"Should quit with q", "q", keyQuit},
+		{"Should quit with a lone escape", "\x1b", keyQuit},
+		{"Should ignore other keys", "x", keyUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readKey(bufio.NewReader(strings.NewReader(tt.input)))
+			if err != nil {
+				t.Errorf("readKey() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("readKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/exporter"
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+const (
+	// sidePanelWidth is the width of the panel that shows the field under the cursor, including its border
+	sidePanelWidth = 34
+
+	// gutterWidth is the width of the line numbers column, including its border
+	gutterWidth = 8
+
+	ansiReverse   = "\x1b[7m"
+	ansiReset     = "\x1b[0m"
+	ansiClearLine = "\x1b[K"
+)
+
+// key represents a key pressed by the user
+type key int
+
+const (
+	keyUnknown key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyQuit
+)
+
+// viewer holds the state of the terminal visualization of a file: the lines, the position of the cursor
+// and which part of the file is currently visible
+type viewer struct {
+	records []yamlconfig.Record
+	lines   []string
+
+	// cursorLine and cursorColumn start at 0
+	cursorLine   int
+	cursorColumn int
+
+	// topLine and leftColumn are the first visible line and column, starting at 0
+	topLine    int
+	leftColumn int
+
+	width  int
+	height int
+}
+
+// newViewer returns a viewer of the given lines for a terminal of the given size
+func newViewer(records []yamlconfig.Record, lines []string, width int, height int) *viewer {
+	return &viewer{records: records, lines: lines, width: width, height: height}
+}
+
+// textWidth returns how many columns of the file are visible at once
+func (viewer *viewer) textWidth() int {
+	width := viewer.width - gutterWidth - sidePanelWidth
+	if width < 1 {
+		return 1
+	}
+	return width
+}
+
+// textHeight returns how many lines of the file are visible at once. The first line of the terminal is used by the ruler
+func (viewer *viewer) textHeight() int {
+	if viewer.height < 2 {
+		return 1
+	}
+	return viewer.height - 1
+}
+
+// lineLength returns the number of columns of the line of the given index
+func (viewer *viewer) lineLength(index int) int {
+	if index < 0 || index >= len(viewer.lines) {
+		return 0
+	}
+	return len([]rune(viewer.lines[index]))
+}
+
+// handleKey moves the cursor according to a given key. It returns false if the viewer should be closed
+func (viewer *viewer) handleKey(pressedKey key) bool {
+	switch pressedKey {
+	case keyQuit:
+		return false
+	case keyUp:
+		viewer.cursorLine--
+	case keyDown:
+		viewer.cursorLine++
+	case keyLeft:
+		viewer.cursorColumn--
+	case keyRight:
+		viewer.cursorColumn++
+	case keyPageUp:
+		viewer.cursorLine -= viewer.textHeight()
+	case keyPageDown:
+		viewer.cursorLine += viewer.textHeight()
+	case keyHome:
+		viewer.cursorColumn = 0
+	case keyEnd:
+		viewer.cursorColumn = viewer.lineLength(viewer.cursorLine) - 1
+	}
+	viewer.clampCursor()
+	viewer.scrollToCursor()
+	return true
+}
+
+// clampCursor keeps the cursor inside the file
+func (viewer *viewer) clampCursor() {
+	if viewer.cursorLine >= len(viewer.lines) {
+		viewer.cursorLine = len(viewer.lines) - 1
+	}
+	if viewer.cursorLine < 0 {
+		viewer.cursorLine = 0
+	}
+
+	maxColumn := viewer.lineLength(viewer.cursorLine) - 1
+	if viewer.cursorColumn > maxColumn {
+		viewer.cursorColumn = maxColumn
+	}
+	if viewer.cursorColumn < 0 {
+		viewer.cursorColumn = 0
+	}
+}
+
+// scrollToCursor changes the visible part of the file so that the cursor is always visible
+func (viewer *viewer) scrollToCursor() {
+	if viewer.cursorLine < viewer.topLine {
+		viewer.topLine = viewer.cursorLine
+	}
+	if viewer.cursorLine >= viewer.topLine+viewer.textHeight() {
+		viewer.topLine = viewer.cursorLine - viewer.textHeight() + 1
+	}
+
+	if viewer.cursorColumn < viewer.leftColumn {
+		viewer.leftColumn = viewer.cursorColumn
+	}
+	if viewer.cursorColumn >= viewer.leftColumn+viewer.textWidth() {
+		viewer.leftColumn = viewer.cursorColumn - viewer.textWidth() + 1
+	}
+}
+
+// fieldsOfLine returns the record of the line with the given index and its fields sorted by their positions
+func (viewer *viewer) fieldsOfLine(index int) (yamlconfig.Record, []yamlconfig.Field, bool) {
+	if index < 0 || index >= len(viewer.lines) {
+		return yamlconfig.Record{}, nil, false
+	}
+
+	record, isRecordFound := yamlconfig.FindFirstRecordThatMatchesString(viewer.records, viewer.lines[index])
+	if !isRecordFound {
+		return record, nil, false
+	}
+
+	fields := make([]yamlconfig.Field, len(record.Fields))
+	copy(fields, record.Fields)
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Initial < fields[j].Initial
+	})
+	return record, fields, true
+}
+
+// fieldIndexOnColumn returns the index of the field, in a slice sorted by positions, that contains
+// the given column (starting at 0), or -1 if no field contains it
+func fieldIndexOnColumn(fields []yamlconfig.Field, column int) int {
+	position := column + 1
+	for i, field := range fields {
+		if field.Initial <= position && position <= field.End {
+			return i
+		}
+	}
+	return -1
+}
+
+// ruler returns the column ruler, like "....+....1....+....2", starting at the first visible column
+func (viewer *viewer) ruler() string {
+	var builder strings.Builder
+	for column := viewer.leftColumn; column < viewer.leftColumn+viewer.textWidth(); column++ {
+		position := column + 1
+		mark := "."
+		if position%10 == 0 {
+			mark = fmt.Sprint((position / 10) % 10)
+		} else if position%5 == 0 {
+			mark = "+"
+		}
+
+		if column == viewer.cursorColumn {
+			mark = ansiReverse + mark + ansiReset
+		}
+		builder.WriteString(mark)
+	}
+	return builder.String()
+}
+
+// renderLine returns the visible part of the line of the given index, with each field colored and the cursor highlighted
+func (viewer *viewer) renderLine(index int) string {
+	runes := []rune(viewer.lines[index])
+	_, fields, _ := viewer.fieldsOfLine(index)
+
+	var builder strings.Builder
+	currentStyle := ""
+	for column := viewer.leftColumn; column < viewer.leftColumn+viewer.textWidth() && column < len(runes); column++ {
+		style := ""
+		if fieldIndex := fieldIndexOnColumn(fields, column); fieldIndex != -1 {
+			style = exporter.FieldColor(fieldIndex)
+		}
+		if index == viewer.cursorLine && column == viewer.cursorColumn {
+			style += ansiReverse
+		}
+
+		if style != currentStyle {
+			builder.WriteString(ansiReset + style)
+			currentStyle = style
+		}
+
+		character := runes[column]
+		if character == '\t' {
+			character = ' '
+		}
+		builder.WriteRune(exporter.ControlPicture(character))
+	}
+	if currentStyle != "" {
+		builder.WriteString(ansiReset)
+	}
+
+	visibleColumns := len(runes) - viewer.leftColumn
+	if visibleColumns < 0 {
+		visibleColumns = 0
+	}
+	if visibleColumns < viewer.textWidth() {
+		builder.WriteString(strings.Repeat(" ", viewer.textWidth()-visibleColumns))
+	}
+	return builder.String()
+}
+
+// sidePanel returns the lines of the panel that describes the field under the cursor
+func (viewer *viewer) sidePanel() []string {
+	panel := []string{
+		fmt.Sprintf("Line %v, column %v", viewer.cursorLine+1, viewer.cursorColumn+1),
+		"",
+	}
+
+	record, fields, isRecordFound := viewer.fieldsOfLine(viewer.cursorLine)
+	if !isRecordFound {
+		return append(panel, "No record matches this line")
+	}
+	panel = append(panel, "Record: "+exporter.ReplaceControlCharacters(record.Name))
+
+	fieldIndex := fieldIndexOnColumn(fields, viewer.cursorColumn)
+	if fieldIndex == -1 {
+		return append(panel, "No field on this column")
+	}
+
+	field := fields[fieldIndex]
+	return append(panel,
+		"Field: "+exporter.ReplaceControlCharacters(field.Name),
+		fmt.Sprintf("Positions: %v-%v", field.Initial, field.End),
+		fmt.Sprintf("Length: %v", field.End-field.Initial+1),
+		"Value: ",
+		"["+exporter.ReplaceControlCharacters(field.RawValue(viewer.lines[viewer.cursorLine]))+"]",
+	)
+}
+
+// fitToWidth cuts or pads a string without escape codes to exactly the given width
+func fitToWidth(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+// render returns the whole screen: the ruler, the visible lines with their numbers and the side panel
+func (viewer *viewer) render() string {
+	var builder strings.Builder
+	builder.WriteString("\x1b[H")
+
+	panel := viewer.sidePanel()
+	panelLine := func(i int) string {
+		if i < len(panel) {
+			return fitToWidth(panel[i], sidePanelWidth-2)
+		}
+		return fitToWidth("", sidePanelWidth-2)
+	}
+
+	builder.WriteString(strings.Repeat(" ", gutterWidth) + viewer.ruler() + " |" + panelLine(0) + ansiClearLine + "\r\n")
+	for i := 0; i < viewer.textHeight(); i++ {
+		index := viewer.topLine + i
+		if index < len(viewer.lines) {
+			builder.WriteString(fmt.Sprintf("%6v |", index+1) + viewer.renderLine(index))
+		} else {
+			builder.WriteString(strings.Repeat(" ", gutterWidth+viewer.textWidth()))
+		}
+		builder.WriteString(" |" + panelLine(i+1) + ansiClearLine)
+		if i < viewer.textHeight()-1 {
+			builder.WriteString("\r\n")
+		}
+	}
+	return builder.String()
+}
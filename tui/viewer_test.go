@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+var testRecords = []yamlconfig.Record{
+	{
+		Name:  "record A",
+		Regex: yamlconfig.MustCreateRegex("^A"),
+		Fields: []yamlconfig.Field{
+			{Name: "field 1", Initial: 1, End: 1},
+			{Name: "field 2", Initial: 2, End: 5},
+		},
+	},
+}
+
+var testLines = []string{
+	"Athequickbrownfox",
+	"Bthequickbrownfox",
+	"Athe",
+}
+
+func TestViewer_handleKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		keys           []key
+		wantLine       int
+		wantColumn     int
+		wantLeftColumn int
+	}{
+		{"Should move down and right", []key{keyDown, keyRight, keyRight}, 1, 2, 0},
+		{"Should not move before the first line and column", []key{keyUp, keyLeft}, 0, 0, 0},
+		{"Should not move after the last line", []key{keyPageDown, keyDown}, 2, 0, 0},
+		{"Should keep the cursor inside a shorter line", []key{keyEnd, keyDown, keyDown}, 2, 3, 3},
+		{"Should scroll to the right when the cursor leaves the screen", []key{keyEnd}, 0, 16, 7},
+		{"Should go back to the first column", []key{keyEnd, keyHome}, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer := newViewer(testRecords, testLines, gutterWidth+sidePanelWidth+10, 3)
+			for _, pressedKey := range tt.keys {
+				viewer.handleKey(pressedKey)
+			}
+			if viewer.cursorLine != tt.wantLine || viewer.cursorColumn != tt.wantColumn || viewer.leftColumn != tt.wantLeftColumn {
+				t.Errorf("viewer.handleKey() cursor = (%v, %v) left column = %v, want (%v, %v) left column = %v",
+					viewer.cursorLine, viewer.cursorColumn, viewer.leftColumn, tt.wantLine, tt.wantColumn, tt.wantLeftColumn)
+			}
+		})
+	}
+
+	viewer := newViewer(testRecords, testLines, 80, 24)
+	if viewer.handleKey(keyQuit) {
+		t.Errorf("viewer.handleKey() should return false when quitting")
+	}
+}
+
+func TestViewer_ruler(t *testing.T) {
+	viewer := newViewer(testRecords, testLines, gutterWidth+sidePanelWidth+12, 3)
+	viewer.cursorColumn = 19
+	want := "....+....1.."
+	if got := viewer.ruler(); got != want {
+		t.Errorf("viewer.ruler() = %q, want %q", got, want)
+	}
+
+	viewer.leftColumn = 8
+	want = ".1....+....2"
+	want = strings.Replace(want, "+....2", "+...."+ansiReverse+"2"+ansiReset, 1)
+	if got := viewer.ruler(); got != want {
+		t.Errorf("viewer.ruler() = %q, want %q", got, want)
+	}
+}
+
+func TestViewer_sidePanel(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   int
+		column int
+		want   []string
+	}{
+		{
+			"Should describe the field under the cursor",
+			0,
+			2,
+			[]string{"Line 1, column 3", "", "Record: record A", "Field: field 2", "Positions: 2-5", "Length: 4", "Value: ", "[theq]"},
+		},
+		{
+			"Should describe a column without field",
+			0,
+			7,
+			[]string{"Line 1, column 8", "", "Record: record A", "No field on this column"},
+		},
+		{
+			"Should describe a line without record",
+			1,
+			0,
+			[]string{"Line 2, column 1", "", "No record matches this line"},
+		},
+		{
+			"Should show the part of the field that exists on a short line",
+			2,
+			3,
+			[]string{"Line 3, column 4", "", "Record: record A", "Field: field 2", "Positions: 2-5", "Length: 4", "Value: ", "[the]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer := newViewer(testRecords, testLines, 80, 24)
+			viewer.cursorLine, viewer.cursorColumn = tt.line, tt.column
+			if got := viewer.sidePanel(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("viewer.sidePanel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestViewer_render(t *testing.T) {
+	viewer := newViewer(testRecords, testLines, 80, 5)
+	screen := viewer.render()
+
+	if lines := strings.Split(screen, "\r\n"); len(lines) != 5 {
+		t.Errorf("viewer.render() should fill the height of the terminal, got %v lines", len(lines))
+	}
+	for _, content := range []string{"     1 |", "     3 |", "Record: record A", "Field: field 1"} {
+		if !strings.Contains(screen, content) {
+			t.Errorf("viewer.render() = %q, want it to contain %q", screen, content)
+		}
+	}
+}
+
+func TestViewer_render_ControlCharacters(t *testing.T) {
+	viewer := newViewer(testRecords, []string{"A\x1b[2J\x9b"}, 80, 10)
+	viewer.cursorColumn = 1
+	screen := viewer.render()
+
+	if strings.Contains(screen, "\x1b[2J") || strings.ContainsRune(screen, '\x9b') {
+		t.Errorf("viewer.render() = %q, which should not have the control characters of the line", screen)
+	}
+	for _, content := range []string{"[2J\x1b[0m�", "[␛[2J]"} {
+		if !strings.Contains(screen, content) {
+			t.Errorf("viewer.render() = %q, want it to contain %q", screen, content)
+		}
+	}
+}
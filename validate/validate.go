@@ -0,0 +1,120 @@
+package validate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pedroppinheiro/fwf/query"
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+// Violation is a problem found on a line: a line that does not match any record, a field whose value is not valid or a rule
+// of the record that the line breaks. The number of the line starts at 1
+type Violation struct {
+	Line    int    `json:"line"`
+	Record  string `json:"record,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// String returns the violation as a line of text, e.g. `line 3 (detail): rule "due after issue" is broken: due_date >= issue_date`
+func (violation Violation) String() string {
+	if violation.Record == "" {
+		return fmt.Sprintf("line %v: %v", violation.Line, violation.Message)
+	}
+	return fmt.Sprintf("line %v (%v): %v", violation.Line, violation.Record, violation.Message)
+}
+
+// Result tells how many lines were validated and how many violations were found on them
+type Result struct {
+	Lines          int
+	UnmatchedLines int
+	InvalidLines   int
+	Violations     int
+}
+
+// rule is a rule of a record along with its parsed expression
+type rule struct {
+	yamlconfig.Rule
+	expression query.Expression
+}
+
+// Validator checks the lines of the records of a layout
+type Validator struct {
+	records []yamlconfig.Record
+	rules   [][]rule
+}
+
+// NewValidator returns a Validator of the lines of the given records. An error is returned if the expression of a rule is
+// not valid or references a field that its record does not have
+func NewValidator(records []yamlconfig.Record) (Validator, error) {
+	validator := Validator{records: records, rules: make([][]rule, len(records))}
+	for i, record := range records {
+		for _, recordRule := range record.Rules {
+			expression, err := query.Parse(recordRule.Expression, []yamlconfig.Record{record})
+			if err != nil {
+				return Validator{}, fmt.Errorf("NewValidator(): error - the rule %q of the record %q is not valid: %v", recordRule.Name, record.Name, err)
+			}
+			validator.rules[i] = append(validator.rules[i], rule{recordRule, expression})
+		}
+	}
+	return validator, nil
+}
+
+// ValidateLine returns the violations of a line, which has the given number: the fields whose values are not valid, in the
+// order of the positions of the fields, followed by the rules that the line breaks, in the order they are declared. Rules
+// are verified even when the values of their fields are not valid, in which case the comparisons over them do not match
+func (validator Validator) ValidateLine(number int, line string) []Violation {
+	recordIndex, isRecordFound := yamlconfig.FindFirstRecordIndexThatMatchesString(validator.records, line)
+	if !isRecordFound {
+		return []Violation{{Line: number, Message: "the line does not match any record"}}
+	}
+
+	record := validator.records[recordIndex]
+	var violations []Violation
+	for _, field := range record.Fields {
+		if _, err := record.ParseFieldValue(field, line); err != nil {
+			violations = append(violations, Violation{Line: number, Record: record.Name, Field: field.Name, Message: err.Error()})
+		}
+	}
+
+	row := query.Row{Number: number, Line: line, Record: &validator.records[recordIndex]}
+	for _, recordRule := range validator.rules[recordIndex] {
+		if !recordRule.expression.Matches(row) {
+			message := fmt.Sprintf("rule %q is broken: %v", recordRule.Name, recordRule.Expression)
+			violations = append(violations, Violation{Line: number, Record: record.Name, Rule: recordRule.Name, Message: message})
+		}
+	}
+	return violations
+}
+
+// Validate reads the lines of a file, one at a time, and calls the given function for each violation found on them
+func (validator Validator) Validate(file io.Reader, handle func(Violation) error) (Result, error) {
+	result := Result{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		result.Lines++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		violations := validator.ValidateLine(result.Lines, line)
+		if len(violations) == 0 {
+			continue
+		}
+		if violations[0].Record == "" {
+			result.UnmatchedLines++
+		} else {
+			result.InvalidLines++
+		}
+		for _, violation := range violations {
+			result.Violations++
+			if err := handle(violation); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, scanner.Err()
+}
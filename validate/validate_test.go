@@ -0,0 +1,84 @@
+package validate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pedroppinheiro/fwf/yamlconfig"
+)
+
+var testRecords = []yamlconfig.Record{
+	{
+		Name:  "detail",
+		Regex: yamlconfig.MustCreateRegex("^D"),
+		Fields: []yamlconfig.Field{
+			{Name: "issue_date", Initial: 2, End: 9, Type: yamlconfig.DateType},
+			{Name: "due_date", Initial: 10, End: 17, Type: yamlconfig.DateType},
+			{Name: "payment_type", Initial: 18, End: 18},
+			{Name: "barcode", Initial: 19, End: 22},
+		},
+		Rules: []yamlconfig.Rule{
+			{Name: "due after issue", Expression: "due_date >= issue_date"},
+			{Name: "slips have barcodes", Expression: `if payment_type == "2" then barcode != ""`},
+		},
+	},
+}
+
+func TestValidator_Validate(t *testing.T) {
+	file := "D20210110202101201ABCD\n" +
+		"D202101102021010521234\n" +
+		"D20210110202101202    \n" +
+		"D2021011020210x202    \n" +
+		"Xunknown\n"
+
+	validator, err := NewValidator(testRecords)
+	if err != nil {
+		t.Fatalf("NewValidator() error = %v", err)
+	}
+
+	var got []string
+	result, err := validator.Validate(strings.NewReader(file), func(violation Violation) error {
+		got = append(got, violation.String())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	want := []string{
+		`line 2 (detail): rule "due after issue" is broken: due_date >= issue_date`,
+		`line 3 (detail): rule "slips have barcodes" is broken: if payment_type == "2" then barcode != ""`,
+		`line 4 (detail): field "due_date": "20210x20" is not a valid date in the format YYYYMMDD`,
+		`line 4 (detail): rule "due after issue" is broken: due_date >= issue_date`,
+		`line 4 (detail): rule "slips have barcodes" is broken: if payment_type == "2" then barcode != ""`,
+		`line 5: the line does not match any record`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() violations = %v, want %v", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if wantResult := (Result{Lines: 5, UnmatchedLines: 1, InvalidLines: 3, Violations: 6}); result != wantResult {
+		t.Errorf("Validate() = %+v, want %+v", result, wantResult)
+	}
+}
+
+func TestNewValidator_errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{"Should get error on expressions that are not valid", "due_date >="},
+		{"Should get error on fields of other records", "amount > 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := []yamlconfig.Record{
+				{Name: "detail", Fields: []yamlconfig.Field{{Name: "due_date", Initial: 1, End: 8}}, Rules: []yamlconfig.Rule{{Name: "rule", Expression: tt.expression}}},
+				{Name: "trailer", Fields: []yamlconfig.Field{{Name: "amount", Initial: 1, End: 8}}},
+			}
+			if _, err := NewValidator(records); err == nil {
+				t.Errorf("NewValidator() error = nil, want an error")
+			}
+		})
+	}
+}
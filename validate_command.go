@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/pedroppinheiro/fwf/validate"
+)
+
+// runValidateCommand handles "fwf validate", which reports the lines of a file that break its layout
+func runValidateCommand(args []string) {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	validateYAMLLocation := flags.String("yaml", "", "the full path for the yaml configuration, in which the rules of the records are declared")
+	validateFileLocation := flags.String("file", "", "the full path for the file to be validated, the standard input is read if none is given")
+	format := flags.String("format", "text", "the format of the violations written to the standard output: \"text\" or \"json\" (an object per line)")
+	flags.Parse(args)
+
+	if *validateYAMLLocation == "" {
+		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf validate -h\" for help")
+	}
+	if *format != "text" && *format != "json" {
+		panic("Unknown format \"" + *format + "\", use \"fwf validate -h\" for help")
+	}
+
+	configuration := readConfigurationFromYAML(*validateYAMLLocation)
+	validator, err := validate.NewValidator(configuration.Records)
+	if err != nil {
+		panic(err)
+	}
+
+	var file io.Reader = os.Stdin
+	if *validateFileLocation != "" {
+		openedFile := getFile(*validateFileLocation)
+		defer openedFile.Close()
+		file = openedFile
+	}
+
+	writer := bufio.NewWriter(os.Stdout)
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	result, err := validator.Validate(file, func(violation validate.Violation) error {
+		if *format == "json" {
+			return encoder.Encode(violation)
+		}
+		_, err := writer.WriteString(violation.String() + "\n")
+		return err
+	})
+	if flushErr := writer.Flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	log.Printf("%v lines validated, %v lines with violations and %v lines without record\n", result.Lines, result.InvalidLines, result.UnmatchedLines)
+	if result.Violations > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/pedroppinheiro/fwf/tui"
+)
+
+// runViewCommand handles "fwf view", which shows the file on an interactive terminal visualization
+func runViewCommand(args []string) {
+	flags := flag.NewFlagSet("view", flag.ExitOnError)
+	viewYAMLLocation := flags.String("yaml", "", "the full path for the yaml configuration")
+	viewFileLocation := flags.String("file", "", "the full path for the file to be visualized")
+	flags.Parse(args)
+
+	if *viewYAMLLocation == "" {
+		panic("Please provide a valid yaml location with the flag \"-yaml\", use \"fwf view -h\" for help")
+	}
+	if *viewFileLocation == "" {
+		panic("Please provide a valid file location with the flag \"-file\", use \"fwf view -h\" for help")
+	}
+
+	configuration := readConfigurationFromYAML(*viewYAMLLocation)
+	lines := readLines(*viewFileLocation)
+
+	terminal, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		panic(err)
+	}
+	defer terminal.Close()
+
+	if err = tui.Run(configuration.Records, lines, terminal); err != nil {
+		panic(err)
+	}
+}
@@ -8,18 +8,39 @@ import (
 
 // Configuration is the representation of the records described on a YAML file
 type Configuration struct {
+	// Version identifies the version of the layout, which is shown on the reports
+	Version string `yaml:",omitempty"`
+
 	Records []Record
 }
 
 // isValid returns true if the given configuration is valid, and else otherwise.
 // A valid configuration is a configuration in which all fields, of each record, are valid and
-// there is no conflict between them.
+// there is no conflict between them, in which the key fields of each record are fields of that record and in which the
+// masks of the sensitive fields can be used on them.
 func (configuration Configuration) isValid() (bool, error) {
 	for _, record := range configuration.Records {
 		existsConflict, err := existsConflictOnFields(record.Fields)
 		if err != nil || existsConflict {
 			return false, err
 		}
+		if err = record.validateKey(); err != nil {
+			return false, err
+		}
+		if err = record.validateRules(); err != nil {
+			return false, err
+		}
+		for _, field := range record.Fields {
+			if err = field.validateMask(); err != nil {
+				return false, err
+			}
+			if err = field.validateValues(); err != nil {
+				return false, err
+			}
+			if err = record.validateCheckDigit(field); err != nil {
+				return false, err
+			}
+		}
 	}
 	return true, nil
 }
@@ -44,3 +65,58 @@ func ReadConfiguration(yamlConfiguration []byte) (Configuration, error) {
 
 	return configuration, err
 }
+
+// WriteConfiguration returns the YAML content equivalent to a given Configuration.
+// Records and fields are written in the same order as they are on the Configuration
+func WriteConfiguration(configuration Configuration) ([]byte, error) {
+	return yaml.Marshal(configuration)
+}
+
+// SetFieldOnRecord returns a copy of a given configuration in which the given field is set on the record of the given index.
+// If the record already has a field with the same name, only the positions of that field are changed (e.g. resized), keeping
+// its other attributes, otherwise the field is added.
+// An error is returned if the resulting fields of the record are invalid or have conflicts between them
+func SetFieldOnRecord(configuration Configuration, recordIndex int, field Field) (Configuration, error) {
+	if recordIndex < 0 || recordIndex >= len(configuration.Records) {
+		return Configuration{}, fmt.Errorf("SetFieldOnRecord(): error - there is no record on index %v", recordIndex)
+	}
+
+	if !field.isValid() {
+		return Configuration{}, fmt.Errorf("SetFieldOnRecord(): error - the field is invalid: %v", field)
+	}
+
+	record := configuration.Records[recordIndex]
+	fields := make([]Field, 0, len(record.Fields)+1)
+	isFieldReplaced := false
+	for _, existingField := range record.Fields {
+		if existingField.Name == field.Name {
+			existingField.Initial, existingField.End = field.Initial, field.End
+			if err := existingField.validateValues(); err != nil {
+				return Configuration{}, fmt.Errorf("SetFieldOnRecord(): error - %v", err)
+			}
+			fields = append(fields, existingField)
+			isFieldReplaced = true
+		} else {
+			fields = append(fields, existingField)
+		}
+	}
+	if !isFieldReplaced {
+		fields = append(fields, field)
+	}
+
+	existsConflict, err := existsConflictOnFields(fields)
+	if err != nil {
+		return Configuration{}, fmt.Errorf("SetFieldOnRecord(): error - invalid fields were detected.\n%v", err)
+	}
+	if existsConflict {
+		return Configuration{}, fmt.Errorf("SetFieldOnRecord(): error - field %q conflicts with another field of record %q", field.Name, record.Name)
+	}
+
+	record.Fields = fields
+	records := make([]Record, len(configuration.Records))
+	copy(records, configuration.Records)
+	records[recordIndex] = record
+	configuration.Records = records
+
+	return configuration, nil
+}
@@ -130,6 +130,18 @@ func Test_ReadConfiguration(t *testing.T) {
 			Configuration{},
 			true,
 		},
+		{
+			"construct yaml with the version of the layout",
+			args{yamlConfiguration: []byte("version: \"2.1\"\nrecords: []")},
+			Configuration{Version: "2.1", Records: []Record{}},
+			false,
+		},
+		{
+			"get error due to a key field that is not a field of the record",
+			args{yamlConfiguration: []byte("records:\n  - name: \"record A\"\n    regex: \"^A\"\n    key: [\"id\"]\n    fields:\n      - name: \"code\"\n        initial: 1\n        end: 2")},
+			Configuration{},
+			true,
+		},
 		{
 			"get empty configuration given an empty string",
 			args{yamlConfiguration: []byte("")},
@@ -314,3 +326,161 @@ func TestConfiguration_isValid(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteConfiguration(t *testing.T) {
+	var configuration Configuration = Configuration{
+		Records: []Record{
+			{
+				Name:  "record B",
+				Regex: Regex{"^B.*$", regexp.MustCompile("^B.*$")},
+				Fields: []Field{
+					{
+						Name:    "field 1",
+						Initial: 1,
+						End:     2,
+					},
+				},
+			},
+			{
+				Name:  "record A",
+				Regex: Regex{"^A.*$", regexp.MustCompile("^A.*$")},
+				Fields: []Field{
+					{
+						Name:    "field 2",
+						Initial: 1,
+						End:     3,
+					},
+				},
+			},
+		},
+	}
+
+	content, err := WriteConfiguration(configuration)
+	if err != nil {
+		t.Fatalf("WriteConfiguration() error = %v", err)
+	}
+
+	got, err := ReadConfiguration(content)
+	if err != nil {
+		t.Fatalf("ReadConfiguration() error = %v, content:\n%s", err, content)
+	}
+	if !reflect.DeepEqual(got, configuration) {
+		t.Errorf("WriteConfiguration() then ReadConfiguration() = %v, want %v", got, configuration)
+	}
+}
+
+func TestSetFieldOnRecord(t *testing.T) {
+	var configuration Configuration = Configuration{
+		Records: []Record{
+			{
+				Name:  "record A",
+				Regex: Regex{"^A.*$", regexp.MustCompile("^A.*$")},
+				Fields: []Field{
+					{
+						Name:    "field 1",
+						Initial: 1,
+						End:     2,
+					},
+					{
+						Name:    "field 2",
+						Initial: 5,
+						End:     6,
+					},
+				},
+			},
+		},
+	}
+
+	type args struct {
+		recordIndex int
+		field       Field
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []Field
+		wantErr bool
+	}{
+		{
+			"Should add a new field",
+			args{0, Field{Name: "field 3", Initial: 3, End: 4}},
+			[]Field{{Name: "field 1", Initial: 1, End: 2}, {Name: "field 3", Initial: 3, End: 4}, {Name: "field 2", Initial: 5, End: 6}},
+			false,
+		},
+		{
+			"Should resize an existing field with the same name",
+			args{0, Field{Name: "field 1", Initial: 1, End: 4}},
+			[]Field{{Name: "field 1", Initial: 1, End: 4}, {Name: "field 2", Initial: 5, End: 6}},
+			false,
+		},
+		{
+			"Should get error due to a conflict with another field",
+			args{0, Field{Name: "field 3", Initial: 2, End: 3}},
+			nil,
+			true,
+		},
+		{
+			"Should get error due to an invalid field",
+			args{0, Field{Name: "field 3", Initial: 4, End: 3}},
+			nil,
+			true,
+		},
+		{
+			"Should get error due to an unknown record",
+			args{1, Field{Name: "field 3", Initial: 3, End: 4}},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SetFieldOnRecord(configuration, tt.args.recordIndex, tt.args.field)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetFieldOnRecord() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.Records[tt.args.recordIndex].Fields, tt.want) {
+				t.Errorf("SetFieldOnRecord() fields = %v, want %v", got.Records[tt.args.recordIndex].Fields, tt.want)
+			}
+			if len(configuration.Records[0].Fields) != 2 {
+				t.Errorf("SetFieldOnRecord() should not change the given configuration, got %v", configuration)
+			}
+		})
+	}
+}
+
+func TestSetFieldOnRecord_KeepsAttributes(t *testing.T) {
+	fields := []Field{
+		{Name: "amount", Initial: 1, End: 5, Type: DecimalType, Decimals: 2, Description: "amount paid", Sensitive: true, Mask: FormatMask},
+		{Name: "name", Initial: 11, End: 20, Sensitive: true, Mask: FakeMask, Generator: NameGenerator},
+		{Name: "date", Initial: 31, End: 38, Type: DateType, Format: "DDMMYYYY"},
+		{Name: "code", Initial: 41, End: 42, Values: &Values{Descriptions: map[string]string{"01": "entry"}}},
+		{Name: "card", Initial: 51, End: 61, Type: IntegerType, CheckDigit: &CheckDigit{Algorithm: LuhnCheckDigit}},
+	}
+	configuration := Configuration{Records: []Record{{Name: "a", Regex: MustCreateRegex("^"), Fields: fields}}}
+
+	want := make([]Field, len(fields))
+	for i, field := range fields {
+		var err error
+		if configuration, err = SetFieldOnRecord(configuration, 0, Field{Name: field.Name, Initial: field.Initial, End: field.End + 2}); err != nil {
+			t.Fatalf("SetFieldOnRecord() error = %v", err)
+		}
+		field.End += 2
+		want[i] = field
+	}
+
+	content, err := WriteConfiguration(configuration)
+	if err != nil {
+		t.Fatalf("WriteConfiguration() error = %v", err)
+	}
+	got, err := ReadConfiguration(content)
+	if err != nil {
+		t.Fatalf("ReadConfiguration() error = %v\n%s", err, content)
+	}
+	if !reflect.DeepEqual(got.Records[0].Fields, want) {
+		t.Errorf("SetFieldOnRecord() fields = %v, want the resized fields with all their attributes %v\n%s", got.Records[0].Fields, want, content)
+	}
+}
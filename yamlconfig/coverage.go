@@ -0,0 +1,70 @@
+package yamlconfig
+
+import "strings"
+
+// LineCoverage describes how the fields of a record cover a line
+type LineCoverage struct {
+	// Gaps are the ranges of positions, before the end of the record, that are not covered by any field
+	Gaps []Field
+
+	// GapCharacters is the number of characters of the line that are on gaps
+	GapCharacters int
+
+	// Overflow is the range of positions of the line after the end of the record, or an invalid Field if there is none
+	Overflow Field
+
+	// MissingCharacters is the number of characters the line lacks to reach the end of the record
+	MissingCharacters int
+}
+
+// Length returns the expected length of the lines of the record, which is the end of its last field
+func (record Record) Length() int {
+	length := 0
+	for _, field := range record.Fields {
+		if field.End > length {
+			length = field.End
+		}
+	}
+	return length
+}
+
+// Coverage returns how the fields of the record cover a given line. The line break at the end of the line is ignored
+func (record Record) Coverage(line string) LineCoverage {
+	lineLength := len([]rune(strings.TrimRight(line, "\r\n")))
+	recordLength := record.Length()
+	coverage := LineCoverage{}
+
+	fields := make([]Field, len(record.Fields))
+	copy(fields, record.Fields)
+	sortFieldsByInitialPositionAsc(fields)
+
+	limit := recordLength
+	if lineLength < limit {
+		limit = lineLength
+	}
+
+	position := 1
+	for _, field := range fields {
+		if field.Initial > position && position <= limit {
+			gapEnd := field.Initial - 1
+			if gapEnd > limit {
+				gapEnd = limit
+			}
+			coverage.Gaps = append(coverage.Gaps, Field{Initial: position, End: gapEnd})
+			coverage.GapCharacters += gapEnd - position + 1
+		}
+		if field.End+1 > position {
+			position = field.End + 1
+		}
+	}
+
+	if lineLength > recordLength {
+		coverage.Overflow = Field{Initial: recordLength + 1, End: lineLength}
+	}
+
+	if lineLength < recordLength {
+		coverage.MissingCharacters = recordLength - lineLength
+	}
+
+	return coverage
+}
@@ -0,0 +1,71 @@
+package yamlconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecord_Length(t *testing.T) {
+	tests := []struct {
+		name   string
+		record Record
+		want   int
+	}{
+		{"Record without fields has no length", Record{}, 0},
+		{"Length is the end of the last field", Record{Fields: []Field{{Initial: 5, End: 9}, {Initial: 1, End: 2}}}, 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.record.Length(); got != tt.want {
+				t.Errorf("Record.Length() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecord_Coverage(t *testing.T) {
+	record := Record{
+		Fields: []Field{
+			{Name: "field 2", Initial: 6, End: 8},
+			{Name: "field 1", Initial: 2, End: 3},
+		},
+	}
+
+	tests := []struct {
+		name string
+		line string
+		want LineCoverage
+	}{
+		{
+			"Should find the gaps between fields",
+			"abcdefgh",
+			LineCoverage{Gaps: []Field{{Initial: 1, End: 1}, {Initial: 4, End: 5}}, GapCharacters: 3},
+		},
+		{
+			"Should find the overflow after the last field, ignoring the line break",
+			"abcdefghij\r\n",
+			LineCoverage{Gaps: []Field{{Initial: 1, End: 1}, {Initial: 4, End: 5}}, GapCharacters: 3, Overflow: Field{Initial: 9, End: 10}},
+		},
+		{
+			"Should find the missing characters of a short line and only the gaps that exist on it",
+			"abcd",
+			LineCoverage{Gaps: []Field{{Initial: 1, End: 1}, {Initial: 4, End: 4}}, GapCharacters: 2, MissingCharacters: 4},
+		},
+		{
+			"Should consider empty lines as missing every character",
+			"",
+			LineCoverage{MissingCharacters: 8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := record.Coverage(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Record.Coverage() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := (Record{}).Coverage("abc"); !reflect.DeepEqual(got, LineCoverage{Overflow: Field{Initial: 1, End: 3}}) {
+		t.Errorf("Record.Coverage() of a record without fields should be all overflow, got %+v", got)
+	}
+}
@@ -10,6 +10,33 @@ type Field struct {
 	Name    string
 	Initial int
 	End     int
+
+	// Description is an optional text that explains the content of the field
+	Description string `yaml:",omitempty"`
+
+	// Type is used to decode and validate the content of the field. It is StringType when empty
+	Type FieldType `yaml:",omitempty"`
+
+	// Decimals is the number of implied decimal places of a DecimalType field
+	Decimals int `yaml:",omitempty"`
+
+	// Format is the format of a DateType field, e.g. "DDMMYYYY". It is "YYYYMMDD" when empty
+	Format string `yaml:",omitempty"`
+
+	// Sensitive tells that the content of the field must be masked before the file leaves production, e.g. with "fwf mask"
+	Sensitive bool `yaml:",omitempty"`
+
+	// Mask is the strategy used to mask a sensitive field. It is FixedMask when empty
+	Mask MaskStrategy `yaml:",omitempty"`
+
+	// Generator is the kind of fake data used to mask a field with FakeMask
+	Generator FakeGenerator `yaml:",omitempty"`
+
+	// Values are the known values of a code field and their descriptions. Any value is accepted when there are none
+	Values *Values `yaml:",omitempty"`
+
+	// CheckDigit is the algorithm that verifies the check digits of the field, which may check its own value or other fields
+	CheckDigit *CheckDigit `yaml:"checkDigit,omitempty"`
 }
 
 // Marker needs to be implemented in order to get the initial and end marker. These markers are placed before and after a string (field)
@@ -67,106 +94,55 @@ func existsConflict(field1 Field, field2 Field) (bool, error) {
 	return !isField1InitialOutOfField2Range || !isField1EndOutOfField2Range, nil
 }
 
-// getStringBeforeField returns the string that exists before a given field.
-// For instance, given the string "thequickbrownfox", and a field with initial 4 and end 8
-// the resulting string will be "the"
-func getStringBeforeField(s string, field Field) string {
-	if !field.isValid() {
-		panic("Error - the given field is invalid")
-	}
-
-	if s == "" || field.Initial == 1 {
-		return ""
-	}
-
-	if field.Initial > len(s) {
-		return s
-	}
-
-	runes := []rune(s)
-	return string(runes[0 : field.Initial-1])
-}
-
-// getStringBeforeField returns the string of a given field.
-// For instance, given the string "thequickbrownfox", and a field with initial 4 and end 8
-// the resulting string will be "quick"
-func getStringOfField(s string, field Field) string {
-	if !field.isValid() {
-		panic("Error - the given field is invalid")
-	}
-
-	if s == "" {
-		return ""
-	}
-
-	if field.Initial > len(s) {
-		return ""
-	}
-
-	var end int
-	// end is bigger than the string
-	if field.End > len(s) {
-		end = len(s)
-	} else {
-		end = field.End
-	}
-
-	runes := []rune(s)
-	return string(runes[field.Initial-1 : end])
-}
-
-// getStringBeforeField returns the string after a given field.
-// For instance, given the string "thequickbrownfox", and a field with initial 4 and end 8
-// the resulting string will be "brownfox"
-func getStringAfterField(s string, field Field) string {
-	if !field.isValid() {
-		panic("Error - the given field is invalid")
-	}
-
-	// end is bigger than the string
-	if field.End > len(s) {
-		return ""
-	}
-
-	if s == "" || field.End == len(s) {
-		return ""
-	}
-
-	runes := []rune(s)
-	return string(runes[field.End:])
+// Escaper may be implemented by a Marker when the content of the string must be escaped before being placed
+// between markers, for instance when the markers are HTML tags
+type Escaper interface {
+	Escape(s string) string
 }
 
 // ApplyMarkerToFieldsOnString returns a string that is the result of applying a field marker to the fields on a string
 // For instance, given a marker "<" and ">", and given the string "thequickbrownfox" with a field with initial 4 and end 8,
-// the resulting string will be "the<quick>brownfox"
+// the resulting string will be "the<quick>brownfox". If the marker implements Escaper, the content of the string is escaped with it
 func ApplyMarkerToFieldsOnString(marker Marker, fields []Field, s string) string {
 	sortFieldsByInitialPositionAsc(fields)
 
+	escape := func(s string) string { return s }
+	if escaper, ok := marker.(Escaper); ok {
+		escape = escaper.Escape
+	}
+
 	var (
-		finalString          string
-		tempString           string
-		lastFieldEndPosition int
+		finalString string
+		runes       = []rune(s)
+		position    int
 	)
 
-	for i, field := range fields {
-		tempString = getStringBeforeField(s, field)
-
-		stringOfField := getStringOfField(s, field)
-		if stringOfField != "" {
-			tempString += marker.ObtainInitialMarker(field)
-			tempString += stringOfField
-			tempString += marker.ObtainEndMarker(field)
+	for _, field := range fields {
+		if !field.isValid() {
+			panic("Error - the given field is invalid")
 		}
 
-		if i != 0 {
-			lastFieldEndPosition = fields[i-1].End
-			tempString = tempString[lastFieldEndPosition:]
+		initial := field.Initial - 1
+		if initial > len(runes) {
+			initial = len(runes)
+		}
+		if initial > position {
+			finalString += escape(string(runes[position:initial]))
+			position = initial
 		}
 
-		finalString += tempString
+		end := field.End
+		if end > len(runes) {
+			end = len(runes)
+		}
+		if end > position {
+			finalString += marker.ObtainInitialMarker(field)
+			finalString += escape(string(runes[position:end]))
+			finalString += marker.ObtainEndMarker(field)
+			position = end
+		}
 	}
 
-	lastField := fields[len(fields)-1]
-	finalString += getStringAfterField(s, lastField)
+	finalString += escape(string(runes[position:]))
 	return finalString
 }
@@ -0,0 +1,352 @@
+package yamlconfig
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// CheckDigitAlgorithm is how the check digits of a value are calculated from its other digits
+type CheckDigitAlgorithm string
+
+const (
+	// LuhnCheckDigit is the algorithm of credit card numbers: the last digit checks the others
+	LuhnCheckDigit CheckDigitAlgorithm = "luhn"
+
+	// Mod10CheckDigit is the FEBRABAN module 10 of the fields of digitable lines: the last digit checks the others. It is
+	// calculated like LuhnCheckDigit
+	Mod10CheckDigit CheckDigitAlgorithm = "mod10"
+
+	// Mod11CheckDigit is the FEBRABAN module 11 of barcodes, with weights from 2 to 9: the last digit checks the others, and
+	// it is 1 when the calculation gives 0, 10 or 11
+	Mod11CheckDigit CheckDigitAlgorithm = "mod11"
+
+	// CPFCheckDigit is the algorithm of CPFs, the Brazilian individual taxpayer numbers: the last 2 of its 11 digits check the others
+	CPFCheckDigit CheckDigitAlgorithm = "cpf"
+
+	// CNPJCheckDigit is the algorithm of CNPJs, the Brazilian company taxpayer numbers: the last 2 of its 14 digits check the others
+	CNPJCheckDigit CheckDigitAlgorithm = "cnpj"
+
+	// IBANCheckDigit is the module 97 of IBANs, the international bank account numbers, whose third and fourth characters
+	// check the others
+	IBANCheckDigit CheckDigitAlgorithm = "iban"
+
+	// BarcodeCheckDigit is the algorithm of the 44 digits barcodes of bank slips, whose fifth digit is the Mod11CheckDigit
+	// of the others
+	BarcodeCheckDigit CheckDigitAlgorithm = "barcode"
+
+	// DigitableLineCheckDigit is the algorithm of the 47 digits digitable lines of bank slips, whose first three fields end
+	// with their Mod10CheckDigit and whose fourth field is the check digit of the barcode
+	DigitableLineCheckDigit CheckDigitAlgorithm = "digitableLine"
+)
+
+// ibanRegex matches an IBAN without spaces: the country, the check digits and the account
+var ibanRegex = regexp.MustCompile("^[A-Z]{2}[0-9]{2}[A-Z0-9]{1,30}$")
+
+// UnmarshalYAML interface is implemented to give an error as soon as an unknown algorithm is used on the yaml.
+// See https://godoc.org/gopkg.in/yaml.v2#Unmarshaler for more details
+func (algorithm *CheckDigitAlgorithm) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var algorithmName string
+	if err := unmarshal(&algorithmName); err != nil {
+		return err
+	}
+
+	switch CheckDigitAlgorithm(algorithmName) {
+	case LuhnCheckDigit, Mod10CheckDigit, Mod11CheckDigit, CPFCheckDigit, CNPJCheckDigit, IBANCheckDigit, BarcodeCheckDigit, DigitableLineCheckDigit:
+		*algorithm = CheckDigitAlgorithm(algorithmName)
+		return nil
+	}
+	return fmt.Errorf("unknown check digit %q, it must be one of: luhn, mod10, mod11, cpf, cnpj, iban, barcode, digitableLine", algorithmName)
+}
+
+// ValueLength returns how many digits a value of the algorithm must have, or 0 if any number of digits is accepted
+func (algorithm CheckDigitAlgorithm) ValueLength() int {
+	switch algorithm {
+	case CPFCheckDigit:
+		return 11
+	case CNPJCheckDigit:
+		return 14
+	case BarcodeCheckDigit:
+		return 44
+	case DigitableLineCheckDigit:
+		return 47
+	}
+	return 0
+}
+
+// CheckDigitsCount returns how many check digits the algorithm has on a value
+func (algorithm CheckDigitAlgorithm) CheckDigitsCount() int {
+	switch algorithm {
+	case CPFCheckDigit, CNPJCheckDigit, IBANCheckDigit:
+		return 2
+	case DigitableLineCheckDigit:
+		return 4
+	}
+	return 1
+}
+
+// isAtTheEnd returns true if the check digits of the algorithm are the last digits of a value
+func (algorithm CheckDigitAlgorithm) isAtTheEnd() bool {
+	return algorithm != IBANCheckDigit && algorithm != BarcodeCheckDigit && algorithm != DigitableLineCheckDigit
+}
+
+// normalize returns a value without the spaces around it and without its separators: dots, hyphens, slashes and spaces, and
+// in upper case for IBANs. An error is returned if the value does not have the characters required by the algorithm
+func (algorithm CheckDigitAlgorithm) normalize(value string) (string, error) {
+	normalized := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(" .-/", r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(value))
+
+	if algorithm == IBANCheckDigit {
+		normalized = strings.ToUpper(normalized)
+		if !ibanRegex.MatchString(normalized) {
+			return "", fmt.Errorf("%q is not an iban", value)
+		}
+		return normalized, nil
+	}
+
+	if strings.IndexFunc(normalized, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return "", fmt.Errorf("%q has characters that are not digits", value)
+	}
+	if length := algorithm.ValueLength(); length > 0 && len(normalized) != length {
+		return "", fmt.Errorf("%q does not have the %v digits of a %v", value, length, algorithm)
+	}
+	if len(normalized) <= algorithm.CheckDigitsCount() {
+		return "", fmt.Errorf("%q does not have digits besides its check digits", value)
+	}
+	return normalized, nil
+}
+
+// WithCheckDigits returns a value, without its separators, with its check digits replaced by the ones calculated from its
+// other digits. An error is returned if the value does not have the characters required by the algorithm
+func (algorithm CheckDigitAlgorithm) WithCheckDigits(value string) (string, error) {
+	digits, err := algorithm.normalize(value)
+	if err != nil {
+		return "", err
+	}
+
+	last := len(digits) - 1
+	switch algorithm {
+	case LuhnCheckDigit, Mod10CheckDigit:
+		return digits[:last] + mod10(digits[:last]), nil
+	case Mod11CheckDigit:
+		return digits[:last] + febrabanMod11(digits[:last]), nil
+	case CPFCheckDigit:
+		return taxpayerMod11(digits[:9], 11), nil
+	case CNPJCheckDigit:
+		return taxpayerMod11(digits[:12], 9), nil
+	case IBANCheckDigit:
+		remainder := mod97(digits[4:] + digits[:2] + "00")
+		return fmt.Sprintf("%v%02d%v", digits[:2], 98-remainder, digits[4:]), nil
+	case BarcodeCheckDigit:
+		return digits[:4] + febrabanMod11(digits[:4]+digits[5:]) + digits[5:], nil
+	case DigitableLineCheckDigit:
+		first, second, third := digits[:9], digits[10:20], digits[21:31]
+		barcode := digits[:4] + "0" + digits[33:] + digits[4:9] + second + third
+		barcode, _ = BarcodeCheckDigit.WithCheckDigits(barcode)
+		return first + mod10(first) + second + mod10(second) + third + mod10(third) + barcode[4:5] + digits[33:], nil
+	}
+	return "", fmt.Errorf("unknown check digit %q", algorithm)
+}
+
+// IsValid returns true if the check digits of a value are the ones calculated from its other digits. CPFs and CNPJs made
+// of a single repeated digit, e.g. "00000000000", have valid check digits but are never issued, so they are not valid
+func (algorithm CheckDigitAlgorithm) IsValid(value string) bool {
+	normalized, err := algorithm.normalize(value)
+	if err != nil {
+		return false
+	}
+	if (algorithm == CPFCheckDigit || algorithm == CNPJCheckDigit) && strings.Count(normalized, normalized[:1]) == len(normalized) {
+		return false
+	}
+	checked, err := algorithm.WithCheckDigits(normalized)
+	return err == nil && checked == normalized
+}
+
+// mod10 returns the check digit of the digits with weights that alternate between 2, on the rightmost digit, and 1, in
+// which the digits of each product are summed
+func mod10(digits string) string {
+	sum := 0
+	for position := 0; position < len(digits); position++ {
+		product := int(digits[len(digits)-1-position]-'0') * (2 - position%2)
+		sum += product/10 + product%10
+	}
+	return string(rune('0' + (10-sum%10)%10))
+}
+
+// mod11Remainder returns the remainder by 11 of the sum of the digits multiplied by weights that go from 2, on the rightmost
+// digit, up to the maximum weight, and then restart from 2
+func mod11Remainder(digits string, maxWeight int) int {
+	sum := 0
+	for position := 0; position < len(digits); position++ {
+		sum += int(digits[len(digits)-1-position]-'0') * (2 + position%(maxWeight-1))
+	}
+	return sum % 11
+}
+
+// febrabanMod11 returns the check digit of barcodes, which is 11 minus the remainder, or 1 when that would be 10 or 11
+func febrabanMod11(digits string) string {
+	checkDigit := 11 - mod11Remainder(digits, 9)
+	if checkDigit >= 10 {
+		checkDigit = 1
+	}
+	return string(rune('0' + checkDigit))
+}
+
+// taxpayerMod11 returns the digits followed by the two check digits of CPFs and CNPJs. Each check digit is 11 minus the
+// remainder, or 0 when that would be 10 or 11
+func taxpayerMod11(digits string, maxWeight int) string {
+	for i := 0; i < 2; i++ {
+		checkDigit := 11 - mod11Remainder(digits, maxWeight)
+		if checkDigit >= 10 {
+			checkDigit = 0
+		}
+		digits += string(rune('0' + checkDigit))
+	}
+	return digits
+}
+
+// mod97 returns the remainder by 97 of an IBAN rearranged as a number, in which each letter is replaced by two digits,
+// "A" being 10 and "Z" being 35
+func mod97(rearranged string) int {
+	var number strings.Builder
+	for _, r := range rearranged {
+		if r >= 'A' && r <= 'Z' {
+			number.WriteString(fmt.Sprint(r - 'A' + 10))
+		} else {
+			number.WriteRune(r)
+		}
+	}
+	value, _ := new(big.Int).SetString(number.String(), 10)
+	return int(new(big.Int).Mod(value, big.NewInt(97)).Int64())
+}
+
+// CheckDigit tells how the check digits of a field are verified
+type CheckDigit struct {
+	Algorithm CheckDigitAlgorithm
+
+	// Of holds the names of the fields of the same record whose digits, in order, are checked by the field, e.g. an agency and
+	// an account checked by a separate digit. When empty, the field holds a value that ends with its own check digits
+	Of []string `yaml:",omitempty"`
+}
+
+// UnmarshalYAML interface is implemented so that a check digit can be written just as its algorithm.
+// See https://godoc.org/gopkg.in/yaml.v2#Unmarshaler for more details
+func (checkDigit *CheckDigit) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var algorithmName string
+	if err := unmarshal(&algorithmName); err == nil {
+		return unmarshal(&checkDigit.Algorithm)
+	}
+
+	type plainCheckDigit CheckDigit
+	return unmarshal((*plainCheckDigit)(checkDigit))
+}
+
+// MarshalYAML interface is implemented so that a check digit of the field itself is written just as its algorithm.
+// See https://godoc.org/gopkg.in/yaml.v2#Marshaler for more details
+func (checkDigit CheckDigit) MarshalYAML() (interface{}, error) {
+	if len(checkDigit.Of) == 0 {
+		return checkDigit.Algorithm, nil
+	}
+	type plainCheckDigit CheckDigit
+	return plainCheckDigit(checkDigit), nil
+}
+
+// ChecksOwnDigits returns true if the field holds a value that ends with its own check digits
+func (field Field) ChecksOwnDigits() bool {
+	return field.CheckDigit != nil && len(field.CheckDigit.Of) == 0
+}
+
+// ChecksGroupDigits returns true if the field holds the check digits of other fields
+func (field Field) ChecksGroupDigits() bool {
+	return field.CheckDigit != nil && len(field.CheckDigit.Of) > 0
+}
+
+// validateOwnCheckDigits returns an error if a raw value that is not blank does not end with the check digits calculated
+// from its other digits. Any value is valid if the field does not check its own digits
+func (field Field) validateOwnCheckDigits(rawValue string) error {
+	if !field.ChecksOwnDigits() || strings.TrimSpace(rawValue) == "" {
+		return nil
+	}
+	if !field.CheckDigit.Algorithm.IsValid(rawValue) {
+		return fmt.Errorf("field %q: %q does not have valid %v check digits", field.Name, rawValue, field.CheckDigit.Algorithm)
+	}
+	return nil
+}
+
+// validateCheckDigit returns an error if the check digit of a field can not be verified on the record
+func (record Record) validateCheckDigit(field Field) error {
+	if field.CheckDigit == nil {
+		return nil
+	}
+	algorithm := field.CheckDigit.Algorithm
+	if algorithm == "" {
+		return fmt.Errorf("field %q: the check digit must have an algorithm", field.Name)
+	}
+	if field.Type == DateType || field.Type == DecimalType {
+		return fmt.Errorf("field %q: the %v check digit can not be used on %v fields", field.Name, algorithm, field.Type)
+	}
+
+	if field.ChecksOwnDigits() {
+		length := algorithm.ValueLength()
+		if length == 0 {
+			length = algorithm.CheckDigitsCount() + 1
+		}
+		if algorithm == IBANCheckDigit {
+			length = 5
+		}
+		if length > field.Length() {
+			return fmt.Errorf("field %q: the %v check digit needs %v positions", field.Name, algorithm, length)
+		}
+		return nil
+	}
+
+	if !algorithm.isAtTheEnd() {
+		return fmt.Errorf("field %q: the %v check digit can only be used on the field that holds the whole value", field.Name, algorithm)
+	}
+	if field.Length() < algorithm.CheckDigitsCount() {
+		return fmt.Errorf("field %q: the %v check digit needs %v positions", field.Name, algorithm, algorithm.CheckDigitsCount())
+	}
+	for _, name := range field.CheckDigit.Of {
+		if _, isFound := record.FieldByName(name); !isFound || name == field.Name {
+			return fmt.Errorf("field %q: the checked field %q is not another field of the record %q", field.Name, name, record.Name)
+		}
+	}
+	return nil
+}
+
+// GroupValue returns the digits checked by a field that holds the check digits of other fields, which are the values of
+// the other fields, in order and without the spaces around them, followed by the value of the field
+func (record Record) GroupValue(field Field, line string) string {
+	var value strings.Builder
+	for _, name := range field.CheckDigit.Of {
+		checkedField, _ := record.FieldByName(name)
+		value.WriteString(strings.TrimSpace(checkedField.RawValue(line)))
+	}
+	value.WriteString(strings.TrimSpace(field.RawValue(line)))
+	return value.String()
+}
+
+// ParseFieldValue decodes the value of a field of the record on a line, like Field.ParseValue, and also verifies its check
+// digits, either its own or the ones of the fields it checks, as they may depend on the whole line
+func (record Record) ParseFieldValue(field Field, line string) (interface{}, error) {
+	rawValue := field.RawValue(line)
+	value, err := field.ParseValue(rawValue)
+	if err != nil {
+		return nil, err
+	}
+	if err = field.validateOwnCheckDigits(rawValue); err != nil {
+		return nil, err
+	}
+	if !field.ChecksGroupDigits() || strings.TrimSpace(rawValue) == "" {
+		return value, nil
+	}
+	if !field.CheckDigit.Algorithm.IsValid(record.GroupValue(field, line)) {
+		return nil, fmt.Errorf("field %q: %q is not the %v check digit of %v", field.Name, rawValue, field.CheckDigit.Algorithm, strings.Join(field.CheckDigit.Of, ", "))
+	}
+	return value, nil
+}
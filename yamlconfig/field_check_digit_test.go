@@ -0,0 +1,185 @@
+package yamlconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckDigitAlgorithm_IsValid(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm CheckDigitAlgorithm
+		value     string
+		want      bool
+	}{
+		{"Should accept a valid luhn", LuhnCheckDigit, "79927398713", true},
+		{"Should reject an invalid luhn", LuhnCheckDigit, "79927398710", false},
+		{"Should accept a valid mod10 of a field of a digitable line", Mod10CheckDigit, "0019050095", true},
+		{"Should accept a valid mod11 that would be 10 or 11", Mod11CheckDigit, "00001", true},
+		{"Should accept a formatted cpf", CPFCheckDigit, "529.982.247-25", true},
+		{"Should reject a cpf with the wrong digits", CPFCheckDigit, "52998224726", false},
+		{"Should reject a cpf with letters", CPFCheckDigit, "5299822472A", false},
+		{"Should reject a cpf of zeros", CPFCheckDigit, "00000000000", false},
+		{"Should reject a cpf of a repeated digit", CPFCheckDigit, "111.111.111-11", false},
+		{"Should accept a formatted cnpj", CNPJCheckDigit, "11.222.333/0001-81", true},
+		{"Should reject a cnpj with the wrong number of digits", CNPJCheckDigit, "112223330001811", false},
+		{"Should reject a cnpj of zeros", CNPJCheckDigit, "00000000000000", false},
+		{"Should accept an iban written with spaces", IBANCheckDigit, "GB82 WEST 1234 5698 7654 32", true},
+		{"Should reject an iban with the wrong digits", IBANCheckDigit, "GB83WEST12345698765432", false},
+		{"Should accept a barcode of a bank slip", BarcodeCheckDigit, "00193373700000001000500940144816060680935031", true},
+		{"Should reject a barcode with the wrong digit", BarcodeCheckDigit, "00194373700000001000500940144816060680935031", false},
+		{"Should accept a formatted digitable line", DigitableLineCheckDigit, "00190.50095 40144.816069 06809.350314 3 37370000000100", true},
+		{"Should reject a digitable line with the wrong digit of the barcode", DigitableLineCheckDigit, "00190500954014481606906809350314437370000000100", false},
+		{"Should reject a digitable line with the wrong digit of a field", DigitableLineCheckDigit, "00190500944014481606906809350314337370000000100", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.algorithm.IsValid(tt.value); got != tt.want {
+				t.Errorf("CheckDigitAlgorithm.IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDigitAlgorithm_WithCheckDigits(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm CheckDigitAlgorithm
+		value     string
+		want      string
+	}{
+		{"Should replace the digit of a luhn", LuhnCheckDigit, "79927398710", "79927398713"},
+		{"Should replace the digits of a cnpj", CNPJCheckDigit, "11.222.333/0001-00", "11222333000181"},
+		{"Should replace the digits of an iban", IBANCheckDigit, "gb00west12345698765432", "GB82WEST12345698765432"},
+		{"Should replace the digits of a digitable line", DigitableLineCheckDigit, "00190500904014481606006809350310037370000000100", "00190500954014481606906809350314337370000000100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.algorithm.WithCheckDigits(tt.value)
+			if err != nil {
+				t.Fatalf("CheckDigitAlgorithm.WithCheckDigits() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckDigitAlgorithm.WithCheckDigits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadConfiguration_checkDigits(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  string
+		want    *CheckDigit
+		wantErr bool
+	}{
+		{
+			name:   "Should read the algorithm of a field that checks its own digits",
+			fields: "    - {name: cpf, initial: 1, end: 11, checkDigit: cpf}",
+			want:   &CheckDigit{Algorithm: CPFCheckDigit},
+		},
+		{
+			name:   "Should read the fields checked by a field",
+			fields: "    - {name: digit, initial: 1, end: 1, checkDigit: {algorithm: mod11, of: [agency, account]}}\n    - {name: agency, initial: 2, end: 5}\n    - {name: account, initial: 6, end: 10}",
+			want:   &CheckDigit{Algorithm: Mod11CheckDigit, Of: []string{"agency", "account"}},
+		},
+		{
+			name:    "Should get error on unknown algorithms",
+			fields:  "    - {name: cpf, initial: 1, end: 11, checkDigit: mod12}",
+			wantErr: true,
+		},
+		{
+			name:    "Should get error on fields too short for the algorithm",
+			fields:  "    - {name: cpf, initial: 1, end: 10, checkDigit: cpf}",
+			wantErr: true,
+		},
+		{
+			name:    "Should get error on date fields",
+			fields:  "    - {name: date, initial: 1, end: 8, type: date, checkDigit: luhn}",
+			wantErr: true,
+		},
+		{
+			name:    "Should get error on checked fields that the record does not have",
+			fields:  "    - {name: digit, initial: 1, end: 1, checkDigit: {algorithm: luhn, of: [agency]}}",
+			wantErr: true,
+		},
+		{
+			name:    "Should get error on groups checked by algorithms whose digits are not at the end",
+			fields:  "    - {name: digit, initial: 1, end: 2, checkDigit: {algorithm: iban, of: [account]}}\n    - {name: account, initial: 3, end: 10}",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadConfiguration([]byte("records:\n - name: a\n   regex: a\n   fields:\n" + tt.fields))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadConfiguration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got.Records[0].Fields[0].CheckDigit, tt.want) {
+				t.Errorf("ReadConfiguration() check digit = %v, want %v", got.Records[0].Fields[0].CheckDigit, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecord_ParseFieldValue(t *testing.T) {
+	record := Record{
+		Name: "account",
+		Fields: []Field{
+			{Name: "agency", Initial: 1, End: 4},
+			{Name: "account", Initial: 5, End: 9, Type: IntegerType},
+			{Name: "digit", Initial: 10, End: 10, CheckDigit: &CheckDigit{Algorithm: Mod11CheckDigit, Of: []string{"agency", "account"}}},
+			{Name: "card", Initial: 11, End: 21, CheckDigit: &CheckDigit{Algorithm: LuhnCheckDigit}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		line    string
+		field   int
+		wantErr bool
+	}{
+		{"Should accept the check digit of a group", "1234000153" + "79927398713", 2, false},
+		{"Should reject a wrong check digit of a group", "1234000156" + "79927398713", 2, true},
+		{"Should accept a blank check digit of a group", "123400015 " + "79927398713", 2, false},
+		{"Should accept a field with its own check digit", "1234000153" + "79927398713", 3, false},
+		{"Should reject a field with a wrong check digit", "1234000153" + "79927398710", 3, true},
+		{"Should not check the fields of a group", "1234000156" + "79927398713", 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := record.ParseFieldValue(record.Fields[tt.field], tt.line); (err != nil) != tt.wantErr {
+				t.Errorf("Record.ParseFieldValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestField_ParseValue_checkDigits(t *testing.T) {
+	field := Field{Name: "card", Initial: 1, End: 11, Type: IntegerType, CheckDigit: &CheckDigit{Algorithm: LuhnCheckDigit}}
+	got, err := field.ParseValue("79927398710")
+	if err != nil {
+		t.Fatalf("Field.ParseValue() error = %v, want a wrong check digit to be accepted", err)
+	}
+	if got != int64(79927398710) {
+		t.Errorf("Field.ParseValue() = %v, want %v", got, int64(79927398710))
+	}
+}
+
+func TestWriteConfiguration_checkDigits(t *testing.T) {
+	configuration := Configuration{Records: []Record{{Name: "a", Regex: MustCreateRegex("a"), Fields: []Field{
+		{Name: "cpf", Initial: 1, End: 11, CheckDigit: &CheckDigit{Algorithm: CPFCheckDigit}},
+		{Name: "digit", Initial: 12, End: 12, CheckDigit: &CheckDigit{Algorithm: LuhnCheckDigit, Of: []string{"cpf"}}},
+	}}}}
+	content, err := WriteConfiguration(configuration)
+	if err != nil {
+		t.Fatalf("WriteConfiguration() error = %v", err)
+	}
+	got, err := ReadConfiguration(content)
+	if err != nil {
+		t.Fatalf("ReadConfiguration() error = %v\n%s", err, content)
+	}
+	if !reflect.DeepEqual(got.Records[0].Fields, configuration.Records[0].Fields) {
+		t.Errorf("WriteConfiguration() = %s, want the same fields when read", content)
+	}
+}
@@ -0,0 +1,122 @@
+package yamlconfig
+
+import (
+	"fmt"
+)
+
+// MaskStrategy is how the content of a sensitive field is replaced when it is masked
+type MaskStrategy string
+
+const (
+	// FixedMask replaces each character of the content that is not a space with "*". It is the default strategy
+	FixedMask MaskStrategy = "fixed"
+
+	// FormatMask replaces each digit with a digit and each letter with a letter of the same case, keeping the other
+	// characters, so that the content keeps its format. Dates are moved to another date up to a year away. The same
+	// content is always replaced by the same masked content
+	FormatMask MaskStrategy = "format"
+
+	// FakeMask replaces the content with fake data created by the field's generator, e.g. a fake name. The same content is
+	// always replaced by the same fake data
+	FakeMask MaskStrategy = "fake"
+
+	// TokenMask replaces the content with a token created with a secret key, which is always the same for the same content
+	// and key, so that masked files can still be matched by the field
+	TokenMask MaskStrategy = "token"
+)
+
+// UnmarshalYAML interface is implemented to give an error as soon as an unknown mask strategy is used on the yaml.
+// See https://godoc.org/gopkg.in/yaml.v2#Unmarshaler for more details
+func (maskStrategy *MaskStrategy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var strategyName string
+	if err := unmarshal(&strategyName); err != nil {
+		return err
+	}
+
+	switch MaskStrategy(strategyName) {
+	case FixedMask, FormatMask, FakeMask, TokenMask:
+		*maskStrategy = MaskStrategy(strategyName)
+		return nil
+	}
+	return fmt.Errorf("unknown mask %q, it must be one of: fixed, format, fake, token", strategyName)
+}
+
+// FakeGenerator is the kind of fake data used by the FakeMask strategy
+type FakeGenerator string
+
+const (
+	// NameGenerator creates the names of people, e.g. "Ana Souza"
+	NameGenerator FakeGenerator = "name"
+
+	// CompanyGenerator creates the names of companies
+	CompanyGenerator FakeGenerator = "company"
+
+	// CPFGenerator creates valid CPFs, the Brazilian individual taxpayer numbers, which need 11 positions, or 14 when the
+	// content is written with dots and a hyphen
+	CPFGenerator FakeGenerator = "cpf"
+
+	// CNPJGenerator creates valid CNPJs, the Brazilian company taxpayer numbers, which need 14 positions, or 18 when the
+	// content is written with dots, a slash and a hyphen
+	CNPJGenerator FakeGenerator = "cnpj"
+)
+
+// UnmarshalYAML interface is implemented to give an error as soon as an unknown generator is used on the yaml.
+// See https://godoc.org/gopkg.in/yaml.v2#Unmarshaler for more details
+func (generator *FakeGenerator) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var generatorName string
+	if err := unmarshal(&generatorName); err != nil {
+		return err
+	}
+
+	switch FakeGenerator(generatorName) {
+	case NameGenerator, CompanyGenerator, CPFGenerator, CNPJGenerator:
+		*generator = FakeGenerator(generatorName)
+		return nil
+	}
+	return fmt.Errorf("unknown generator %q, it must be one of: name, company, cpf, cnpj", generatorName)
+}
+
+// MaskStrategyOrDefault returns the strategy used to mask the field, which is FixedMask when none is declared
+func (field Field) MaskStrategyOrDefault() MaskStrategy {
+	if field.Mask == "" {
+		return FixedMask
+	}
+	return field.Mask
+}
+
+// validateMask returns an error if the mask of the field is declared on a field that is not sensitive, or if its strategy
+// can not be used on the field
+func (field Field) validateMask() error {
+	if !field.Sensitive && (field.Mask != "" || field.Generator != "") {
+		return fmt.Errorf("the field %q has a mask but it is not sensitive", field.Name)
+	}
+	if field.Mask == FakeMask && field.Generator == "" {
+		return fmt.Errorf("the field %q is masked with fake data but it does not have a generator", field.Name)
+	}
+	if field.Mask != FakeMask && field.Generator != "" {
+		return fmt.Errorf("the field %q has a generator but it is not masked with fake data", field.Name)
+	}
+	if field.Mask == TokenMask && field.Type == DateType {
+		return fmt.Errorf("the date field %q can not be masked with tokens, use the format mask instead", field.Name)
+	}
+
+	isNumber := field.Type == IntegerType || field.Type == DecimalType
+	switch field.Generator {
+	case NameGenerator, CompanyGenerator:
+		if isNumber || field.Type == DateType {
+			return fmt.Errorf("the %v field %q can not be masked with fake %v", field.Type, field.Name, field.Generator)
+		}
+	case CPFGenerator, CNPJGenerator:
+		if field.Type == DateType || field.Type == DecimalType {
+			return fmt.Errorf("the %v field %q can not be masked with fake %v", field.Type, field.Name, field.Generator)
+		}
+		minimumLength := 11
+		if field.Generator == CNPJGenerator {
+			minimumLength = 14
+		}
+		if field.Length() < minimumLength {
+			return fmt.Errorf("the field %q needs %v positions to be masked with fake %v", field.Name, minimumLength, field.Generator)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package yamlconfig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestField_validateMask(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		wantErr bool
+	}{
+		{"Should accept a sensitive field without mask", Field{Name: "name", Initial: 1, End: 10, Sensitive: true}, false},
+		{"Should accept fake names on string fields", Field{Name: "name", Initial: 1, End: 10, Sensitive: true, Mask: FakeMask, Generator: NameGenerator}, false},
+		{"Should accept fake cpfs on integer fields", Field{Name: "cpf", Initial: 1, End: 11, Type: IntegerType, Sensitive: true, Mask: FakeMask, Generator: CPFGenerator}, false},
+		{"Should get error on masks of fields that are not sensitive", Field{Name: "name", Initial: 1, End: 10, Mask: FormatMask}, true},
+		{"Should get error on fake data without generator", Field{Name: "name", Initial: 1, End: 10, Sensitive: true, Mask: FakeMask}, true},
+		{"Should get error on generators without fake data", Field{Name: "name", Initial: 1, End: 10, Sensitive: true, Generator: NameGenerator}, true},
+		{"Should get error on tokens of date fields", Field{Name: "birth", Initial: 1, End: 8, Type: DateType, Sensitive: true, Mask: TokenMask}, true},
+		{"Should get error on fake names of integer fields", Field{Name: "id", Initial: 1, End: 10, Type: IntegerType, Sensitive: true, Mask: FakeMask, Generator: NameGenerator}, true},
+		{"Should get error on fake cnpjs of short fields", Field{Name: "cnpj", Initial: 1, End: 11, Sensitive: true, Mask: FakeMask, Generator: CNPJGenerator}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.field.validateMask(); (err != nil) != tt.wantErr {
+				t.Errorf("Field.validateMask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadConfiguration_masks(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    Field
+		wantErr bool
+	}{
+		{
+			name: "Should read the mask of a sensitive field",
+			yaml: "records:\n - name: a\n   regex: a\n   fields:\n    - {name: name, initial: 1, end: 10, sensitive: true, mask: fake, generator: name}",
+			want: Field{Name: "name", Initial: 1, End: 10, Sensitive: true, Mask: FakeMask, Generator: NameGenerator},
+		},
+		{
+			name:    "Should get error on unknown masks",
+			yaml:    "records:\n - name: a\n   regex: a\n   fields:\n    - {name: name, initial: 1, end: 10, sensitive: true, mask: blur}",
+			wantErr: true,
+		},
+		{
+			name:    "Should get error on unknown generators",
+			yaml:    "records:\n - name: a\n   regex: a\n   fields:\n    - {name: name, initial: 1, end: 10, sensitive: true, mask: fake, generator: pet}",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadConfiguration([]byte(tt.yaml))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadConfiguration() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.Records[0].Fields[0] != tt.want {
+				t.Errorf("ReadConfiguration() field = %v, want %v", got.Records[0].Fields[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestField_FormatDate(t *testing.T) {
+	date := time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)
+	if got := (Field{Type: DateType}).FormatDate(date); got != "20200131" {
+		t.Errorf("Field.FormatDate() = %v, want 20200131", got)
+	}
+	if got := (Field{Type: DateType, Format: "DD/MM/YY"}).FormatDate(date); got != "31/01/20" {
+		t.Errorf("Field.FormatDate() = %v, want 31/01/20", got)
+	}
+}
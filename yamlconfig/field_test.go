@@ -2,6 +2,7 @@ package yamlconfig
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -88,34 +89,34 @@ func Test_sortFieldsByInitialPositionAsc(t *testing.T) {
 		{
 			name: "Slice with one Field should remain the same",
 			args: args{[]Field{
-				{"", 5, 5},
+				{Initial: 5, End: 5},
 			}},
-			want: []Field{{"", 5, 5}},
+			want: []Field{{Initial: 5, End: 5}},
 		},
 		{
 			name: "Slice sorted by Initial desc should be sorted by Initial asc",
 			args: args{[]Field{
-				{"", 5, 5},
-				{"", 1, 1},
+				{Initial: 5, End: 5},
+				{Initial: 1, End: 1},
 			}},
-			want: []Field{{"", 1, 1}, {"", 5, 5}},
+			want: []Field{{Initial: 1, End: 1}, {Initial: 5, End: 5}},
 		},
 		{
 			name: "Slice sorted by Initial asc should remain the same",
 			args: args{[]Field{
-				{"", 1, 1},
-				{"", 5, 5},
+				{Initial: 1, End: 1},
+				{Initial: 5, End: 5},
 			}},
-			want: []Field{{"", 1, 1}, {"", 5, 5}},
+			want: []Field{{Initial: 1, End: 1}, {Initial: 5, End: 5}},
 		},
 		{
 			name: "Unsorted slice should be sorted",
 			args: args{[]Field{
-				{"", 5, 5},
-				{"", 1, 1},
-				{"", 10, 10},
+				{Initial: 5, End: 5},
+				{Initial: 1, End: 1},
+				{Initial: 10, End: 10},
 			}},
-			want: []Field{{"", 1, 1}, {"", 5, 5}, {"", 10, 10}},
+			want: []Field{{Initial: 1, End: 1}, {Initial: 5, End: 5}, {Initial: 10, End: 10}},
 		},
 	}
 	for _, tt := range tests {
@@ -142,32 +143,32 @@ func Test_existsConflict(t *testing.T) {
 	}{
 		{
 			name: "Should not detect any conflict",
-			args: args{field1: Field{"", 1, 2}, field2: Field{"", 3, 4}},
+			args: args{field1: Field{Initial: 1, End: 2}, field2: Field{Initial: 3, End: 4}},
 			want: false,
 		},
 		{
 			name: "Should not detect any conflict",
-			args: args{field1: Field{"", 3, 4}, field2: Field{"", 1, 2}},
+			args: args{field1: Field{Initial: 3, End: 4}, field2: Field{Initial: 1, End: 2}},
 			want: false,
 		},
 		{
 			name: "Should detect conflict - field2's initial is the same as field1's end",
-			args: args{field1: Field{"", 1, 2}, field2: Field{"", 2, 3}},
+			args: args{field1: Field{Initial: 1, End: 2}, field2: Field{Initial: 2, End: 3}},
 			want: true,
 		},
 		{
 			name: "Should detect conflict - field2's positions are inside field1's",
-			args: args{field1: Field{"", 1, 5}, field2: Field{"", 2, 3}},
+			args: args{field1: Field{Initial: 1, End: 5}, field2: Field{Initial: 2, End: 3}},
 			want: true,
 		},
 		{
 			name: "Should detect conflict - field1's positions are inside field2's",
-			args: args{field1: Field{"", 2, 3}, field2: Field{"", 1, 5}},
+			args: args{field1: Field{Initial: 2, End: 3}, field2: Field{Initial: 1, End: 5}},
 			want: true,
 		},
 		{
 			name:    "Should give error due to invalid field",
-			args:    args{field1: Field{"", 0, 1}, field2: Field{"", 2, 5}},
+			args:    args{field1: Field{Initial: 0, End: 1}, field2: Field{Initial: 2, End: 5}},
 			want:    false,
 			wantErr: true,
 		},
@@ -190,17 +191,17 @@ func Test_existsConflict(t *testing.T) {
 
 func Test_existsConflictOnFields(t *testing.T) {
 	var fieldsWithConflicts = []Field{
-		{"", 1, 1},
-		{"", 2, 3},
-		{"", 4, 5},
-		{"", 5, 6},
+		{Initial: 1, End: 1},
+		{Initial: 2, End: 3},
+		{Initial: 4, End: 5},
+		{Initial: 5, End: 6},
 	}
 
 	var unsortedfieldsWithConflicts = []Field{
-		{"", 4, 5},
-		{"", 2, 3},
-		{"", 5, 6},
-		{"", 1, 1},
+		{Initial: 4, End: 5},
+		{Initial: 2, End: 3},
+		{Initial: 5, End: 6},
+		{Initial: 1, End: 1},
 	}
 
 	type args struct {
@@ -259,288 +260,6 @@ func Test_existsConflictOnFields(t *testing.T) {
 	}
 }
 
-func Test_getStringBeforeField(t *testing.T) {
-
-	type args struct {
-		s     string
-		field Field
-	}
-	tests := []struct {
-		name        string
-		args        args
-		want        string
-		expectPanic bool
-	}{
-		{
-			name: "String before field should be empty string",
-			args: args{s: "", field: Field{"", 1, 2}},
-			want: "",
-		},
-		{
-			name: "String before field should be empty string",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 1, 35}},
-			want: "",
-		},
-		{
-			name:        "Should panic due to invalid field",
-			args:        args{s: "", field: Field{"", 0, 2}},
-			expectPanic: true,
-		},
-		{
-			name: "Should get the string before the field",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 1, 1}},
-			want: "",
-		},
-		{
-			name: "Should get the string before the field",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 1, 2}},
-			want: "",
-		},
-		{
-			name: "Should get the string before the field",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 2, 2}},
-			want: "t",
-		},
-		{
-			name: "Should get the string before the field",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 4, 4}},
-			want: "the",
-		},
-		{
-			name: "Should get the string before the field with end bigger than string length",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 4, 100}},
-			want: "the",
-		},
-		{
-			name: "Should get the string before the field with initial bigger than string length",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 100, 200}},
-			want: "thequickbrownfoxjumpsoverthelazydog",
-		},
-		{
-			name: "Should correctly get the string with accents before the field",
-			args: args{s: "ÇÇÇÇÇuickbrownfoxjumpsoverthelazydog", field: Field{"", 2, 5}},
-			want: "Ç",
-		},
-		{
-			name: "Should correctly get the string before the field - testing edge cases",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 34, 35}},
-			want: "thequickbrownfoxjumpsoverthelazyd",
-		},
-		{
-			name: "Should correctly get the string before the field - testing edge cases",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 35, 35}},
-			want: "thequickbrownfoxjumpsoverthelazydo",
-		},
-		{
-			name: "Should correctly get the string before the field - testing edge cases",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 35, 36}},
-			want: "thequickbrownfoxjumpsoverthelazydo",
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-
-			if tt.expectPanic {
-				defer func() {
-					if r := recover(); r == nil {
-						t.Error("Error - was expecting function to panic")
-						return
-					}
-				}()
-			}
-
-			if got := getStringBeforeField(tt.args.s, tt.args.field); got != tt.want {
-				t.Errorf("getStringBeforeField() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_getStringOfField(t *testing.T) {
-	type args struct {
-		s     string
-		field Field
-	}
-	tests := []struct {
-		name        string
-		args        args
-		want        string
-		expectPanic bool
-	}{
-		{
-			name: "String of field should be empty string",
-			args: args{s: "", field: Field{"", 1, 2}},
-			want: "",
-		},
-		{
-			name:        "Should panic due to invalid field",
-			args:        args{s: "", field: Field{"", 0, 2}},
-			expectPanic: true,
-		},
-		{
-			name: "Should get the string of the field",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 1, 1}},
-			want: "t",
-		},
-		{
-			name: "Should get the string of the field",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 1, 2}},
-			want: "th",
-		},
-		{
-			name: "Should get the string of the field",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 2, 2}},
-			want: "h",
-		},
-		{
-			name: "Should get the string of the field",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 4, 8}},
-			want: "quick",
-		},
-		{
-			name: "Should get the string of the field with end bigger than string length",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 4, 100}},
-			want: "quickbrownfoxjumpsoverthelazydog",
-		},
-		{
-			name: "Should get the string of the field with initial bigger than string length",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 100, 200}},
-			want: "",
-		},
-		{
-			name: "Should get the string of the field",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 1, 35}},
-			want: "thequickbrownfoxjumpsoverthelazydog",
-		},
-		{
-			name: "Should correctly get the string with accents of field",
-			args: args{s: "ÇÇÇÇÇuickbrownfoxjumpsoverthelazydog", field: Field{"", 2, 5}},
-			want: "ÇÇÇÇ",
-		},
-		{
-			name: "Should correctly get the string of the field - testing edge cases",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 34, 35}},
-			want: "og",
-		},
-		{
-			name: "Should correctly get the string of the field - testing edge cases",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 35, 35}},
-			want: "g",
-		},
-		{
-			name: "Should correctly get the string of the field - testing edge cases",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 35, 36}},
-			want: "g",
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.expectPanic {
-				defer func() {
-					if r := recover(); r == nil {
-						t.Error("Error - was expecting function to panic")
-						return
-					}
-				}()
-			}
-
-			if got := getStringOfField(tt.args.s, tt.args.field); got != tt.want {
-				t.Errorf("getStringOfField() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_getStringAfterField(t *testing.T) {
-	type args struct {
-		s     string
-		field Field
-	}
-	tests := []struct {
-		name        string
-		args        args
-		want        string
-		expectPanic bool
-	}{
-		{
-			name: "String after field should be empty string",
-			args: args{s: "", field: Field{"", 1, 2}},
-			want: "",
-		},
-		{
-			name: "String after field should be empty string",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 1, 35}},
-			want: "",
-		},
-		{
-			name:        "Should panic due to invalid field",
-			args:        args{s: "", field: Field{"", 0, 2}},
-			expectPanic: true,
-		},
-		{
-			name: "Should get the string after the field",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 1, 1}},
-			want: "hequickbrownfoxjumpsoverthelazydog",
-		},
-		{
-			name: "Should get the string after the field",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 2, 2}},
-			want: "equickbrownfoxjumpsoverthelazydog",
-		},
-		{
-			name: "Should get the string after the field",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 4, 8}},
-			want: "brownfoxjumpsoverthelazydog",
-		},
-		{
-			name: "Should get the string after the field with end bigger than string length",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 4, 100}},
-			want: "",
-		},
-		{
-			name: "Should get the string of the field with initial bigger than string length",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 100, 200}},
-			want: "",
-		},
-		{
-			name: "Should correctly get the string with accents after field",
-			args: args{s: "ÇÇÇÇÇuickbrownfoxjumpsoverthelazydog", field: Field{"", 2, 5}},
-			want: "uickbrownfoxjumpsoverthelazydog",
-		},
-		{
-			name: "Should correctly get the string after field - testing edge cases",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 34, 35}},
-			want: "",
-		},
-		{
-			name: "Should correctly get the string of the field - testing edge cases",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 35, 35}},
-			want: "",
-		},
-		{
-			name: "Should correctly get the string of the field - testing edge cases",
-			args: args{s: "thequickbrownfoxjumpsoverthelazydog", field: Field{"", 35, 36}},
-			want: "",
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.expectPanic {
-				defer func() {
-					if r := recover(); r == nil {
-						t.Error("Error - was expecting function to panic")
-						return
-					}
-				}()
-			}
-
-			if got := getStringAfterField(tt.args.s, tt.args.field); got != tt.want {
-				t.Errorf("getStringAfterField() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 type marker struct{}
 
 func (m marker) ObtainInitialMarker(field Field) string {
@@ -550,6 +269,14 @@ func (m marker) ObtainEndMarker(field Field) string {
 	return ">"
 }
 
+type escaperMarker struct {
+	marker
+}
+
+func (m escaperMarker) Escape(s string) string {
+	return strings.ToUpper(s)
+}
+
 func Test_ApplyMarkerToFieldsOnString(t *testing.T) {
 
 	customMarker := marker{}
@@ -568,8 +295,8 @@ func Test_ApplyMarkerToFieldsOnString(t *testing.T) {
 			args: args{
 				marker: customMarker,
 				fields: []Field{
-					{"", 4, 8},
-					{"", 17, 21},
+					{Initial: 4, End: 8},
+					{Initial: 17, End: 21},
 				},
 				s: "thequickbrownfoxjumpsoverthelazydog",
 			},
@@ -580,10 +307,10 @@ func Test_ApplyMarkerToFieldsOnString(t *testing.T) {
 			args: args{
 				marker: customMarker,
 				fields: []Field{
-					{"", 9, 13},
-					{"", 4, 8},
-					{"", 17, 21},
-					{"", 14, 16},
+					{Initial: 9, End: 13},
+					{Initial: 4, End: 8},
+					{Initial: 17, End: 21},
+					{Initial: 14, End: 16},
 				},
 				s: "thequickbrownfoxjumpsoverthelazydog",
 			},
@@ -594,7 +321,7 @@ func Test_ApplyMarkerToFieldsOnString(t *testing.T) {
 			args: args{
 				marker: customMarker,
 				fields: []Field{
-					{"", 1, 35},
+					{Initial: 1, End: 35},
 				},
 				s: "thequickbrownfoxjumpsoverthelazydog",
 			},
@@ -605,8 +332,8 @@ func Test_ApplyMarkerToFieldsOnString(t *testing.T) {
 			args: args{
 				marker: customMarker,
 				fields: []Field{
-					{"", 1, 1},
-					{"", 35, 35},
+					{Initial: 1, End: 1},
+					{Initial: 35, End: 35},
 				},
 				s: "thequickbrownfoxjumpsoverthelazydog",
 			},
@@ -617,7 +344,7 @@ func Test_ApplyMarkerToFieldsOnString(t *testing.T) {
 			args: args{
 				marker: customMarker,
 				fields: []Field{
-					{"", 34, 100},
+					{Initial: 34, End: 100},
 				},
 				s: "thequickbrownfoxjumpsoverthelazydog",
 			},
@@ -628,7 +355,7 @@ func Test_ApplyMarkerToFieldsOnString(t *testing.T) {
 			args: args{
 				marker: customMarker,
 				fields: []Field{
-					{"", 2, 5},
+					{Initial: 2, End: 5},
 				},
 				s: "ÇÇÇÇÇuickbrownfoxjumpsoverthelazydog",
 			},
@@ -639,12 +366,47 @@ func Test_ApplyMarkerToFieldsOnString(t *testing.T) {
 			args: args{
 				marker: customMarker,
 				fields: []Field{
-					{"", 100, 200},
+					{Initial: 100, End: 200},
 				},
 				s: "thequickbrownfoxjumpsoverthelazydog",
 			},
 			want: "thequickbrownfoxjumpsoverthelazydog",
 		},
+		{
+			name: "Should mark the string correctly with accents before other fields",
+			args: args{
+				marker: customMarker,
+				fields: []Field{
+					{Initial: 1, End: 2},
+					{Initial: 4, End: 5},
+				},
+				s: "ÇÇÇÇÇuick",
+			},
+			want: "<ÇÇ>Ç<ÇÇ>uick",
+		},
+		{
+			name: "Should mark only the fields that exist on a string shorter than the fields",
+			args: args{
+				marker: customMarker,
+				fields: []Field{
+					{Initial: 1, End: 19},
+					{Initial: 20, End: 21},
+				},
+				s: "the",
+			},
+			want: "<the>",
+		},
+		{
+			name: "Should escape the content of the string when the marker is an escaper",
+			args: args{
+				marker: escaperMarker{},
+				fields: []Field{
+					{Initial: 4, End: 8},
+				},
+				s: "thequickbrownfox",
+			},
+			want: "THE<QUICK>BROWNFOX",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,249 @@
+package yamlconfig
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType is the type of the content of a field, which is used to decode and validate it
+type FieldType string
+
+const (
+	// StringType is the default type, in which the content is used as it is
+	StringType FieldType = "string"
+
+	// IntegerType is a whole number, which may be padded with zeros or spaces and may have a sign
+	IntegerType FieldType = "integer"
+
+	// DecimalType is a number with a given number of implied decimal places, e.g. "0012345" with 2 decimals is 123.45.
+	// An explicit decimal point is also accepted
+	DecimalType FieldType = "decimal"
+
+	// DateType is a date written in the field's format, e.g. "YYYYMMDD"
+	DateType FieldType = "date"
+)
+
+// defaultDateFormat is the format used by date fields that do not declare one
+const defaultDateFormat = "YYYYMMDD"
+
+// decimalRegex matches the decimals that may be written on a DecimalType field: an optional sign, digits and an optional
+// decimal point followed by digits. Exponents, hexadecimal numbers, "NaN" and "Inf" are not decimals of a file
+var decimalRegex = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
+
+// dateFormatTokens maps the tokens that may be used on a field's date format to the equivalent Go layout
+var dateFormatTokens = []struct {
+	token  string
+	layout string
+}{
+	{"YYYY", "2006"},
+	{"YY", "06"},
+	{"MM", "01"},
+	{"DD", "02"},
+	{"hh", "15"},
+	{"mm", "04"},
+	{"ss", "05"},
+}
+
+// UnmarshalYAML interface is implemented to give an error as soon as an unknown type is used on the yaml.
+// See https://godoc.org/gopkg.in/yaml.v2#Unmarshaler for more details
+func (fieldType *FieldType) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var typeName string
+	if err := unmarshal(&typeName); err != nil {
+		return err
+	}
+
+	switch FieldType(typeName) {
+	case StringType, IntegerType, DecimalType, DateType:
+		*fieldType = FieldType(typeName)
+		return nil
+	}
+	return fmt.Errorf("unknown field type %q, it must be one of: string, integer, decimal, date", typeName)
+}
+
+// dateLayout converts a format such as "DD/MM/YYYY" to the equivalent Go layout, "02/01/2006"
+func dateLayout(format string) string {
+	if format == "" {
+		format = defaultDateFormat
+	}
+
+	var layout strings.Builder
+	for len(format) > 0 {
+		isTokenFound := false
+		for _, dateFormatToken := range dateFormatTokens {
+			if strings.HasPrefix(format, dateFormatToken.token) {
+				layout.WriteString(dateFormatToken.layout)
+				format = format[len(dateFormatToken.token):]
+				isTokenFound = true
+				break
+			}
+		}
+		if !isTokenFound {
+			layout.WriteByte(format[0])
+			format = format[1:]
+		}
+	}
+	return layout.String()
+}
+
+// RawValue returns the content of the field on a given line, as it is. If the line is shorter than the field, only
+// the part of the field that exists on the line is returned
+func (field Field) RawValue(line string) string {
+	runes := []rune(strings.TrimRight(line, "\r\n"))
+	if field.Initial < 1 || field.Initial > len(runes) {
+		return ""
+	}
+
+	end := field.End
+	if end > len(runes) {
+		end = len(runes)
+	}
+	return string(runes[field.Initial-1 : end])
+}
+
+// Length returns how many characters the field has
+func (field Field) Length() int {
+	return field.End - field.Initial + 1
+}
+
+// ParseValue decodes a raw value according to the field's type, like ParseTypedValue. An error is returned if the value is
+// not valid for the field's type or if it is not one of the known values of the field. Check digits are not verified, see
+// Record.ParseFieldValue
+func (field Field) ParseValue(rawValue string) (interface{}, error) {
+	if err := field.validateKnownValue(rawValue); err != nil {
+		return nil, err
+	}
+	return field.ParseTypedValue(rawValue)
+}
+
+// ParseTypedValue decodes a raw value according to the field's type only. Strings are returned as they are, integers as
+// int64, decimals as float64 and dates as time.Time. Blank values of the other types are considered empty, and nil is
+// returned. An error is returned if the value is not valid for the field's type, while unknown values and wrong check
+// digits are accepted, so that they can still be sorted, summed and queried
+func (field Field) ParseTypedValue(rawValue string) (interface{}, error) {
+	if field.Type == "" || field.Type == StringType {
+		return rawValue, nil
+	}
+
+	value := strings.TrimSpace(rawValue)
+	if value == "" {
+		return nil, nil
+	}
+
+	switch field.Type {
+	case IntegerType:
+		parsedValue, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %q is not a valid integer", field.Name, rawValue)
+		}
+		return parsedValue, nil
+
+	case DecimalType:
+		if !decimalRegex.MatchString(value) {
+			return nil, fmt.Errorf("field %q: %q is not a valid decimal", field.Name, rawValue)
+		}
+		parsedValue, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %q is not a valid decimal", field.Name, rawValue)
+		}
+		if !strings.Contains(value, ".") {
+			parsedValue = parsedValue / math.Pow10(field.Decimals)
+		}
+		return parsedValue, nil
+
+	case DateType:
+		format := field.Format
+		if format == "" {
+			format = defaultDateFormat
+		}
+		parsedValue, err := time.Parse(dateLayout(format), value)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %q is not a valid date in the format %v", field.Name, rawValue, format)
+		}
+		return parsedValue, nil
+	}
+
+	return nil, fmt.Errorf("field %q: unknown type %q", field.Name, field.Type)
+}
+
+// FormatDate returns a date written in the format of the field, e.g. "20200131" with the default format
+func (field Field) FormatDate(date time.Time) string {
+	return date.Format(dateLayout(field.Format))
+}
+
+// EncodeValue returns the content of the field for a given value, with the length of the field. Integers and decimals are
+// aligned to the right and padded with zeros, after their signs, and the other types are aligned to the left and padded with
+// spaces. A decimal with a decimal point, e.g. "123.4", is written with the implied decimal places of the field, e.g. "12340"
+// with 2 decimals, while a decimal without one is taken as it is. A blank value is written as spaces.
+// An error is returned if the value is not valid for the type of the field or if it does not fit on the field
+func (field Field) EncodeValue(value string) (string, error) {
+	content := strings.TrimSpace(value)
+	if content == "" {
+		return strings.Repeat(" ", field.Length()), nil
+	}
+
+	isNumber := field.Type == IntegerType || field.Type == DecimalType
+	if field.Type == DecimalType && strings.Contains(content, ".") {
+		parts := strings.SplitN(content, ".", 2)
+		if len(parts[1]) > field.Decimals {
+			return "", fmt.Errorf("field %q: %q has more than %v decimal places", field.Name, value, field.Decimals)
+		}
+		content = parts[0] + parts[1] + strings.Repeat("0", field.Decimals-len(parts[1]))
+	}
+	if !isNumber {
+		content = value
+	}
+
+	if _, err := field.ParseValue(content); err != nil {
+		return "", err
+	}
+
+	padding := field.Length() - len([]rune(content))
+	if padding < 0 {
+		return "", fmt.Errorf("field %q: %q does not fit on %v positions", field.Name, value, field.Length())
+	}
+	if !isNumber {
+		return content + strings.Repeat(" ", padding), nil
+	}
+
+	sign := ""
+	if strings.HasPrefix(content, "-") || strings.HasPrefix(content, "+") {
+		sign, content = content[:1], content[1:]
+	}
+	return sign + strings.Repeat("0", padding) + content, nil
+}
+
+// ReplaceValue returns a line with the content of the field replaced by a given content, which should have the length of the
+// field, e.g. a content returned by EncodeValue. Lines shorter than the field are padded with spaces up to it
+func (field Field) ReplaceValue(line string, content string) string {
+	runes := []rune(line)
+	if len(runes) < field.Initial-1 {
+		runes = append(runes, []rune(strings.Repeat(" ", field.Initial-1-len(runes)))...)
+	}
+
+	before := string(runes[:field.Initial-1])
+	after := ""
+	if field.End < len(runes) {
+		after = string(runes[field.End:])
+	}
+	return before + content + after
+}
+
+// FormatValue returns a human readable representation of a value returned by ParseValue
+func FormatValue(value interface{}) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if typedValue.Hour() == 0 && typedValue.Minute() == 0 && typedValue.Second() == 0 {
+			return typedValue.Format("2006-01-02")
+		}
+		return typedValue.Format("2006-01-02 15:04:05")
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,206 @@
+package yamlconfig
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestField_RawValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		field Field
+		line  string
+		want  string
+	}{
+		{"Should return the content of the field", Field{Initial: 4, End: 8}, "thequickbrownfox", "quick"},
+		{"Should return the part of the field that exists on the line", Field{Initial: 14, End: 20}, "thequickbrownfox", "fox"},
+		{"Should return empty when the line is shorter than the field", Field{Initial: 20, End: 30}, "thequickbrownfox", ""},
+		{"Should not return the line break", Field{Initial: 14, End: 20}, "thequickbrownfox\r\n", "fox"},
+		{"Should count accented characters as one position", Field{Initial: 2, End: 3}, "ÇÇÇÇ", "ÇÇ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.field.RawValue(tt.line); got != tt.want {
+				t.Errorf("Field.RawValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestField_ParseValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    Field
+		rawValue string
+		want     interface{}
+		wantErr  bool
+	}{
+		{"Should return strings as they are", Field{}, " abc ", " abc ", false},
+		{"Should parse integers padded with zeros", Field{Type: IntegerType}, "00042", int64(42), false},
+		{"Should parse integers padded with spaces and signs", Field{Type: IntegerType}, "  -42", int64(-42), false},
+		{"Should get error on invalid integers", Field{Type: IntegerType}, "4x2", nil, true},
+		{"Should parse decimals with implied decimal places", Field{Type: DecimalType, Decimals: 2}, "0012345", 123.45, false},
+		{"Should parse decimals with an explicit decimal point", Field{Type: DecimalType, Decimals: 2}, "123.4", 123.4, false},
+		{"Should get error on invalid decimals", Field{Type: DecimalType}, "12,5", nil, true},
+		{"Should get error on NaN decimals", Field{Type: DecimalType}, "NaN", nil, true},
+		{"Should get error on infinite decimals", Field{Type: DecimalType}, "  -Inf", nil, true},
+		{"Should get error on decimals with exponents", Field{Type: DecimalType, Decimals: 2}, "1e5", nil, true},
+		{"Should get error on hexadecimal decimals", Field{Type: DecimalType}, "0x1p-2", nil, true},
+		{"Should parse signed decimals with an explicit decimal point", Field{Type: DecimalType, Decimals: 2}, "-12.5", -12.5, false},
+		{"Should parse dates with the default format", Field{Type: DateType}, "20200131", time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC), false},
+		{"Should parse dates with a custom format", Field{Type: DateType, Format: "DD/MM/YY"}, "31/01/20", time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC), false},
+		{"Should get error on invalid dates", Field{Type: DateType}, "20201331", nil, true},
+		{"Should consider blank typed values empty", Field{Type: DateType}, "        ", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.field.ParseValue(tt.rawValue)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Field.ParseValue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Field.ParseValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestField_EncodeValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"Should pad strings with spaces on the right", Field{Initial: 1, End: 5}, "ab", "ab   ", false},
+		{"Should keep the spaces of strings", Field{Initial: 1, End: 5}, " ab", " ab  ", false},
+		{"Should pad integers with zeros on the left", Field{Initial: 1, End: 5, Type: IntegerType}, "42", "00042", false},
+		{"Should pad integers with zeros after their signs", Field{Initial: 1, End: 5, Type: IntegerType}, "-42", "-0042", false},
+		{"Should write decimals with implied decimal places", Field{Initial: 1, End: 7, Type: DecimalType, Decimals: 2}, "123.4", "0012340", false},
+		{"Should take decimals without a decimal point as they are", Field{Initial: 1, End: 7, Type: DecimalType, Decimals: 2}, "12340", "0012340", false},
+		{"Should pad dates with spaces on the right", Field{Initial: 1, End: 10, Type: DateType, Format: "DDMMYYYY"}, "31012021", "31012021  ", false},
+		{"Should write blank values as spaces", Field{Initial: 1, End: 3, Type: IntegerType}, "", "   ", false},
+		{"Should get error on values that do not fit", Field{Initial: 1, End: 3}, "abcd", "", true},
+		{"Should get error on decimals with more decimal places", Field{Initial: 1, End: 7, Type: DecimalType, Decimals: 2}, "1.234", "", true},
+		{"Should get error on invalid values", Field{Initial: 1, End: 8, Type: DateType}, "20211331", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.field.EncodeValue(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Field.EncodeValue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Field.EncodeValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestField_ReplaceValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		line    string
+		content string
+		want    string
+	}{
+		{"Should replace the content of the field", Field{Initial: 4, End: 8}, "thequickbrownfox", "QUICK", "theQUICKbrownfox"},
+		{"Should replace the end of the line", Field{Initial: 14, End: 16}, "thequickbrownfox", "FOX", "thequickbrownFOX"},
+		{"Should pad short lines up to the field", Field{Initial: 6, End: 7}, "the", "AB", "the  AB"},
+		{"Should count accented characters as one position", Field{Initial: 2, End: 3}, "ÇÇÇÇ", "ab", "ÇabÇ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.field.ReplaceValue(tt.line, tt.content); got != tt.want {
+				t.Errorf("Field.ReplaceValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_dateLayout(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"", "20060102"},
+		{"DD/MM/YYYY", "02/01/2006"},
+		{"YYMMDDhhmmss", "060102150405"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := dateLayout(tt.format); got != tt.want {
+				t.Errorf("dateLayout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"Should format empty values", nil, ""},
+		{"Should format integers", int64(42), "42"},
+		{"Should format decimals", 123.45, "123.45"},
+		{"Should format dates", time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC), "2020-01-31"},
+		{"Should format dates with time", time.Date(2020, 1, 31, 10, 20, 30, 0, time.UTC), "2020-01-31 10:20:30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatValue(tt.value); got != tt.want {
+				t.Errorf("FormatValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadConfiguration_FieldTypes(t *testing.T) {
+	validYAML := `
+        records:
+         - name: "record A"
+           fields:
+            - name: "amount"
+              description: "the amount, in cents"
+              initial: 1
+              end: 10
+              type: decimal
+              decimals: 2
+            - name: "date"
+              initial: 11
+              end: 18
+              type: date
+              format: DDMMYYYY`
+
+	configuration, err := ReadConfiguration([]byte(validYAML))
+	if err != nil {
+		t.Fatalf("ReadConfiguration() error = %v", err)
+	}
+	want := []Field{
+		{Name: "amount", Initial: 1, End: 10, Description: "the amount, in cents", Type: DecimalType, Decimals: 2},
+		{Name: "date", Initial: 11, End: 18, Type: DateType, Format: "DDMMYYYY"},
+	}
+	if !reflect.DeepEqual(configuration.Records[0].Fields, want) {
+		t.Errorf("ReadConfiguration() fields = %v, want %v", configuration.Records[0].Fields, want)
+	}
+
+	invalidYAML := `
+        records:
+         - name: "record A"
+           fields:
+            - name: "amount"
+              initial: 1
+              end: 10
+              type: money`
+
+	if _, err = ReadConfiguration([]byte(invalidYAML)); err == nil {
+		t.Errorf("ReadConfiguration() should get error due to an unknown field type")
+	}
+}
@@ -0,0 +1,191 @@
+package yamlconfig
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Values are the known values of a code field along with what they mean, e.g. "01" is "entry" and "02" is "cancellation".
+// On the yaml they are either a map of the values to their descriptions or the location of a csv file, relative to the
+// yaml, whose rows have a value and its description. Values with leading zeros must be quoted on the yaml map
+type Values struct {
+	// Descriptions maps each known value, without the spaces around it, to its description
+	Descriptions map[string]string
+
+	// File is the location of the csv file from which the descriptions are loaded, if they are not declared on the yaml
+	File string
+}
+
+// UnmarshalYAML interface is implemented so that the values can be written either as a map or as the location of a csv file.
+// See https://godoc.org/gopkg.in/yaml.v2#Unmarshaler for more details
+func (values *Values) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var descriptions map[string]string
+	if err := unmarshal(&descriptions); err == nil {
+		values.Descriptions = descriptions
+		return nil
+	}
+
+	var file string
+	if err := unmarshal(&file); err != nil || file == "" {
+		return fmt.Errorf("values must be a map of the values to their descriptions or the location of a csv file")
+	}
+	values.File = file
+	return nil
+}
+
+// MarshalYAML interface is implemented so that values loaded from a csv file are written back as the location of the file.
+// See https://godoc.org/gopkg.in/yaml.v2#Marshaler for more details
+func (values Values) MarshalYAML() (interface{}, error) {
+	if values.File != "" {
+		return values.File, nil
+	}
+	return values.Descriptions, nil
+}
+
+// SortedValues returns the known values in ascending order
+func (values Values) SortedValues() []string {
+	sortedValues := make([]string, 0, len(values.Descriptions))
+	for value := range values.Descriptions {
+		sortedValues = append(sortedValues, value)
+	}
+	sort.Strings(sortedValues)
+	return sortedValues
+}
+
+// HasValues returns true if the field has known values whose descriptions are loaded
+func (field Field) HasValues() bool {
+	return field.Values != nil && field.Values.Descriptions != nil
+}
+
+// ValueDescription returns the description of a raw value of the field and true if the value is one of its known values,
+// and false otherwise
+func (field Field) ValueDescription(rawValue string) (string, bool) {
+	if !field.HasValues() {
+		return "", false
+	}
+	description, isKnown := field.Values.Descriptions[strings.TrimSpace(rawValue)]
+	return description, isKnown
+}
+
+// validateKnownValue returns an error if a raw value that is not blank is not one of the known values of the field.
+// Any value is valid if the field has no known values
+func (field Field) validateKnownValue(rawValue string) error {
+	value := strings.TrimSpace(rawValue)
+	if !field.HasValues() || value == "" {
+		return nil
+	}
+	if _, isKnown := field.Values.Descriptions[value]; !isKnown {
+		return fmt.Errorf("field %q: %q is not one of its known values", field.Name, rawValue)
+	}
+	return nil
+}
+
+// validateValues returns an error if a known value of the field does not fit on it
+func (field Field) validateValues() error {
+	if !field.HasValues() {
+		return nil
+	}
+	for _, value := range field.Values.SortedValues() {
+		if len([]rune(value)) > field.Length() {
+			return fmt.Errorf("field %q: the known value %q does not fit on its %v positions", field.Name, value, field.Length())
+		}
+	}
+	return nil
+}
+
+// LoadValues reads the descriptions of the known values that are declared as csv files, whose locations are relative to
+// the given directory, usually the one of the yaml. An error is returned if a file can not be read or a known value does
+// not fit on its field
+func (configuration Configuration) LoadValues(directory string) error {
+	for _, record := range configuration.Records {
+		for _, field := range record.Fields {
+			if field.Values == nil || field.Values.File == "" {
+				continue
+			}
+			location := field.Values.File
+			if !filepath.IsAbs(location) {
+				location = filepath.Join(directory, location)
+			}
+
+			descriptions, err := readValuesFile(location)
+			if err != nil {
+				return fmt.Errorf("LoadValues(): error - could not read the values of field %q: %v", field.Name, err)
+			}
+			field.Values.Descriptions = descriptions
+			if err = field.validateValues(); err != nil {
+				return fmt.Errorf("LoadValues(): error - %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// RelocateValues returns a copy of the configuration in which the locations of the csv files of the known values, relative
+// to the given directory, are made relative to newDirectory, so that the configuration can be written there. Locations that
+// can not be made relative to newDirectory are made absolute
+func (configuration Configuration) RelocateValues(directory, newDirectory string) (Configuration, error) {
+	newDirectory, err := filepath.Abs(newDirectory)
+	if err != nil {
+		return Configuration{}, fmt.Errorf("RelocateValues(): error - %v", err)
+	}
+
+	relocated := configuration
+	relocated.Records = make([]Record, len(configuration.Records))
+	for i, record := range configuration.Records {
+		record.Fields = append([]Field(nil), record.Fields...)
+		for j, field := range record.Fields {
+			if field.Values == nil || field.Values.File == "" || filepath.IsAbs(field.Values.File) {
+				continue
+			}
+			location, err := filepath.Abs(filepath.Join(directory, field.Values.File))
+			if err != nil {
+				return Configuration{}, fmt.Errorf("RelocateValues(): error - %v", err)
+			}
+			if relativeLocation, err := filepath.Rel(newDirectory, location); err == nil {
+				location = relativeLocation
+			}
+
+			values := *field.Values
+			values.File = filepath.ToSlash(location)
+			record.Fields[j].Values = &values
+		}
+		relocated.Records[i] = record
+	}
+	return relocated, nil
+}
+
+// readValuesFile reads a csv file whose rows have a value and its description. The first row is ignored if it is the
+// header "value,description"
+func readValuesFile(location string) (map[string]string, error) {
+	file, err := os.Open(location)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	descriptions := map[string]string{}
+	for isFirstRow := true; ; isFirstRow = false {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return descriptions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		value, description := strings.TrimSpace(row[0]), strings.TrimSpace(row[1])
+		if isFirstRow && strings.EqualFold(value, "value") && strings.EqualFold(description, "description") {
+			continue
+		}
+		descriptions[value] = description
+	}
+}
@@ -0,0 +1,174 @@
+package yamlconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestField_ParseValue_knownValues(t *testing.T) {
+	field := Field{Name: "code", Initial: 1, End: 3, Type: IntegerType, Values: &Values{Descriptions: map[string]string{"01": "entry", "02": "cancellation"}}}
+
+	tests := []struct {
+		name     string
+		rawValue string
+		want     interface{}
+		wantErr  bool
+	}{
+		{"Should accept a known value", "01 ", int64(1), false},
+		{"Should accept blank values", "   ", nil, false},
+		{"Should get error on unknown values", "03 ", nil, true},
+		{"Should get error on known values with other digits", "1  ", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := field.ParseValue(tt.rawValue)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Field.ParseValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Field.ParseValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if description, isKnown := field.ValueDescription(" 02"); !isKnown || description != "cancellation" {
+		t.Errorf("Field.ValueDescription() = %q, %v, want %q, true", description, isKnown, "cancellation")
+	}
+}
+
+func TestReadConfiguration_values(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    *Values
+		wantErr bool
+	}{
+		{
+			name: "Should read the values from the yaml",
+			yaml: "records:\n - name: a\n   regex: a\n   fields:\n    - name: code\n      initial: 1\n      end: 2\n      values: {'01': entry, '02': cancellation}",
+			want: &Values{Descriptions: map[string]string{"01": "entry", "02": "cancellation"}},
+		},
+		{
+			name: "Should read the location of a csv file",
+			yaml: "records:\n - name: a\n   regex: a\n   fields:\n    - {name: code, initial: 1, end: 2, values: codes.csv}",
+			want: &Values{File: "codes.csv"},
+		},
+		{
+			name:    "Should get error on values that do not fit on the field",
+			yaml:    "records:\n - name: a\n   regex: a\n   fields:\n    - {name: code, initial: 1, end: 2, values: {'001': entry}}",
+			wantErr: true,
+		},
+		{
+			name:    "Should get error on values that are neither a map nor a file",
+			yaml:    "records:\n - name: a\n   regex: a\n   fields:\n    - {name: code, initial: 1, end: 2, values: [entry]}",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadConfiguration([]byte(tt.yaml))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadConfiguration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got.Records[0].Fields[0].Values, tt.want) {
+				t.Errorf("ReadConfiguration() values = %v, want %v", got.Records[0].Fields[0].Values, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfiguration_LoadValues(t *testing.T) {
+	directory, err := ioutil.TempDir("", "fwf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	files := map[string]string{
+		"codes.csv": "value,description\n01,entry\n02,\"cancellation, partial\"\n",
+		"long.csv":  "001,entry\n",
+		"bad.csv":   "01\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(directory, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "Should load the values of a csv file with a header", file: "codes.csv", want: map[string]string{"01": "entry", "02": "cancellation, partial"}},
+		{name: "Should get error on values that do not fit on the field", file: "long.csv", wantErr: true},
+		{name: "Should get error on rows without description", file: "bad.csv", wantErr: true},
+		{name: "Should get error on missing files", file: "missing.csv", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configuration := Configuration{Records: []Record{{Name: "a", Fields: []Field{{Name: "code", Initial: 1, End: 2, Values: &Values{File: tt.file}}}}}}
+			err := configuration.LoadValues(directory)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Configuration.LoadValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := configuration.Records[0].Fields[0].Values.Descriptions; err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Configuration.LoadValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfiguration_RelocateValues(t *testing.T) {
+	absoluteLocation, err := filepath.Abs("codes.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{"Should make a location relative to the new directory", "codes.csv", "../layouts/codes.csv"},
+		{"Should keep a location on a subdirectory", "values/codes.csv", "../layouts/values/codes.csv"},
+		{"Should keep an absolute location", absoluteLocation, absoluteLocation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configuration := Configuration{Version: "2.1", Records: []Record{{Name: "a", Fields: []Field{{Name: "code", Initial: 1, End: 2, Values: &Values{File: tt.file}}}}}}
+			got, err := configuration.RelocateValues("layouts", "extracts")
+			if err != nil {
+				t.Fatalf("Configuration.RelocateValues() error = %v", err)
+			}
+			if got.Version != configuration.Version {
+				t.Errorf("Configuration.RelocateValues() version = %q, want %q", got.Version, configuration.Version)
+			}
+			if file := got.Records[0].Fields[0].Values.File; file != tt.want {
+				t.Errorf("Configuration.RelocateValues() = %v, want %v", file, tt.want)
+			}
+			if configuration.Records[0].Fields[0].Values.File != tt.file {
+				t.Errorf("Configuration.RelocateValues() should not change the given configuration")
+			}
+		})
+	}
+}
+
+func TestWriteConfiguration_values(t *testing.T) {
+	configuration := Configuration{Records: []Record{{Name: "a", Fields: []Field{{Name: "code", Initial: 1, End: 2, Values: &Values{Descriptions: map[string]string{"01": "entry"}, File: "codes.csv"}}}}}}
+	content, err := WriteConfiguration(configuration)
+	if err != nil {
+		t.Fatalf("WriteConfiguration() error = %v", err)
+	}
+	got, err := ReadConfiguration(content)
+	if err != nil {
+		t.Fatalf("ReadConfiguration() error = %v", err)
+	}
+	if want := (&Values{File: "codes.csv"}); !reflect.DeepEqual(got.Records[0].Fields[0].Values, want) {
+		t.Errorf("WriteConfiguration() should write the values loaded from a file as its location, got %v", got.Records[0].Fields[0].Values)
+	}
+}
@@ -1,12 +1,99 @@
 package yamlconfig
 
-import "regexp"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// colorRegex matches the colors that may be used on a record: hexadecimal colors, like "#1565c0", or color names, like "teal"
+var colorRegex = regexp.MustCompile("^(#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6}|[a-zA-Z]+)$")
 
 // Record holds the data of the Records
 type Record struct {
 	Name   string
 	Regex  Regex
 	Fields []Field
+
+	// Color is an optional color used to identify the record on visualizations
+	Color Color `yaml:",omitempty"`
+
+	// Key holds the names of the fields that identify a line of the record, which are used to match lines of different files
+	Key []string `yaml:",omitempty"`
+
+	// Rules are the conditions that every line of the record must meet, which are verified by "fwf validate"
+	Rules []Rule `yaml:",omitempty"`
+}
+
+// Rule is a condition over the fields of a line, written as an expression like the ones of "fwf query", e.g.
+// "due_date >= issue_date" or `IF payment_type = "2" THEN barcode != ""`
+type Rule struct {
+	Name       string
+	Expression string
+}
+
+// validateRules returns an error if a rule of the record has no name or no expression, or if two rules have the same name
+func (record Record) validateRules() error {
+	isNameUsed := map[string]bool{}
+	for _, rule := range record.Rules {
+		if strings.TrimSpace(rule.Name) == "" || strings.TrimSpace(rule.Expression) == "" {
+			return fmt.Errorf("the rules of the record %q must have a name and an expression", record.Name)
+		}
+		if isNameUsed[rule.Name] {
+			return fmt.Errorf("the record %q has more than one rule named %q", record.Name, rule.Name)
+		}
+		isNameUsed[rule.Name] = true
+	}
+	return nil
+}
+
+// validateKey returns an error if any of the key fields of the record is not one of its fields
+func (record Record) validateKey() error {
+	for _, keyField := range record.Key {
+		if _, isFound := record.FieldByName(keyField); !isFound {
+			return fmt.Errorf("the key field %q is not a field of the record %q", keyField, record.Name)
+		}
+	}
+	return nil
+}
+
+// FieldByName returns the field of the record with the given name
+func (record Record) FieldByName(name string) (Field, bool) {
+	for _, field := range record.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
+
+// KeyValues returns the values of the key fields of the record on a given line, without the spaces around them
+func (record Record) KeyValues(line string) []string {
+	values := make([]string, 0, len(record.Key))
+	for _, keyField := range record.Key {
+		field, _ := record.FieldByName(keyField)
+		values = append(values, strings.TrimSpace(field.RawValue(line)))
+	}
+	return values
+}
+
+// Color is a hexadecimal color, like "#1565c0", or a color name, like "teal"
+type Color string
+
+// UnmarshalYAML interface is implemented so that only valid colors are accepted, since they are placed on the html.
+// See https://godoc.org/gopkg.in/yaml.v2#Unmarshaler for more details
+func (color *Color) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var colorString string
+	if err := unmarshal(&colorString); err != nil {
+		return err
+	}
+
+	if !colorRegex.MatchString(colorString) {
+		return fmt.Errorf("invalid color %q, it must be an hexadecimal color, like \"#1565c0\", or a color name, like \"teal\"", colorString)
+	}
+
+	*color = Color(colorString)
+	return nil
 }
 
 // IsMatch reports whether the string s contains any match of the regular expression pattern
@@ -36,6 +123,17 @@ func (regex *Regex) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return err
 }
 
+// MarshalYAML interface is implemented so that a "Regex" is written back as the string it was created from.
+// See https://godoc.org/gopkg.in/yaml.v2#Marshaler for more details
+func (regex Regex) MarshalYAML() (interface{}, error) {
+	return regex.regexString, nil
+}
+
+// String returns the string the regex was created from
+func (regex Regex) String() string {
+	return regex.regexString
+}
+
 // MustCreateRegex creates a compiled regex based on a given string, but panics if anything goes wrong
 func MustCreateRegex(s string) (regex Regex) {
 	regex, err := CreateRegex(s)
@@ -60,11 +158,23 @@ func CreateRegex(s string) (Regex, error) {
 // regex matches the given line. If a record is found it returs the found record and true.
 // if it does not find it returns an empty Record and false
 func FindFirstRecordThatMatchesString(records []Record, line string) (Record, bool) {
-	for _, record := range records {
+	index, isRecordFound := FindFirstRecordIndexThatMatchesString(records, line)
+	if !isRecordFound {
+		return Record{}, false
+	}
+
+	return records[index], true
+}
+
+// FindFirstRecordIndexThatMatchesString returns the index of the first record, in a given slice of records, in which its
+// regex matches the given line. If a record is found it returns its index and true.
+// if it does not find it returns -1 and false
+func FindFirstRecordIndexThatMatchesString(records []Record, line string) (int, bool) {
+	for i, record := range records {
 		if record.IsMatch(line) {
-			return record, true
+			return i, true
 		}
 	}
 
-	return Record{}, false
+	return -1, false
 }
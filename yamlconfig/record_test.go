@@ -257,3 +257,108 @@ func TestCreateRegex(t *testing.T) {
 		})
 	}
 }
+
+func TestFindFirstRecordIndexThatMatchesString(t *testing.T) {
+	var records = []Record{
+		{Name: "record A", Regex: MustCreateRegex("^A.*$")},
+		{Name: "record B", Regex: MustCreateRegex("^B.*$")},
+	}
+
+	tests := []struct {
+		name  string
+		line  string
+		want  int
+		want1 bool
+	}{
+		{"Find index of the first record", "Athequickbrownfox", 0, true},
+		{"Find index of the last record", "Bthequickbrownfox", 1, true},
+		{"Do not find any records", "Xthequickbrownfox", -1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := FindFirstRecordIndexThatMatchesString(records, tt.line)
+			if got != tt.want {
+				t.Errorf("FindFirstRecordIndexThatMatchesString() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("FindFirstRecordIndexThatMatchesString() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestColor_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		color   string
+		want    Color
+		wantErr bool
+	}{
+		{"Should accept hexadecimal colors", "\"#1565c0\"", "#1565c0", false},
+		{"Should accept short hexadecimal colors", "\"#fff\"", "#fff", false},
+		{"Should accept color names", "teal", "teal", false},
+		{"Should not accept anything that could change the css", "\"red; background: url(x)\"", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configuration, err := ReadConfiguration([]byte("records:\n  - name: record A\n    color: " + tt.color))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadConfiguration() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && configuration.Records[0].Color != tt.want {
+				t.Errorf("ReadConfiguration() color = %v, want %v", configuration.Records[0].Color, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecord_KeyValues(t *testing.T) {
+	record := Record{
+		Name: "record A",
+		Key:  []string{"branch", "id"},
+		Fields: []Field{
+			{Name: "id", Initial: 1, End: 4},
+			{Name: "name", Initial: 5, End: 9},
+			{Name: "branch", Initial: 10, End: 12},
+		},
+	}
+
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{"Should return the values of the key fields in the order of the key", "0001John 023", []string{"023", "0001"}},
+		{"Should remove the spaces around the values", "  1 John  23", []string{"23", "1"}},
+		{"Should return empty values when the line is short", "0001", []string{"", "0001"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := record.KeyValues(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Record.KeyValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecord_validateRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []Rule
+		wantErr bool
+	}{
+		{"Should accept records without rules", nil, false},
+		{"Should accept rules with names and expressions", []Rule{{Name: "a", Expression: "x > 0"}, {Name: "b", Expression: "x < 9"}}, false},
+		{"Should get error on rules without name", []Rule{{Expression: "x > 0"}}, true},
+		{"Should get error on rules without expression", []Rule{{Name: "a", Expression: " "}}, true},
+		{"Should get error on rules with the same name", []Rule{{Name: "a", Expression: "x > 0"}, {Name: "a", Expression: "x < 9"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (Record{Name: "record", Rules: tt.rules}).validateRules(); (err != nil) != tt.wantErr {
+				t.Errorf("Record.validateRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}